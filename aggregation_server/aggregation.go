@@ -1,45 +1,317 @@
 package main
 
 import (
+	"agentske/checkpoint"
+	"agentske/compression"
+	"agentske/preprocessing"
 	messages "agentske/proto"
+	"agentske/registry"
 	"agentske/training"
+	"errors"
+	"flag"
 	"fmt"
 	console "github.com/asynkron/goconsole"
 	"github.com/asynkron/protoactor-go/actor"
 	"github.com/asynkron/protoactor-go/remote"
+	"io/fs"
+	"log"
+	"time"
 )
 
-type AggregationActor struct{}
+type SecureAggregationConfig struct {
+	Enabled bool
+	// Minimum number of hospitals whose updates are summed, and whose shares are needed to unmask
+	Threshold int
+	// A round starts as soon as this many hospitals joined
+	MaxClients   int
+	PhaseTimeout time.Duration
+}
+
+type CompressionConfig struct {
+	// Preferred encodings, used when the hospital accepts them
+	WeightsEncoding messages.Encoding
+	UpdateEncoding  messages.Encoding
+	TopKRatio       float64
+}
+
+type AggregationActor struct {
+	rounds map[uint64]*secureRound
+	// Round hospitals join, for every purpose
+	openRounds map[messages.SecAggPurpose]*secureRound
+	nextRound  uint64
+	// Model version of the last checkpoint written
+	checkpointed uint64
+	// Model version created by the last rollback
+	lastRollback uint64
+	// Feature statistics waiting for more hospitals
+	statistics *pendingStatistics
+	// Mean pooled by the first pass of the feature statistics, and the number of hospitals in it
+	featureMean      []float64
+	featureHospitals int
+	// Merged metadata of the versions committed since the global model was last registered, and their number
+	unregistered        *messages.ModelVersion
+	unregisteredCommits int
+}
 
 var n *training.MLP
 
-func (*AggregationActor) Receive(context actor.Context) {
+var optimizer *training.Optimizer
+
+// The global model is identified by modelID, and its version counts the updates applied to it
+const modelID = "covid-lbp-mlp"
+
+var modelVersion uint64
+
+var secureAggregation SecureAggregationConfig
+
+var wireCompression CompressionConfig
+
+func newAggregationActor(nextRound uint64) actor.Producer {
+	return func() actor.Actor {
+		return &AggregationActor{
+			rounds:       make(map[uint64]*secureRound),
+			openRounds:   make(map[messages.SecAggPurpose]*secureRound),
+			nextRound:    nextRound,
+			checkpointed: modelVersion,
+		}
+	}
+}
+
+func (state *AggregationActor) Receive(context actor.Context) {
 	switch msg := context.Message().(type) {
+	case *actor.Started:
+		state.startCheckpointTimer(context)
+	case *checkpointTick:
+		// Periodic checkpoints are skipped while the model did not change
+		if state.checkpointed != modelVersion {
+			state.saveCheckpoint()
+		}
+	case *saveCheckpoint:
+		state.saveCheckpoint()
+		context.Respond(msg)
+	case *registryCommand:
+		context.Respond(state.runCommand(msg.args))
+	case *messages.ResolveModelVersion:
+		// The current version is about to be requested by its number, so it has to be in the registry
+		if msg.ModelVersion == "" {
+			state.registerPending()
+		}
+		context.Respond(resolveModelVersion(msg.ModelVersion))
+	case *messages.ValidationReport:
+		response := &messages.ValidationReportReceived{}
+		if err := state.receiveValidationReport(msg); err != nil {
+			log.Println("Could not record the validation metrics:", err)
+			response.Error = err.Error()
+		}
+		context.Respond(response)
 	case *messages.GetGlobalWeights:
-		globalWeights := training.ConvertToGlobalWeights(n)
+		globalWeights, err := globalWeightsFor(msg.ModelVersion)
+		if err != nil {
+			log.Println("Could not serve the requested model version:", err)
+			context.Respond(&messages.GlobalWeights{})
+			return
+		}
+		globalWeights.SecureAggregation = secureAggregation.Enabled
+		globalWeights.StatisticsTimeoutMs = uint64(featureStatistics.Timeout.Milliseconds())
+		weightsEncoding := compression.Negotiate(wireCompression.WeightsEncoding, msg.AcceptedEncodings)
+		if err := compression.EncodeGlobalWeights(globalWeights, weightsEncoding); err != nil {
+			// Vectors that could not be compressed are still sent dense
+			log.Println("Could not compress the global weights:", err)
+		}
+		// Masked updates look random and can not be compressed
+		if !secureAggregation.Enabled {
+			globalWeights.UpdateCompression = &messages.CompressionSettings{
+				Encoding:  compression.Negotiate(wireCompression.UpdateEncoding, msg.AcceptedEncodings),
+				TopKRatio: wireCompression.TopKRatio,
+			}
+		}
 		context.Respond(globalWeights)
 	case *messages.GradientUpdate:
-		training.UpdateGlobalWeights(n, msg)
+		if secureAggregation.Enabled {
+			// Plain updates would expose a single hospital's gradients
+			fmt.Println("Rejected an unmasked gradient update")
+			return
+		}
+		if err := compression.DecodeGradientUpdate(msg); err != nil {
+			log.Println("Could not decompress the gradient update:", err)
+			return
+		}
+		meta := &messages.ModelVersion{Round: modelVersion + 1, Participants: []string{msg.Hospital}, ParticipantCount: 1}
+		if err := state.applyUpdate(msg, meta); err != nil {
+			log.Println("Rejected a gradient update:", err)
+		}
+	case *messages.FeatureStatistics:
+		// The response is sent once the statistics are pooled
+		if err := state.receiveFeatureStatistics(context, msg); err != nil {
+			log.Println("Rejected feature statistics:", err)
+			context.Respond(&messages.FeatureStatisticsReceived{Error: err.Error()})
+		}
+	case *featureStatisticsTimeout:
+		if msg.pending == state.statistics {
+			state.poolPendingStatistics(context)
+		}
+	default:
+		if secureAggregation.Enabled {
+			state.receiveSecureAggregation(context)
+		}
+	}
+}
+
+// applyUpdate applies the gradients to the global model, commits the new version with
+// the round metadata and registers and checkpoints it every few rounds.
+func (state *AggregationActor) applyUpdate(msg *messages.GradientUpdate, meta *messages.ModelVersion) error {
+	if msg.Gradients.ModelId != modelID {
+		return fmt.Errorf("Gradients were computed for model %q, not %q", msg.Gradients.ModelId, modelID)
+	}
+	if err := training.UpdateGlobalWeights(n, optimizer, msg); err != nil {
+		return err
+	}
+	state.commitVersion(meta)
+	if checkpointing.EveryRounds > 0 && modelVersion%checkpointing.EveryRounds == 0 {
+		state.saveCheckpoint()
+	}
+	return nil
+}
+
+// commitVersion bumps the version of the changed global model. Versions are kept in memory and
+// registered together, as one version with their merged metadata, by registerPending.
+func (state *AggregationActor) commitVersion(meta *messages.ModelVersion) {
+	meta.ParentVersion = modelVersion
+	modelVersion++
+	state.unregisteredCommits++
+	pending := state.unregistered
+	if pending == nil {
+		state.unregistered = meta
+		return
+	}
+	pending.Round = meta.Round
+	for _, participant := range meta.Participants {
+		if !contains(pending.Participants, participant) {
+			pending.Participants = append(pending.Participants, participant)
+		}
+	}
+	// Anonymous secure aggregation rounds only report their size
+	if meta.ParticipantCount > pending.ParticipantCount {
+		pending.ParticipantCount = meta.ParticipantCount
+	}
+	if count := uint32(len(pending.Participants)); count > pending.ParticipantCount {
+		pending.ParticipantCount = count
 	}
 }
 
 func main() {
+	flag.BoolVar(&secureAggregation.Enabled, "secure-aggregation", false, "only aggregate masked updates, so individual hospital updates are never visible")
+	flag.IntVar(&secureAggregation.Threshold, "secagg-threshold", 2, "minimum number of hospitals in a secure aggregation round")
+	flag.IntVar(&secureAggregation.MaxClients, "secagg-clients", 2, "number of hospitals after which a secure aggregation round starts")
+	flag.DurationVar(&secureAggregation.PhaseTimeout, "secagg-timeout", 10*time.Second, "how long a secure aggregation phase waits for slow hospitals")
+	flag.IntVar(&featureStatistics.Hospitals, "statistics-hospitals", 2, "number of hospitals whose feature statistics are pooled into the standardization of the features, with secure aggregation the round size applies")
+	flag.DurationVar(&featureStatistics.Timeout, "statistics-timeout", time.Minute, "how long the first feature statistics of each pass wait for those of other hospitals")
+	weightsEncoding := flag.String("weights-encoding", "none", "preferred encoding of the global weights (none, float16, q8, q4)")
+	updateEncoding := flag.String("update-encoding", "none", "preferred encoding of the gradient updates (none, float16, q8, q4, topk)")
+	flag.Float64Var(&wireCompression.TopKRatio, "topk-ratio", 0.01, "fraction of the gradient entries kept by the topk encoding")
+	flag.StringVar(&checkpointing.Dir, "checkpoint-dir", "checkpoints", "directory of the global model checkpoints, empty to disable checkpointing")
+	flag.IntVar(&checkpointing.Keep, "checkpoint-keep", 5, "number of newest checkpoints to keep")
+	flag.Uint64Var(&checkpointing.EveryRounds, "checkpoint-every", 10, "write a checkpoint every this many applied rounds, 0 to disable")
+	flag.DurationVar(&checkpointing.Interval, "checkpoint-interval", 5*time.Minute, "also register the model and write a checkpoint this often when it changed, 0 to disable")
+	flag.StringVar(&registryConfig.Dir, "registry-dir", "model-registry", "directory of the model registry")
+	flag.IntVar(&registryConfig.KeepUntagged, "registry-keep", 200, "number of untagged versions without metrics whose parameters are kept")
+	flag.BoolVar(&registryConfig.AutoRollback, "auto-rollback", true, "roll the global model back when a version regresses the validation metrics")
+	flag.Float64Var(&registryConfig.Tolerance, "rollback-tolerance", 5, "f1 score drop, in percentage points, that triggers a rollback")
+	features := flag.String("features", preprocessing.DefaultDatasetConfig().LBP.String(), "feature pipeline of the model: optional preprocessing steps and a bar, the extractors joined by +, optionally a bar and feature normalization steps; must match the hospitals' features")
+	flag.Parse()
+	var err error
+	featurePipeline, err := preprocessing.ParsePipeline(*features)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// The input layer has one neuron per feature
+	inputs := featurePipeline.Dimension()
+	if wireCompression.WeightsEncoding, err = compression.ParseEncoding(*weightsEncoding); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if wireCompression.WeightsEncoding == messages.Encoding_ENCODING_TOP_K {
+		fmt.Println("The topk encoding is only supported for gradient updates")
+		return
+	}
+	if wireCompression.UpdateEncoding, err = compression.ParseEncoding(*updateEncoding); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if wireCompression.TopKRatio <= 0 || wireCompression.TopKRatio > 1 {
+		fmt.Println("The topk ratio must be in (0, 1]")
+		return
+	}
+	if secureAggregation.Threshold < 2 || secureAggregation.MaxClients < secureAggregation.Threshold {
+		fmt.Println("Secure aggregation needs a threshold of at least 2 and at least as many clients")
+		return
+	}
+	if featureStatistics.Hospitals < 1 || featureStatistics.Timeout <= 0 {
+		fmt.Println("The feature statistics need at least 1 hospital and a positive timeout")
+		return
+	}
+
 	con := training.Config{
-		Epochs:    25,
+		Epochs:    training.DefaultEpochs,
 		Eta:       0.3,
 		BatchSize: 32,
 	}
-	arch := []int{1600, 15, 8, 1}
+	arch := []int{inputs, 15, 8, 1}
 	n = training.New(con, arch...)
-	n.ReadWeightsFromFile("weights.json")
+	n.SetFeatures(featurePipeline.String(), featurePipeline.Standardized())
+	optimizer = training.NewOptimizer(con.Eta, 0)
+
+	var nextRound uint64
+	var resumed *messages.Checkpoint
+	if checkpointing.Dir != "" {
+		if checkpoints, err = checkpoint.NewStore(checkpointing.Dir, checkpointing.Keep); err != nil {
+			fmt.Println("Could not open the checkpoint directory:", err)
+			return
+		}
+		if resumed, err = resumeFromCheckpoint(); err != nil {
+			fmt.Println("Could not resume from the latest checkpoint:", err)
+			return
+		}
+		if resumed != nil {
+			nextRound = resumed.SecureAggregationRound
+		}
+	}
+	if resumed == nil {
+		// Only a missing file starts from random weights, one for other features must not be replaced silently
+		if err := n.ReadWeightsFromFile("weights.json"); errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("Starting from random weights:", err)
+		} else if err != nil {
+			fmt.Println("Could not read the starting weights:", err)
+			return
+		} else {
+			fmt.Println("Read")
+		}
+	}
+	if models, err = registry.Open(registryConfig.Dir, registryConfig.KeepUntagged); err != nil {
+		fmt.Println("Could not open the model registry:", err)
+		return
+	}
+	registerStartingModel(resumed != nil)
 
-	fmt.Println("Read")
 	system := actor.NewActorSystem()
 	remoteConfig := remote.Configure("127.0.0.1", 8091)
 	remoting := remote.NewRemote(system, remoteConfig)
 	remoting.Start()
 
 	// register a name for our local actor so that it can be spawned remotely
-	remoting.Register("AggregationActor", actor.PropsFromProducer(func() actor.Actor { return &AggregationActor{} }))
-	console.ReadLine()
+	props := actor.PropsFromProducer(newAggregationActor(nextRound))
+	remoting.Register("AggregationActor", props)
+	// Activate it right away through the remote activator, so it checkpoints even before the first
+	// hospital connects, and the console and shutdown reach the actor the hospitals update
+	spawnResponse, err := remoting.SpawnNamed(remoteConfig.Address(), "AggregationActor", "AggregationActor", 5*time.Second)
+	if err != nil || spawnResponse.Pid == nil {
+		fmt.Println("Could not spawn the aggregation actor:", err)
+		return
+	}
+	pid := spawnResponse.Pid
+	readCommands(system, pid, console.ReadLine)
+
+	// Registers and checkpoints the last updates
+	system.Root.RequestFuture(pid, &saveCheckpoint{}, 30*time.Second).Wait()
 }
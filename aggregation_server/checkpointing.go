@@ -0,0 +1,87 @@
+package main
+
+import (
+	"agentske/checkpoint"
+	messages "agentske/proto"
+	"agentske/training"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asynkron/protoactor-go/actor"
+	"github.com/asynkron/protoactor-go/scheduler"
+)
+
+type CheckpointConfig struct {
+	Dir  string
+	Keep int
+	// Checkpoint after every EveryRounds applied updates
+	EveryRounds uint64
+	// and periodically, if the model changed since the last checkpoint
+	Interval time.Duration
+}
+
+var checkpointing CheckpointConfig
+
+var checkpoints *checkpoint.Store
+
+// checkpointTick triggers the periodic checkpoint.
+type checkpointTick struct{}
+
+// saveCheckpoint asks the aggregation actor to write a checkpoint now and respond when it is done.
+type saveCheckpoint struct{}
+
+func (state *AggregationActor) startCheckpointTimer(context actor.Context) {
+	if checkpointing.Interval <= 0 {
+		return
+	}
+	scheduler.NewTimerScheduler(context).SendRepeatedly(checkpointing.Interval, checkpointing.Interval, context.Self(), &checkpointTick{})
+}
+
+// saveCheckpoint registers the global model and writes it, the optimizer state and the round
+// counters to disk.
+func (state *AggregationActor) saveCheckpoint() {
+	state.registerPending()
+	if checkpoints == nil {
+		return
+	}
+	globalWeights := training.ConvertToGlobalWeights(n)
+	globalWeights.Parameters.ModelId = modelID
+	globalWeights.Parameters.Version = modelVersion
+	c := &messages.Checkpoint{
+		Parameters:             globalWeights.Parameters,
+		Optimizer:              optimizer.State(),
+		SecureAggregationRound: state.nextRound,
+		CreatedUnix:            time.Now().Unix(),
+	}
+	path, err := checkpoints.Save(c)
+	if err != nil {
+		log.Println("Could not write a checkpoint:", err)
+		return
+	}
+	state.checkpointed = modelVersion
+	log.Printf("Checkpoint of version %d written to %s\n", modelVersion, path)
+}
+
+// resumeFromCheckpoint restores the global model, the optimizer and the model version
+// from the latest checkpoint, if there is one.
+func resumeFromCheckpoint() (*messages.Checkpoint, error) {
+	c, path, err := checkpoints.Latest()
+	if err != nil || c == nil {
+		return nil, err
+	}
+	if c.Parameters.ModelId != modelID {
+		return nil, fmt.Errorf("Checkpoint %s belongs to model %q, not %q", path, c.Parameters.ModelId, modelID)
+	}
+	if err := n.ConvertFromGlobalWeights(&messages.GlobalWeights{Parameters: c.Parameters}); err != nil {
+		return nil, err
+	}
+	if c.Optimizer != nil {
+		if err := optimizer.Restore(c.Optimizer, n); err != nil {
+			return nil, err
+		}
+	}
+	modelVersion = c.Parameters.Version
+	fmt.Printf("Resumed version %d from %s\n", modelVersion, path)
+	return c, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	messages "agentske/proto"
+	"agentske/training"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asynkron/protoactor-go/actor"
+	"github.com/asynkron/protoactor-go/scheduler"
+)
+
+type FeatureStatisticsConfig struct {
+	// The statistics are pooled as soon as this many hospitals reported them
+	Hospitals int
+	// or this long after the first report
+	Timeout time.Duration
+}
+
+var featureStatistics FeatureStatisticsConfig
+
+// featureStatisticsTimeout is sent to the aggregation actor itself when the hospitals took too long to report.
+type featureStatisticsTimeout struct {
+	pending *pendingStatistics
+}
+
+// pendingStatistics holds the plain feature statistics of one pass reported so far, by hospital,
+// and the hospitals waiting for them to be pooled.
+type pendingStatistics struct {
+	deviations bool
+	// The statistics are pooled as soon as this many hospitals reported them
+	hospitals int
+	reports   map[string]*messages.FeatureStatistics
+	waiting   []*actor.PID
+	cancel    scheduler.CancelFunc
+}
+
+// receiveFeatureStatistics collects the statistics of a hospital until enough hospitals reported
+// them, and answers it once they are pooled. A hospital reporting again replaces its statistics.
+func (state *AggregationActor) receiveFeatureStatistics(context actor.Context, msg *messages.FeatureStatistics) error {
+	if secureAggregation.Enabled {
+		// Plain statistics would expose a single hospital's feature distribution
+		return errors.New("The aggregator only accepts feature statistics through secure aggregation")
+	}
+	if msg.ModelId != modelID {
+		return fmt.Errorf("The feature statistics were computed for model %q, not %q", msg.ModelId, modelID)
+	}
+	if !n.Standardized() {
+		return fmt.Errorf("Model %q does not standardize its features", modelID)
+	}
+	if n.HasScaler() {
+		context.Respond(&messages.FeatureStatisticsReceived{})
+		return nil
+	}
+	hospitals := featureStatistics.Hospitals
+	if msg.Deviations {
+		if state.featureMean == nil {
+			return errors.New("The feature mean was not pooled yet")
+		}
+		// The hospitals which pooled the mean report their deviations from it
+		hospitals = state.featureHospitals
+	} else if state.featureMean != nil {
+		// The mean was pooled without this hospital
+		context.Respond(&messages.FeatureStatisticsReceived{Mean: state.featureMean})
+		return nil
+	}
+
+	if state.statistics == nil {
+		state.statistics = &pendingStatistics{deviations: msg.Deviations, hospitals: hospitals, reports: make(map[string]*messages.FeatureStatistics)}
+		timeout := &featureStatisticsTimeout{pending: state.statistics}
+		state.statistics.cancel = scheduler.NewTimerScheduler(context).SendOnce(featureStatistics.Timeout, context.Self(), timeout)
+	}
+	state.statistics.reports[msg.Hospital] = msg
+	state.statistics.waiting = append(state.statistics.waiting, context.Sender())
+	if len(state.statistics.reports) >= state.statistics.hospitals {
+		state.poolPendingStatistics(context)
+	}
+	return nil
+}
+
+// poolPendingStatistics pools the statistics reported so far, if any, and answers the hospitals
+// waiting for them.
+func (state *AggregationActor) poolPendingStatistics(context actor.Context) {
+	pending := state.statistics
+	if pending == nil {
+		return
+	}
+	state.statistics = nil
+	pending.cancel()
+
+	inputs, _ := n.Weights[0].Dims()
+	pooled := &messages.FeatureStatistics{Sum: make([]float64, inputs), SumSquares: make([]float64, inputs)}
+	var hospitals []string
+	for hospital, report := range pending.reports {
+		if len(report.Sum) != inputs || len(report.SumSquares) != inputs {
+			log.Printf("Dropped the feature statistics of %s, they do not have %d features\n", hospital, inputs)
+			continue
+		}
+		pooled.Count += report.Count
+		for j := 0; j < inputs; j++ {
+			pooled.Sum[j] += report.Sum[j]
+			pooled.SumSquares[j] += report.SumSquares[j]
+		}
+		hospitals = append(hospitals, hospital)
+	}
+
+	response := &messages.FeatureStatisticsReceived{}
+	var err error
+	if pending.deviations {
+		meta := &messages.ModelVersion{Round: modelVersion + 1, Participants: hospitals, ParticipantCount: uint32(len(hospitals))}
+		err = state.poolFeatureDeviations(pooled, meta)
+	} else {
+		response.Mean, err = state.poolFeatureSums(pooled, len(hospitals))
+	}
+	if err != nil {
+		log.Println("Could not pool the feature statistics:", err)
+		response.Error = err.Error()
+	}
+	for _, pid := range pending.waiting {
+		context.Send(pid, response)
+	}
+}
+
+// poolFeatureSums keeps the mean of the pooled sums of the first pass, which the hospitals compute
+// their deviations from.
+func (state *AggregationActor) poolFeatureSums(pooled *messages.FeatureStatistics, hospitals int) ([]float64, error) {
+	if n.HasScaler() {
+		return nil, nil
+	}
+	if state.featureMean != nil {
+		// The hospitals already computing their deviations keep the mean
+		return state.featureMean, nil
+	}
+	mean, err := training.FeatureMean(pooled)
+	if err != nil {
+		return nil, err
+	}
+	state.featureMean = mean
+	state.featureHospitals = hospitals
+	log.Printf("Pooled the feature sums of %d images from %d hospitals\n", pooled.Count, hospitals)
+	return mean, nil
+}
+
+// poolFeatureDeviations stores the scaler of the pooled deviations of the second pass with the global
+// model as a new version. Every hospital trains and evaluates with it from then on.
+func (state *AggregationActor) poolFeatureDeviations(pooled *messages.FeatureStatistics, meta *messages.ModelVersion) error {
+	if n.HasScaler() {
+		return nil
+	}
+	if state.featureMean == nil {
+		return errors.New("The feature mean was not pooled yet")
+	}
+	scaler, err := training.FeatureScalerFromDeviations(state.featureMean, pooled)
+	if err != nil {
+		return err
+	}
+	state.featureMean = nil
+	// The scaler is a version of its own
+	state.registerPending()
+	n.SetScaler(scaler)
+	meta.Note = "feature statistics"
+	state.commitVersion(meta)
+	log.Printf("Pooled the feature statistics of %d images from %d hospitals as version %d\n", pooled.Count, meta.ParticipantCount, modelVersion)
+	// Without the scaler the model can not be trained or evaluated, so it is registered and checkpointed right away
+	state.saveCheckpoint()
+	return nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	messages "agentske/proto"
+	"agentske/registry"
+	"agentske/training"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asynkron/protoactor-go/actor"
+)
+
+type RegistryConfig struct {
+	Dir string
+	// Parameters of this many untagged versions without metrics are kept
+	KeepUntagged int
+	// Roll back when a version's F1 score drops more than Tolerance percentage points below the baseline
+	AutoRollback bool
+	Tolerance    float64
+}
+
+var registryConfig RegistryConfig
+
+var models *registry.Registry
+
+// registryCommand is an operator command typed into the aggregator console.
+// The actor responds with the text to print.
+type registryCommand struct {
+	args []string
+}
+
+const registryHelp = `commands:
+  versions                  list the registered model versions
+  tag <version> <tag>       tag a version as candidate or production
+  rollback <version>        make a registered version the global model again
+  quit                      stop the aggregator`
+
+// registerVersion stores the current global model in the registry as a new version.
+func registerVersion(meta *messages.ModelVersion) {
+	globalWeights := training.ConvertToGlobalWeights(n)
+	globalWeights.Parameters.ModelId = modelID
+	globalWeights.Parameters.Version = modelVersion
+	meta.CreatedUnix = time.Now().Unix()
+	if err := models.Register(globalWeights.Parameters, meta); err != nil {
+		log.Printf("Could not register model version %d: %v\n", modelVersion, err)
+	}
+}
+
+// registerPending registers the global model with the merged metadata of the versions committed
+// since it was last registered. The versions in between are never registered: registering every
+// update would write the whole model and the index to disk for every mini-batch.
+func (state *AggregationActor) registerPending() {
+	meta := state.unregistered
+	if meta == nil {
+		return
+	}
+	if state.unregisteredCommits > 1 && meta.Note == "" {
+		meta.Note = fmt.Sprintf("%d updates", state.unregisteredCommits)
+	}
+	state.unregistered, state.unregisteredCommits = nil, 0
+	registerVersion(meta)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// registerStartingModel makes sure the model the aggregator starts with is registered. When the
+// registry already has newer versions than the checkpoint, numbering continues after them.
+func registerStartingModel(resumed bool) {
+	note := "initial weights"
+	if resumed {
+		note = fmt.Sprintf("resumed from the checkpoint of version %d", modelVersion)
+	}
+	latest, ok := models.Latest()
+	if !ok || latest < modelVersion {
+		registerVersion(&messages.ModelVersion{Round: modelVersion, Note: note})
+		return
+	}
+	if resumed && latest == modelVersion {
+		return
+	}
+	parent := modelVersion
+	modelVersion = latest + 1
+	registerVersion(&messages.ModelVersion{Round: modelVersion, ParentVersion: parent, Note: note})
+}
+
+// globalWeightsFor returns the current global model, or the registry version the selector names.
+func globalWeightsFor(selector string) (*messages.GlobalWeights, error) {
+	if selector == "" {
+		globalWeights := training.ConvertToGlobalWeights(n)
+		globalWeights.Parameters.ModelId = modelID
+		globalWeights.Parameters.Version = modelVersion
+		return globalWeights, nil
+	}
+	version, err := models.Resolve(selector)
+	if err != nil {
+		return nil, err
+	}
+	params, err := models.Get(version)
+	if err != nil {
+		return nil, err
+	}
+	return &messages.GlobalWeights{Parameters: params}, nil
+}
+
+func resolveModelVersion(selector string) *messages.ResolvedModelVersion {
+	version := modelVersion
+	if selector != "" {
+		var err error
+		if version, err = models.Resolve(selector); err != nil {
+			return &messages.ResolvedModelVersion{Error: err.Error()}
+		}
+	}
+	resolved := &messages.ResolvedModelVersion{Version: version}
+	if meta, ok := models.Info(version); ok {
+		resolved.Tags = meta.Tags
+	}
+	return resolved
+}
+
+// receiveValidationReport records the metrics and rolls the global model back when
+// the reported version is clearly worse than an earlier baseline version.
+func (state *AggregationActor) receiveValidationReport(msg *messages.ValidationReport) error {
+	if msg.Metrics == nil {
+		return errors.New("The validation report has no metrics")
+	}
+	if msg.ModelVersion == modelVersion {
+		state.registerPending()
+	}
+	if err := models.AddMetrics(msg.ModelVersion, msg.Metrics); err != nil {
+		return err
+	}
+	log.Printf("Hospital %s reported f1 %0.01f%% for model version %d\n", msg.Metrics.Hospital, msg.Metrics.F1Score, msg.ModelVersion)
+
+	// Versions from before the last rollback are already history
+	if !registryConfig.AutoRollback || msg.ModelVersion <= state.lastRollback {
+		return nil
+	}
+	meta, _ := models.Info(msg.ModelVersion)
+	f1, _ := registry.MeanF1(meta)
+	baseline, baselineF1, ok := models.Baseline(msg.ModelVersion)
+	if !ok || baseline.Version > msg.ModelVersion || f1 >= baselineF1-registryConfig.Tolerance {
+		return nil
+	}
+	reason := fmt.Sprintf("version %d regressed the f1 score from %0.01f%% to %0.01f%%", msg.ModelVersion, baselineF1, f1)
+	// The metrics are recorded either way
+	if err := state.rollback(baseline.Version, reason); err != nil {
+		log.Println("Could not roll back:", err)
+	}
+	return nil
+}
+
+// rollback makes a registered version the global model again, as a new version.
+func (state *AggregationActor) rollback(version uint64, reason string) error {
+	params, err := models.Get(version)
+	if err != nil {
+		return err
+	}
+	if params.ModelId != modelID {
+		return fmt.Errorf("Version %d belongs to model %q, not %q", version, params.ModelId, modelID)
+	}
+	// The model rolled back from stays in the registry
+	state.registerPending()
+	if err := n.ConvertFromGlobalWeights(&messages.GlobalWeights{Parameters: params}); err != nil {
+		return err
+	}
+	optimizer.ResetVelocity()
+	previous := modelVersion
+	modelVersion++
+	state.lastRollback = modelVersion
+
+	note := fmt.Sprintf("rolled back to version %d", version)
+	if reason != "" {
+		note += " because " + reason
+	}
+	registerVersion(&messages.ModelVersion{ParentVersion: version, Note: note})
+	log.Printf("Model version %d %s (was %d)\n", modelVersion, note, previous)
+	state.saveCheckpoint()
+	return nil
+}
+
+func (state *AggregationActor) runCommand(args []string) string {
+	if len(args) == 0 {
+		return registryHelp
+	}
+	switch args[0] {
+	case "versions":
+		var lines []string
+		for _, v := range models.Versions() {
+			line := registry.Describe(v)
+			if v.Version == modelVersion {
+				line += " <- global model"
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			return "no registered versions"
+		}
+		return strings.Join(lines, "\n")
+
+	case "tag":
+		if len(args) != 3 {
+			return registryHelp
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return "invalid version: " + args[1]
+		}
+		if err := models.Tag(version, args[2]); err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("version %d tagged %s", version, args[2])
+
+	case "rollback":
+		if len(args) != 2 {
+			return registryHelp
+		}
+		version, err := models.Resolve(args[1])
+		if err != nil {
+			return err.Error()
+		}
+		if err := state.rollback(version, "an operator asked for it"); err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("global model is now version %d, a copy of version %d", modelVersion, version)
+	}
+	return registryHelp
+}
+
+// readCommands runs the operator console until quit or an empty line. The commands go to pid, which
+// must be the aggregation actor the hospitals update, so a rollback never runs concurrently with an
+// update and the auto-rollback guard sees it.
+func readCommands(system *actor.ActorSystem, pid *actor.PID, readLine func() (string, error)) {
+	fmt.Println(registryHelp)
+	for {
+		line, err := readLine()
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 || args[0] == "quit" {
+			return
+		}
+		result, err := system.Root.RequestFuture(pid, &registryCommand{args: args}, 30*time.Second).Result()
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(result)
+	}
+}
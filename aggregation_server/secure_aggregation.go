@@ -0,0 +1,219 @@
+package main
+
+import (
+	messages "agentske/proto"
+	"agentske/secagg"
+	"agentske/training"
+	"fmt"
+	"log"
+
+	"github.com/asynkron/protoactor-go/actor"
+	"github.com/asynkron/protoactor-go/scheduler"
+)
+
+// secureRound tracks the hospitals waiting for the current phase of a round to close.
+type secureRound struct {
+	round *secagg.Round
+	// What the hospitals of the round sum
+	purpose messages.SecAggPurpose
+	waiting map[uint32]*actor.PID
+	cancel  scheduler.CancelFunc
+}
+
+// phaseTimeout is sent to the aggregation actor itself when a phase took too long.
+type phaseTimeout struct {
+	round uint64
+	phase secagg.Phase
+}
+
+func (state *AggregationActor) receiveSecureAggregation(context actor.Context) {
+	switch msg := context.Message().(type) {
+	case *messages.SecAggAdvertiseKeys:
+		current := state.openRounds[msg.Purpose]
+		if current == nil {
+			state.nextRound++
+			current = &secureRound{
+				round:   secagg.NewRound(state.nextRound, secureAggregation.Threshold),
+				purpose: msg.Purpose,
+				waiting: make(map[uint32]*actor.PID),
+			}
+			state.openRounds[msg.Purpose] = current
+			state.rounds[state.nextRound] = current
+			state.schedulePhaseTimeout(context, current)
+		}
+		id, err := current.round.Join(msg)
+		if err != nil {
+			log.Println("Secure aggregation join rejected:", err)
+			return
+		}
+		current.waiting[id] = context.Sender()
+		if current.round.Advertised() == secureAggregation.MaxClients {
+			state.closePhase(context, current)
+		}
+
+	case *messages.SecAggShareKeys:
+		current := state.rounds[msg.Round]
+		if current == nil {
+			return
+		}
+		if err := current.round.AddShares(msg); err != nil {
+			log.Println("Secure aggregation shares rejected:", err)
+			return
+		}
+		current.waiting[msg.ClientId] = context.Sender()
+		if current.round.Shared() == current.round.Advertised() {
+			state.closePhase(context, current)
+		}
+
+	case *messages.MaskedGradientUpdate:
+		current := state.rounds[msg.Round]
+		if current == nil {
+			return
+		}
+		if err := current.round.AddMaskedInput(msg); err != nil {
+			log.Println("Secure aggregation masked update rejected:", err)
+			return
+		}
+		current.waiting[msg.ClientId] = context.Sender()
+		if current.round.Masked() == current.round.Shared() {
+			state.closePhase(context, current)
+		}
+
+	case *messages.SecAggUnmaskShares:
+		current := state.rounds[msg.Round]
+		if current == nil {
+			context.Respond(&messages.SecAggRoundResult{Round: msg.Round, Error: "The round is over"})
+			return
+		}
+		if err := current.round.AddUnmaskShares(msg); err != nil {
+			log.Println("Secure aggregation unmasking shares rejected:", err)
+			context.Respond(&messages.SecAggRoundResult{Round: msg.Round, Error: err.Error()})
+			return
+		}
+		current.waiting[msg.ClientId] = context.Sender()
+		if current.round.Unmasked() == len(current.round.Survivors()) {
+			state.closePhase(context, current)
+		}
+
+	case *phaseTimeout:
+		current := state.rounds[msg.round]
+		if current != nil && current.round.Phase() == msg.phase {
+			log.Printf("Secure aggregation round %d timed out, closing the phase\n", msg.round)
+			state.closePhase(context, current)
+		}
+	}
+}
+
+// closePhase moves the round to its next phase and answers every hospital waiting on it.
+func (state *AggregationActor) closePhase(context actor.Context, current *secureRound) {
+	if current.cancel != nil {
+		current.cancel()
+	}
+	if state.openRounds[current.purpose] == current {
+		delete(state.openRounds, current.purpose)
+	}
+	waiting := current.waiting
+	current.waiting = make(map[uint32]*actor.PID)
+
+	switch current.round.Phase() {
+	case secagg.PhaseAdvertise:
+		keys, err := current.round.CloseAdvertise()
+		if err != nil {
+			state.abortRound(context, current, waiting, err, &messages.SecAggRoundKeys{Round: current.round.ID, Aborted: true})
+			return
+		}
+		for _, pid := range waiting {
+			context.Send(pid, keys)
+		}
+
+	case secagg.PhaseShare:
+		if err := current.round.CloseShare(); err != nil {
+			state.abortRound(context, current, waiting, err, &messages.SecAggRoundShares{Round: current.round.ID, Aborted: true})
+			return
+		}
+		for id, pid := range waiting {
+			context.Send(pid, current.round.SharesFor(id))
+		}
+
+	case secagg.PhaseMasked:
+		request, err := current.round.CloseMasked()
+		if err != nil {
+			state.abortRound(context, current, waiting, err, &messages.SecAggUnmaskRequest{Round: current.round.ID, Aborted: true})
+			return
+		}
+		for _, pid := range waiting {
+			context.Send(pid, request)
+		}
+
+	case secagg.PhaseUnmask:
+		delete(state.rounds, current.round.ID)
+		result := &messages.SecAggRoundResult{Round: current.round.ID}
+		if err := state.finishRound(current, result); err != nil {
+			log.Printf("Secure aggregation round %d failed: %v\n", current.round.ID, err)
+			result.Error = err.Error()
+		}
+		for _, pid := range waiting {
+			context.Send(pid, result)
+		}
+		return
+	}
+
+	state.schedulePhaseTimeout(context, current)
+}
+
+// finishRound unmasks the sum of the round and applies it according to the round's purpose.
+func (state *AggregationActor) finishRound(current *secureRound, result *messages.SecAggRoundResult) error {
+	sum, err := current.round.Finish()
+	if err != nil {
+		return err
+	}
+	if current.purpose != messages.SecAggPurpose_SECAGG_PURPOSE_GRADIENTS {
+		return state.poolSecureStatistics(current, secagg.DecodeFixedPoint(sum), result)
+	}
+	update, err := training.UnflattenGradientUpdate(n, secagg.DecodeFixedPoint(sum))
+	if err != nil {
+		return fmt.Errorf("The sum is invalid: %w", err)
+	}
+	update.Gradients.ModelId = modelID
+	// The hospitals stay anonymous, only their number is known
+	meta := &messages.ModelVersion{Round: current.round.ID, ParticipantCount: uint32(len(current.round.Survivors()))}
+	if err := state.applyUpdate(update, meta); err != nil {
+		return fmt.Errorf("The sum could not be applied: %w", err)
+	}
+	log.Printf("Secure aggregation round %d applied the sum of %d updates\n", current.round.ID, len(current.round.Survivors()))
+	return nil
+}
+
+func (state *AggregationActor) abortRound(context actor.Context, current *secureRound, waiting map[uint32]*actor.PID, err error, response interface{}) {
+	log.Printf("Secure aggregation round %d aborted: %v\n", current.round.ID, err)
+	delete(state.rounds, current.round.ID)
+	for _, pid := range waiting {
+		context.Send(pid, response)
+	}
+}
+
+func (state *AggregationActor) schedulePhaseTimeout(context actor.Context, current *secureRound) {
+	timeout := &phaseTimeout{round: current.round.ID, phase: current.round.Phase()}
+	current.cancel = scheduler.NewTimerScheduler(context).SendOnce(secureAggregation.PhaseTimeout, context.Self(), timeout)
+}
+
+// poolSecureStatistics pools the summed feature statistics of a secure round, answering the sums
+// with their mean.
+func (state *AggregationActor) poolSecureStatistics(current *secureRound, sum []float64, result *messages.SecAggRoundResult) error {
+	if !n.Standardized() {
+		return fmt.Errorf("Model %q does not standardize its features", modelID)
+	}
+	inputs, _ := n.Weights[0].Dims()
+	pooled, err := training.UnflattenFeatureStatistics(sum, inputs)
+	if err != nil {
+		return err
+	}
+	survivors := len(current.round.Survivors())
+	if current.purpose == messages.SecAggPurpose_SECAGG_PURPOSE_FEATURE_SUMS {
+		result.Mean, err = state.poolFeatureSums(pooled, survivors)
+		return err
+	}
+	// The hospitals stay anonymous, only their number is known
+	meta := &messages.ModelVersion{Round: current.round.ID, ParticipantCount: uint32(survivors)}
+	return state.poolFeatureDeviations(pooled, meta)
+}
@@ -0,0 +1,118 @@
+package checkpoint
+
+import (
+	"agentske/fileutil"
+	messages "agentske/proto"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	filePrefix = "checkpoint-"
+	fileSuffix = ".pb"
+)
+
+// Store keeps the checkpoints of the global model in a directory, named by model version
+// so that they sort chronologically. Only the newest Keep checkpoints are retained.
+type Store struct {
+	Dir  string
+	Keep int
+}
+
+func NewStore(dir string, keep int) (*Store, error) {
+	if keep < 1 {
+		return nil, errors.New("At least one checkpoint has to be kept")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{Dir: dir, Keep: keep}, nil
+}
+
+// Save atomically writes the checkpoint and removes the checkpoints beyond the retention limit.
+func (s *Store) Save(c *messages.Checkpoint) (string, error) {
+	data, err := proto.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.Dir, fmt.Sprintf("%s%020d%s", filePrefix, c.Parameters.Version, fileSuffix))
+	if err := fileutil.WriteFileAtomic(path, data); err != nil {
+		return "", err
+	}
+	if err := s.prune(); err != nil {
+		log.Println("Could not remove old checkpoints:", err)
+	}
+	return path, nil
+}
+
+// Latest loads the newest readable checkpoint, skipping corrupt ones.
+// It returns nil without an error when there is no checkpoint yet.
+func (s *Store) Latest() (*messages.Checkpoint, string, error) {
+	paths, err := s.list()
+	if err != nil {
+		return nil, "", err
+	}
+	for i := len(paths) - 1; i >= 0; i-- {
+		c, err := Load(paths[i])
+		if err != nil {
+			log.Printf("Skipping unreadable checkpoint %s: %v\n", paths[i], err)
+			continue
+		}
+		return c, paths[i], nil
+	}
+	return nil, "", nil
+}
+
+// Load reads a single checkpoint file.
+func Load(path string) (*messages.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &messages.Checkpoint{}
+	if err := proto.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Parameters == nil {
+		return nil, errors.New("Checkpoint has no model parameters")
+	}
+	return c, nil
+}
+
+// list returns the checkpoint files from the oldest to the newest.
+func (s *Store) list() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			paths = append(paths, filepath.Join(s.Dir, name))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// prune removes everything but the newest Keep checkpoints.
+func (s *Store) prune() error {
+	paths, err := s.list()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(paths)-s.Keep; i++ {
+		if err := os.Remove(paths[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
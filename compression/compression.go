@@ -0,0 +1,180 @@
+package compression
+
+import (
+	messages "agentske/proto"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Settings describes how a vector is compressed on the wire.
+type Settings struct {
+	Encoding messages.Encoding
+	// Fraction of the entries kept by the top-k encoding
+	TopKRatio float64
+}
+
+var encodingNames = map[string]messages.Encoding{
+	"none":    messages.Encoding_ENCODING_NONE,
+	"float16": messages.Encoding_ENCODING_FLOAT16,
+	"q8":      messages.Encoding_ENCODING_QUANTIZED_8BIT,
+	"q4":      messages.Encoding_ENCODING_QUANTIZED_4BIT,
+	"topk":    messages.Encoding_ENCODING_TOP_K,
+}
+
+// ParseEncoding maps the short names used on the command line (none, float16, q8, q4, topk) to encodings.
+func ParseEncoding(name string) (messages.Encoding, error) {
+	encoding, ok := encodingNames[strings.ToLower(name)]
+	if !ok {
+		return messages.Encoding_ENCODING_NONE, fmt.Errorf("Unknown encoding %q", name)
+	}
+	return encoding, nil
+}
+
+// Supported returns every encoding this package can decode.
+func Supported() []messages.Encoding {
+	return []messages.Encoding{
+		messages.Encoding_ENCODING_NONE,
+		messages.Encoding_ENCODING_FLOAT16,
+		messages.Encoding_ENCODING_QUANTIZED_8BIT,
+		messages.Encoding_ENCODING_QUANTIZED_4BIT,
+		messages.Encoding_ENCODING_TOP_K,
+	}
+}
+
+// Negotiate returns the preferred encoding if the peer accepts it, and no encoding otherwise.
+func Negotiate(preferred messages.Encoding, accepted []messages.Encoding) messages.Encoding {
+	for _, encoding := range accepted {
+		if encoding == preferred {
+			return preferred
+		}
+	}
+	return messages.Encoding_ENCODING_NONE
+}
+
+// Encode compresses the values with the given settings.
+func Encode(values []float64, settings Settings) (*messages.CompressedVector, error) {
+	compressed := &messages.CompressedVector{
+		Encoding: settings.Encoding,
+		Length:   uint32(len(values)),
+	}
+
+	switch settings.Encoding {
+	case messages.Encoding_ENCODING_FLOAT16:
+		compressed.Packed = encodeFloat16(values)
+	case messages.Encoding_ENCODING_QUANTIZED_8BIT:
+		compressed.Packed, compressed.Min, compressed.Max = quantize(values, 8)
+	case messages.Encoding_ENCODING_QUANTIZED_4BIT:
+		compressed.Packed, compressed.Min, compressed.Max = quantize(values, 4)
+	case messages.Encoding_ENCODING_TOP_K:
+		if settings.TopKRatio <= 0 || settings.TopKRatio > 1 {
+			return nil, errors.New("Top-k ratio must be in (0, 1]")
+		}
+		compressed.Indices, compressed.SparseValues = topK(values, settings.TopKRatio)
+	default:
+		return nil, fmt.Errorf("Can not compress with encoding %v", settings.Encoding)
+	}
+
+	return compressed, nil
+}
+
+// Decode restores the dense values of a compressed vector.
+func Decode(compressed *messages.CompressedVector) ([]float64, error) {
+	length := int(compressed.Length)
+
+	switch compressed.Encoding {
+	case messages.Encoding_ENCODING_FLOAT16:
+		if len(compressed.Packed) != 2*length {
+			return nil, errors.New("Invalid float16 vector size")
+		}
+		return decodeFloat16(compressed.Packed, length), nil
+	case messages.Encoding_ENCODING_QUANTIZED_8BIT:
+		if len(compressed.Packed) != length {
+			return nil, errors.New("Invalid 8-bit quantized vector size")
+		}
+		return dequantize(compressed.Packed, length, 8, compressed.Min, compressed.Max), nil
+	case messages.Encoding_ENCODING_QUANTIZED_4BIT:
+		if len(compressed.Packed) != (length+1)/2 {
+			return nil, errors.New("Invalid 4-bit quantized vector size")
+		}
+		return dequantize(compressed.Packed, length, 4, compressed.Min, compressed.Max), nil
+	case messages.Encoding_ENCODING_TOP_K:
+		if len(compressed.Indices) != len(compressed.SparseValues) {
+			return nil, errors.New("Top-k vector has a different number of indices and values")
+		}
+		values := make([]float64, length)
+		for i, index := range compressed.Indices {
+			if int(index) >= length {
+				return nil, errors.New("Top-k index is out of range")
+			}
+			values[index] = float64(compressed.SparseValues[i])
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("Can not decompress encoding %v", compressed.Encoding)
+	}
+}
+
+// quantize maps every value to one of 2^bits levels between the minimum and the maximum,
+// rounding up or down at random so that the quantized vector is unbiased.
+func quantize(values []float64, bits uint) ([]byte, float64, float64) {
+	if len(values) == 0 {
+		return nil, 0, 0
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	levels := float64(uint(1)<<bits - 1)
+	perByte := 8 / int(bits)
+	packed := make([]byte, (len(values)+perByte-1)/perByte)
+	for i, v := range values {
+		var level float64
+		if max > min {
+			scaled := (v - min) / (max - min) * levels
+			level = math.Floor(scaled)
+			if rand.Float64() < scaled-level {
+				level++
+			}
+		}
+		packed[i/perByte] |= byte(level) << (uint(i%perByte) * bits)
+	}
+	return packed, min, max
+}
+
+func dequantize(packed []byte, length int, bits uint, min, max float64) []float64 {
+	levels := float64(uint(1)<<bits - 1)
+	perByte := 8 / int(bits)
+	mask := byte(1<<bits - 1)
+	values := make([]float64, length)
+	for i := range values {
+		level := (packed[i/perByte] >> (uint(i%perByte) * bits)) & mask
+		values[i] = min + float64(level)/levels*(max-min)
+	}
+	return values
+}
+
+// topK keeps the entries with the largest magnitude, in increasing index order.
+func topK(values []float64, ratio float64) ([]uint32, []float32) {
+	k := int(math.Ceil(ratio * float64(len(values))))
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return math.Abs(values[order[i]]) > math.Abs(values[order[j]]) })
+	order = order[:k]
+	sort.Ints(order)
+
+	indices := make([]uint32, k)
+	sparse := make([]float32, k)
+	for i, index := range order {
+		indices[i] = uint32(index)
+		sparse[i] = float32(values[index])
+	}
+	return indices, sparse
+}
@@ -0,0 +1,174 @@
+package compression
+
+import (
+	messages "agentske/proto"
+	"math"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	values := []float64{-1.5, 0.25, 3, -0.125, 0.7, 2.2, -3}
+	cases := []struct {
+		name      string
+		settings  Settings
+		packed    int
+		tolerance float64
+	}{
+		{"float16", Settings{Encoding: messages.Encoding_ENCODING_FLOAT16}, 14, 2e-3},
+		{"8-bit", Settings{Encoding: messages.Encoding_ENCODING_QUANTIZED_8BIT}, 7, 6.0 / 255},
+		// Seven 4-bit values leave the upper half of the last byte unused
+		{"4-bit", Settings{Encoding: messages.Encoding_ENCODING_QUANTIZED_4BIT}, 4, 6.0 / 15},
+		{"top-k keeping everything", Settings{Encoding: messages.Encoding_ENCODING_TOP_K, TopKRatio: 1}, 0, 1e-6},
+	}
+	for _, c := range cases {
+		compressed, err := Encode(values, c.settings)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(compressed.Packed) != c.packed {
+			t.Errorf("%s: packed into %d bytes, expected %d", c.name, len(compressed.Packed), c.packed)
+		}
+		decoded, err := Decode(compressed)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(decoded) != len(values) {
+			t.Fatalf("%s: decoded %d values, expected %d", c.name, len(decoded), len(values))
+		}
+		for i := range values {
+			if math.Abs(decoded[i]-values[i]) > c.tolerance {
+				t.Errorf("%s: value %g decoded to %g", c.name, values[i], decoded[i])
+			}
+		}
+	}
+}
+
+func TestQuantizeKeepsTheRange(t *testing.T) {
+	for _, bits := range []uint{4, 8} {
+		// The minimum and the maximum are always exact, also in the last half byte of an odd length
+		values := []float64{0.3, -2, 0.9, 1.1, 5}
+		packed, min, max := quantize(values, bits)
+		decoded := dequantize(packed, len(values), bits, min, max)
+		if decoded[1] != -2 || decoded[4] != 5 {
+			t.Errorf("%d-bit: extremes decoded to %g and %g, expected -2 and 5", bits, decoded[1], decoded[4])
+		}
+
+		constant := []float64{0.4, 0.4, 0.4}
+		packed, min, max = quantize(constant, bits)
+		for i, v := range dequantize(packed, len(constant), bits, min, max) {
+			if v != 0.4 {
+				t.Errorf("%d-bit: constant value %d decoded to %g", bits, i, v)
+			}
+		}
+	}
+}
+
+func TestStochasticQuantizationIsUnbiased(t *testing.T) {
+	values := []float64{0, 0.01, 0.37, 0.5, 0.66, 0.93, 1}
+	const trials = 20000
+	for _, encoding := range []messages.Encoding{messages.Encoding_ENCODING_QUANTIZED_4BIT, messages.Encoding_ENCODING_QUANTIZED_8BIT} {
+		sums := make([]float64, len(values))
+		for trial := 0; trial < trials; trial++ {
+			compressed, err := Encode(values, Settings{Encoding: encoding})
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := Decode(compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range decoded {
+				sums[i] += v
+			}
+		}
+		// A single 4-bit value is off by at most 1/15, so the mean is within a few thousandths
+		for i, v := range values {
+			if mean := sums[i] / trials; math.Abs(mean-v) > 0.003 {
+				t.Errorf("%v: value %g has a mean of %g over %d encodings", encoding, v, mean, trials)
+			}
+		}
+	}
+}
+
+func TestTopK(t *testing.T) {
+	values := []float64{0.1, -5, 3, 0.2, -0.05, 4}
+	cases := []struct {
+		ratio   float64
+		indices []uint32
+	}{
+		{0.5, []uint32{1, 2, 5}},
+		// The number of entries kept is rounded up
+		{0.1, []uint32{1}},
+		{0.6, []uint32{1, 2, 3, 5}},
+	}
+	for _, c := range cases {
+		compressed, err := Encode(values, Settings{Encoding: messages.Encoding_ENCODING_TOP_K, TopKRatio: c.ratio})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(compressed.Indices) != len(c.indices) {
+			t.Fatalf("Ratio %g kept indices %v, expected %v", c.ratio, compressed.Indices, c.indices)
+		}
+		for i, index := range c.indices {
+			if compressed.Indices[i] != index {
+				t.Fatalf("Ratio %g kept indices %v, expected %v", c.ratio, compressed.Indices, c.indices)
+			}
+		}
+
+		decoded, err := Decode(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kept := make(map[uint32]bool)
+		for _, index := range c.indices {
+			kept[index] = true
+		}
+		for i, v := range decoded {
+			// Kept entries travel as float32
+			expected := 0.0
+			if kept[uint32(i)] {
+				expected = float64(float32(values[i]))
+			}
+			if v != expected {
+				t.Errorf("Ratio %g: value %d decoded to %g, expected %g", c.ratio, i, v, expected)
+			}
+		}
+	}
+}
+
+func TestEncodeRejectsInvalidSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings Settings
+	}{
+		{"no encoding", Settings{Encoding: messages.Encoding_ENCODING_NONE}},
+		{"zero top-k ratio", Settings{Encoding: messages.Encoding_ENCODING_TOP_K}},
+		{"top-k ratio above one", Settings{Encoding: messages.Encoding_ENCODING_TOP_K, TopKRatio: 1.5}},
+	}
+	for _, c := range cases {
+		if _, err := Encode([]float64{1, 2}, c.settings); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestDecodeRejectsInvalidSizes(t *testing.T) {
+	cases := []struct {
+		name       string
+		compressed *messages.CompressedVector
+	}{
+		{"short float16", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_FLOAT16, Length: 3, Packed: make([]byte, 5)}},
+		{"long float16", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_FLOAT16, Length: 3, Packed: make([]byte, 8)}},
+		{"short 8-bit", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_QUANTIZED_8BIT, Length: 3, Packed: make([]byte, 2)}},
+		{"short odd length 4-bit", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_QUANTIZED_4BIT, Length: 5, Packed: make([]byte, 2)}},
+		{"long 4-bit", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_QUANTIZED_4BIT, Length: 4, Packed: make([]byte, 3)}},
+		{"top-k with more indices than values", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_TOP_K, Length: 4, Indices: []uint32{0, 1}, SparseValues: []float32{1}}},
+		{"top-k index out of range", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_TOP_K, Length: 4, Indices: []uint32{4}, SparseValues: []float32{1}}},
+		{"no encoding", &messages.CompressedVector{Encoding: messages.Encoding_ENCODING_NONE, Length: 1}},
+	}
+	for _, c := range cases {
+		if _, err := Decode(c.compressed); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package compression
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// float32ToHalf converts a float32 into IEEE 754 half precision bits, rounding to nearest even.
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23) & 0xff
+	mantissa := bits & 0x7fffff
+
+	// NaN and infinity
+	if exp == 0xff {
+		if mantissa != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+
+	exp = exp - 127 + 15
+	// Overflow to infinity
+	if exp >= 0x1f {
+		return sign | 0x7c00
+	}
+	// Subnormal half or zero
+	if exp <= 0 {
+		if exp < -10 {
+			return sign
+		}
+		mantissa |= 0x800000
+		shift := uint32(14 - exp)
+		half := uint16(mantissa >> shift)
+		rest := mantissa & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rest > halfway || (rest == halfway && half&1 == 1) {
+			half++
+		}
+		return sign | half
+	}
+
+	half := uint16(exp)<<10 | uint16(mantissa>>13)
+	rest := mantissa & 0x1fff
+	// Rounding may carry into the exponent, which correctly rounds up to infinity
+	if rest > 0x1000 || (rest == 0x1000 && half&1 == 1) {
+		half++
+	}
+	return sign | half
+}
+
+// halfToFloat32 converts IEEE 754 half precision bits into a float32.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mantissa := uint32(h & 0x3ff)
+
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	case exp == 0:
+		if mantissa == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Normalize the subnormal value
+		for mantissa&0x400 == 0 {
+			mantissa <<= 1
+			exp--
+		}
+		exp++
+		mantissa &= 0x3ff
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mantissa<<13)
+}
+
+// maxHalf is the largest finite half precision value.
+const maxHalf = 65504
+
+// encodeFloat16 saturates values outside of the half precision range instead of turning them into infinities.
+func encodeFloat16(values []float64) []byte {
+	packed := make([]byte, 2*len(values))
+	for i, v := range values {
+		v = math.Max(-maxHalf, math.Min(maxHalf, v))
+		binary.LittleEndian.PutUint16(packed[2*i:], float32ToHalf(float32(v)))
+	}
+	return packed
+}
+
+func decodeFloat16(packed []byte, length int) []float64 {
+	values := make([]float64, length)
+	for i := range values {
+		values[i] = float64(halfToFloat32(binary.LittleEndian.Uint16(packed[2*i:])))
+	}
+	return values
+}
@@ -0,0 +1,70 @@
+package compression
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat32ToHalf(t *testing.T) {
+	cases := []struct {
+		name  string
+		value float32
+		half  uint16
+	}{
+		{"zero", 0, 0x0000},
+		{"negative zero", float32(math.Copysign(0, -1)), 0x8000},
+		{"one", 1, 0x3c00},
+		{"minus two", -2, 0xc000},
+		{"largest finite", 65504, 0x7bff},
+		{"smallest normal", float32(math.Ldexp(1, -14)), 0x0400},
+		{"largest subnormal", float32(math.Ldexp(1023, -24)), 0x03ff},
+		{"smallest subnormal", float32(math.Ldexp(1, -24)), 0x0001},
+		{"halfway to the smallest subnormal rounds to zero", float32(math.Ldexp(1, -25)), 0x0000},
+		{"above halfway to the smallest subnormal", float32(math.Ldexp(3, -26)), 0x0001},
+		{"subnormal halfway rounds to even", float32(math.Ldexp(3, -25)), 0x0002},
+		{"below the subnormal range", float32(math.Ldexp(1, -30)), 0x0000},
+		{"halfway rounds down to even", float32(1 + math.Ldexp(1, -11)), 0x3c00},
+		{"halfway rounds up to even", float32(1 + math.Ldexp(3, -11)), 0x3c02},
+		{"above halfway rounds up", float32(1 + math.Ldexp(3, -12)), 0x3c01},
+		{"rounding carries into the exponent", float32(2 - math.Ldexp(1, -12)), 0x4000},
+		{"halfway past the largest finite overflows", 65520, 0x7c00},
+		{"overflow", 1e6, 0x7c00},
+		{"negative overflow", -1e6, 0xfc00},
+		{"infinity", float32(math.Inf(1)), 0x7c00},
+		{"negative infinity", float32(math.Inf(-1)), 0xfc00},
+		{"NaN", float32(math.NaN()), 0x7e00},
+	}
+	for _, c := range cases {
+		if half := float32ToHalf(c.value); half != c.half {
+			t.Errorf("%s: %g converted to %#04x, expected %#04x", c.name, c.value, half, c.half)
+		}
+	}
+}
+
+func TestHalfRoundTrip(t *testing.T) {
+	// Every half precision value but NaN survives the conversion to float32 and back
+	for h := 0; h <= 0xffff; h++ {
+		half := uint16(h)
+		value := halfToFloat32(half)
+		if half&0x7c00 == 0x7c00 && half&0x3ff != 0 {
+			if !math.IsNaN(float64(value)) {
+				t.Fatalf("NaN %#04x converted to %g", half, value)
+			}
+			continue
+		}
+		if back := float32ToHalf(value); back != half {
+			t.Fatalf("%#04x converted to %g and back to %#04x", half, value, back)
+		}
+	}
+}
+
+func TestEncodeFloat16Saturates(t *testing.T) {
+	values := []float64{1e6, -1e6, 0.5}
+	decoded := decodeFloat16(encodeFloat16(values), len(values))
+	expected := []float64{maxHalf, -maxHalf, 0.5}
+	for i := range expected {
+		if decoded[i] != expected[i] {
+			t.Errorf("Value %g decoded to %g, expected %g", values[i], decoded[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package compression
+
+import (
+	messages "agentske/proto"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorFeedback remembers what compression dropped from every vector, and adds it back
+// to the next vector with the same name, so no part of the gradient is lost for good.
+type ErrorFeedback struct {
+	residuals map[string][]float64
+}
+
+func NewErrorFeedback() *ErrorFeedback {
+	return &ErrorFeedback{residuals: make(map[string][]float64)}
+}
+
+// Encode compresses the values corrected by the residual of the previous call with the same name.
+func (f *ErrorFeedback) Encode(name string, values []float64, settings Settings) (*messages.CompressedVector, error) {
+	corrected := make([]float64, len(values))
+	copy(corrected, values)
+	if residual, ok := f.residuals[name]; ok && len(residual) == len(values) {
+		for i := range corrected {
+			corrected[i] += residual[i]
+		}
+	}
+
+	compressed, err := Encode(corrected, settings)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := Decode(compressed)
+	if err != nil {
+		return nil, err
+	}
+	for i := range corrected {
+		corrected[i] -= decoded[i]
+	}
+	f.residuals[name] = corrected
+
+	return compressed, nil
+}
+
+// ExactPrefix starts the names of tensors which are never compressed, e.g. statistics every hospital
+// has to apply exactly.
+const ExactPrefix = "exact."
+
+// EncodeParameters replaces the dense data of every tensor but the exact ones with its compressed form.
+// With error feedback, the residuals are kept per tensor name.
+func EncodeParameters(params *messages.ModelParameters, settings Settings, feedback *ErrorFeedback) error {
+	for _, tensor := range params.Tensors {
+		if strings.HasPrefix(tensor.Name, ExactPrefix) {
+			continue
+		}
+		var compressed *messages.CompressedVector
+		var err error
+		if feedback != nil {
+			compressed, err = feedback.Encode(tensor.Name, tensor.Data, settings)
+		} else {
+			compressed, err = Encode(tensor.Data, settings)
+		}
+		if err != nil {
+			return fmt.Errorf("Could not compress tensor %s: %w", tensor.Name, err)
+		}
+		tensor.Compressed, tensor.Data = compressed, nil
+	}
+	return nil
+}
+
+// DecodeParameters restores the dense data of every compressed tensor in place.
+func DecodeParameters(params *messages.ModelParameters) error {
+	for _, tensor := range params.Tensors {
+		if tensor.Compressed == nil {
+			continue
+		}
+		data, err := Decode(tensor.Compressed)
+		if err != nil {
+			return fmt.Errorf("Could not decompress tensor %s: %w", tensor.Name, err)
+		}
+		tensor.Data, tensor.Compressed = data, nil
+	}
+	return nil
+}
+
+// EncodeGradientUpdate compresses the gradients of the update with the settings chosen by the aggregator.
+func EncodeGradientUpdate(msg *messages.GradientUpdate, settings *messages.CompressionSettings, feedback *ErrorFeedback) error {
+	if settings == nil || settings.Encoding == messages.Encoding_ENCODING_NONE || msg.Gradients == nil {
+		return nil
+	}
+	return EncodeParameters(msg.Gradients, Settings{Encoding: settings.Encoding, TopKRatio: settings.TopKRatio}, feedback)
+}
+
+// DecodeGradientUpdate restores the dense gradients of a compressed update in place.
+func DecodeGradientUpdate(msg *messages.GradientUpdate) error {
+	if msg.Gradients == nil {
+		return errors.New("Gradient update has no gradients")
+	}
+	return DecodeParameters(msg.Gradients)
+}
+
+// EncodeGlobalWeights compresses the global weights.
+// Top-k is meant for updates only, dropping most of the weights would break the model.
+func EncodeGlobalWeights(msg *messages.GlobalWeights, encoding messages.Encoding) error {
+	if encoding == messages.Encoding_ENCODING_NONE || msg.Parameters == nil {
+		return nil
+	}
+	if encoding == messages.Encoding_ENCODING_TOP_K {
+		return errors.New("Global weights can not be sent with the top-k encoding")
+	}
+	return EncodeParameters(msg.Parameters, Settings{Encoding: encoding}, nil)
+}
+
+// DecodeGlobalWeights restores the dense global weights in place.
+func DecodeGlobalWeights(msg *messages.GlobalWeights) error {
+	if msg.Parameters == nil {
+		return errors.New("Global weights have no parameters")
+	}
+	return DecodeParameters(msg.Parameters)
+}
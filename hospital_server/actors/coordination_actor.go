@@ -34,7 +34,7 @@ func (state *CoordinationActor) Receive(context actor.Context) {
 		state.trainingActor = trainingActor
 		state.aggregationActor = msg.AggregationActor
 		//start preprocessing
-		context.Send(preprocessingActor, &messages.ActivatePreprocTraining{})
+		context.Send(preprocessingActor, &messages.ActivatePreprocTraining{Dataset: msg.Dataset})
 
 	case *messages.ActivateEvaluation:
 		log.Println("Coordination Actor started:", context.Self().String())
@@ -43,12 +43,12 @@ func (state *CoordinationActor) Receive(context actor.Context) {
 		preprocessingActor := context.Spawn(propsPreprocessing)
 		state.preprocessingActor = preprocessingActor
 		//spawn evaluation actor
-		propsEvaluation := actor.PropsFromProducer(newEvaluationActor)
+		propsEvaluation := actor.PropsFromProducer(newEvaluationActor(msg.ModelVersion))
 		evaluationActor := context.Spawn(propsEvaluation)
 		state.evaluationActor = evaluationActor
 		state.aggregationActor = msg.AggregationActor
 		//start preprocessing
-		context.Send(preprocessingActor, &messages.ActivatePreprocEvaluation{})
+		context.Send(preprocessingActor, &messages.ActivatePreprocEvaluation{Dataset: msg.Dataset})
 
 	case *messages.GetTrainingActor:
 		context.Respond(state.trainingActor)
@@ -1,31 +1,78 @@
 package actors
 
 import (
-	utils "agentske/hospital_server/proto_conversion"
 	messages "agentske/proto"
 	nn "agentske/training"
 	"github.com/asynkron/protoactor-go/actor"
 	"log"
 )
 
+// EvaluationActor spills the streamed features to disk and evaluates the model on them once the
+// stream ended, reading them back chunk by chunk.
 type EvaluationActor struct {
 	coordinationActor *actor.PID
+	modelVersion      string
+	// Features received so far
+	evaluation *chunkSpool
+	chunks     uint32
+	// First chunk which could not be spilled
+	err error
 }
 
-func newEvaluationActor() actor.Actor {
-	return &EvaluationActor{}
+func newEvaluationActor(modelVersion string) actor.Producer {
+	return func() actor.Actor {
+		return &EvaluationActor{modelVersion: modelVersion}
+	}
 }
 
 func (state *EvaluationActor) Receive(context actor.Context) {
 	switch msg := context.Message().(type) {
-	case *messages.EvaluationDataSets:
+	case *messages.DataChunk:
+		if msg.Sequence != state.chunks {
+			log.Printf("Evaluation Actor expected chunk %d, received %d\n", state.chunks, msg.Sequence)
+		}
+		state.chunks++
+		var err error
+		state.evaluation, err = openSpool(state.evaluation)
+		if err == nil {
+			err = state.evaluation.append(msg.Data)
+		}
+		if err != nil && state.err == nil {
+			state.err = err
+		}
+
+	case *messages.DataStreamEnd:
 		log.Println("Evaluation Actor started:", context.Self().String())
 		state.coordinationActor = context.Parent()
-		Xv, Yv, _ := utils.GetDataSetsFromProto(msg.Validation)
-		nn.StartEvaluation(Xv, Yv, context)
+		defer state.close()
+		if state.err != nil {
+			log.Println("Evaluation Actor could not spill the features, not evaluating:", state.err)
+			context.Send(context.Parent(), &messages.EvaluationFinished{})
+			return
+		}
+		if msg.Chunks != state.chunks || state.evaluation == nil {
+			log.Printf("Evaluation Actor received %d of %d chunks, not evaluating\n", state.chunks, msg.Chunks)
+			context.Send(context.Parent(), &messages.EvaluationFinished{})
+			return
+		}
+		if state.evaluation.columns != int(msg.Dimension) {
+			log.Printf("Evaluation Actor received %d features per image, expected %d\n", state.evaluation.columns, msg.Dimension)
+			context.Send(context.Parent(), &messages.EvaluationFinished{})
+			return
+		}
+		nn.StartEvaluation(state.evaluation, int(msg.Dimension), msg.Features, msg.Standardized, state.modelVersion, context)
 		context.Send(context.Parent(), &messages.EvaluationFinished{})
 
 	case *actor.Stopped:
+		state.close()
 		log.Println("Evaluation Actor stopped:", context.Self().String())
 	}
 }
+
+// close removes the spilled features.
+func (state *EvaluationActor) close() {
+	if state.evaluation != nil {
+		state.evaluation.close()
+		state.evaluation = nil
+	}
+}
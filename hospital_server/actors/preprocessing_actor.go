@@ -18,31 +18,66 @@ func newPreprocessingActor() actor.Actor {
 }
 
 func (state *PreprocessingActor) Receive(context actor.Context) {
-	switch context.Message().(type) {
+	switch msg := context.Message().(type) {
 	case *messages.ActivatePreprocTraining:
 		log.Println("Preprocessing Actor started:", context.Self().String())
 		state.coordinationActor = context.Parent()
-		train, val := preprocessing.PreprocessImagesForTraining()
-		trainProto, _ := utils.ConvertToProtoData(train)
-		valProto, _ := utils.ConvertToProtoData(val)
-		message := &messages.TrainingDataSets{Training: trainProto, Validation: valProto}
 		future, _ := context.RequestFuture(state.coordinationActor, &messages.GetTrainingActor{}, 1*time.Second).Result()
 		pid, _ := future.(*actor.PID)
-		context.Send(pid, message)
+		config := utils.GetDatasetConfigFromProto(msg.Dataset)
+		chunks, err := streamChunks(context, pid, func(handle preprocessing.ChunkHandler) error {
+			return preprocessing.StreamTraining(config, handle)
+		})
+		if err != nil {
+			log.Println("Preprocessing failed:", err)
+			context.Send(context.Parent(), &messages.PreprocessingFinished{})
+			context.Send(context.Parent(), &messages.TrainingFinished{})
+			return
+		}
+		context.Send(pid, dataStreamEnd(chunks, config))
 		context.Send(context.Parent(), &messages.PreprocessingFinished{})
 
 	case *messages.ActivatePreprocEvaluation:
 		log.Println("Preprocessing Actor started:", context.Self().String())
 		state.coordinationActor = context.Parent()
-		val := preprocessing.PreprocessImagesForEvaluation()
-		valProto, _ := utils.ConvertToProtoData(val)
-		message := &messages.EvaluationDataSets{Validation: valProto}
 		future, _ := context.RequestFuture(state.coordinationActor, &messages.GetEvaluationActor{}, 1*time.Second).Result()
 		pid, _ := future.(*actor.PID)
-		context.Send(pid, message)
+		config := utils.GetDatasetConfigFromProto(msg.Dataset)
+		chunks, err := streamChunks(context, pid, func(handle preprocessing.ChunkHandler) error {
+			return preprocessing.StreamEvaluation(config, handle)
+		})
+		if err != nil {
+			log.Println("Preprocessing failed:", err)
+			context.Send(context.Parent(), &messages.PreprocessingFinished{})
+			context.Send(context.Parent(), &messages.EvaluationFinished{})
+			return
+		}
+		context.Send(pid, dataStreamEnd(chunks, config))
 		context.Send(context.Parent(), &messages.PreprocessingFinished{})
 
 	case *actor.Stopped:
 		log.Println("Preprocessing Actor stopped:", context.Self().String())
 	}
 }
+
+// streamChunks sends every chunk the stream emits to the actor as soon as its features are computed.
+func streamChunks(context actor.Context, pid *actor.PID, stream func(preprocessing.ChunkHandler) error) (uint32, error) {
+	var sequence uint32
+	err := stream(func(split string, chunk preprocessing.Data) error {
+		chunkProto, err := utils.ConvertToProtoData(chunk)
+		if err != nil {
+			return err
+		}
+		context.Send(pid, &messages.DataChunk{Split: split, Sequence: sequence, Data: chunkProto, Epoch: uint32(chunk.Epoch)})
+		sequence++
+		log.Printf("Sent %s chunk %d with %d images\n", split, sequence, len(chunk.Labels))
+		return nil
+	})
+	return sequence, err
+}
+
+// dataStreamEnd tells the receiver how many chunks were sent and which features they hold.
+func dataStreamEnd(chunks uint32, config preprocessing.DatasetConfig) *messages.DataStreamEnd {
+	features, _ := config.FeaturePipeline()
+	return &messages.DataStreamEnd{Chunks: chunks, Features: features.String(), Dimension: uint32(features.Dimension()), Standardized: features.Standardized()}
+}
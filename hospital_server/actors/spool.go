@@ -0,0 +1,118 @@
+package actors
+
+import (
+	utils "agentske/hospital_server/proto_conversion"
+	messages "agentske/proto"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+)
+
+// chunkSpool spills the chunks of one split to a temporary file as they arrive, and reads them back
+// one at a time on every pass, so the features never have to fit in memory.
+type chunkSpool struct {
+	file   *os.File
+	writer *bufio.Writer
+	// Features per image and images written so far
+	columns int
+	images  int
+}
+
+func newChunkSpool() (*chunkSpool, error) {
+	file, err := os.CreateTemp("", "hospital-features-*")
+	if err != nil {
+		return nil, err
+	}
+	return &chunkSpool{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// openSpool returns the spool, or a new one when it is nil.
+func openSpool(spool *chunkSpool) (*chunkSpool, error) {
+	if spool != nil {
+		return spool, nil
+	}
+	return newChunkSpool()
+}
+
+// append writes the chunk after the previous ones, each prefixed with its length. All images must
+// have the same number of features.
+func (s *chunkSpool) append(data *messages.Data) error {
+	if data == nil || len(data.Histograms) == 0 {
+		return nil
+	}
+	if len(data.Labels) != len(data.Histograms) {
+		return fmt.Errorf("Chunk has %d labels for %d images", len(data.Labels), len(data.Histograms))
+	}
+	columns := len(data.Histograms[0].Values)
+	if s.images == 0 {
+		s.columns = columns
+	}
+	for _, hist := range data.Histograms {
+		if len(hist.Values) != s.columns {
+			return fmt.Errorf("Chunk has images with %d features, expected %d", len(hist.Values), s.columns)
+		}
+	}
+
+	encoded, err := proto.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var length [binary.MaxVarintLen64]byte
+	if _, err := s.writer.Write(length[:binary.PutUvarint(length[:], uint64(len(encoded)))]); err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(encoded); err != nil {
+		return err
+	}
+	s.images += len(data.Labels)
+	return nil
+}
+
+// Chunks reads the chunks back in the order they were written.
+func (s *chunkSpool) Chunks(visit func(x, y *mat.Dense) error) error {
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(s.file)
+	for {
+		length, err := binary.ReadUvarint(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		encoded := make([]byte, length)
+		if _, err := io.ReadFull(reader, encoded); err != nil {
+			return fmt.Errorf("Spilled chunk is truncated: %w", err)
+		}
+		data := &messages.Data{}
+		if err := proto.Unmarshal(encoded, data); err != nil {
+			return err
+		}
+		x, y, err := utils.GetDataSetsFromProto(data)
+		if err != nil {
+			return err
+		}
+		if err := visit(x, y); err != nil {
+			return err
+		}
+	}
+	// Chunks appended later go after the last one
+	_, err := s.file.Seek(0, io.SeekEnd)
+	return err
+}
+
+// close removes the file.
+func (s *chunkSpool) close() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
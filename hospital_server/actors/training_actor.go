@@ -1,15 +1,25 @@
 package actors
 
 import (
-	utils "agentske/hospital_server/proto_conversion"
+	"agentske/preprocessing"
 	messages "agentske/proto"
 	nn "agentske/training"
 	"github.com/asynkron/protoactor-go/actor"
 	"log"
 )
 
+// TrainingActor spills the streamed features to disk and trains on them once the stream ended. Every
+// epoch reads its features back chunk by chunk, so the memory does not grow with the dataset or the
+// augmented epochs.
 type TrainingActor struct {
 	coordinationActor *actor.PID
+	// Features received so far, the training features by augmented epoch
+	training   map[uint32]*chunkSpool
+	validation *chunkSpool
+	test       *chunkSpool
+	chunks     uint32
+	// First chunk which could not be spilled
+	err error
 }
 
 func newTrainingActor() actor.Actor {
@@ -18,15 +28,99 @@ func newTrainingActor() actor.Actor {
 
 func (state *TrainingActor) Receive(context actor.Context) {
 	switch msg := context.Message().(type) {
-	case *messages.TrainingDataSets:
+	case *messages.DataChunk:
+		if msg.Sequence != state.chunks {
+			log.Printf("Training Actor expected chunk %d, received %d\n", state.chunks, msg.Sequence)
+		}
+		state.chunks++
+		spool, err := state.spool(msg)
+		if err == nil {
+			err = spool.append(msg.Data)
+		}
+		if err != nil && state.err == nil {
+			state.err = err
+		}
+
+	case *messages.DataStreamEnd:
 		log.Println("Training Actor started:", context.Self().String())
 		state.coordinationActor = context.Parent()
-		X, Y, _ := utils.GetDataSetsFromProto(msg.Training)
-		Xv, Yv, _ := utils.GetDataSetsFromProto(msg.Validation)
-		nn.StartTraining(X, Y, Xv, Yv, context)
+		defer state.close()
+		if state.err != nil {
+			log.Println("Training Actor could not spill the features, not training:", state.err)
+			context.Send(context.Parent(), &messages.TrainingFinished{})
+			return
+		}
+		if msg.Chunks != state.chunks || state.training == nil || state.validation == nil {
+			log.Printf("Training Actor received %d of %d chunks, not training\n", state.chunks, msg.Chunks)
+			context.Send(context.Parent(), &messages.TrainingFinished{})
+			return
+		}
+		// The epochs are numbered from 0 without gaps
+		sets := make([]nn.Samples, len(state.training))
+		spools := []*chunkSpool{state.validation}
+		for epoch, spool := range state.training {
+			if int(epoch) >= len(sets) {
+				log.Printf("Training Actor received augmented epoch %d of %d, not training\n", epoch, len(sets))
+				context.Send(context.Parent(), &messages.TrainingFinished{})
+				return
+			}
+			sets[epoch] = spool
+			spools = append(spools, spool)
+		}
+		var test nn.Samples
+		if state.test != nil {
+			test = state.test
+			spools = append(spools, state.test)
+		}
+		// The input layer is sized by the features, so they must have the reported dimension
+		for _, spool := range spools {
+			if spool.columns != int(msg.Dimension) {
+				log.Printf("Training Actor received %d features per image, expected %d\n", spool.columns, msg.Dimension)
+				context.Send(context.Parent(), &messages.TrainingFinished{})
+				return
+			}
+		}
+		nn.StartTraining(sets, state.validation, test, msg.Features, int(msg.Dimension), msg.Standardized, context)
 		context.Send(context.Parent(), &messages.TrainingFinished{})
 
 	case *actor.Stopped:
+		state.close()
 		log.Println("Training Actor stopped:", context.Self().String())
 	}
 }
+
+// spool returns the spool of the chunk's split, created with its first chunk.
+func (state *TrainingActor) spool(msg *messages.DataChunk) (*chunkSpool, error) {
+	var err error
+	switch msg.Split {
+	case preprocessing.SplitValidation:
+		state.validation, err = openSpool(state.validation)
+		return state.validation, err
+	case preprocessing.SplitTest:
+		state.test, err = openSpool(state.test)
+		return state.test, err
+	}
+	if state.training == nil {
+		state.training = make(map[uint32]*chunkSpool)
+	}
+	spool, err := openSpool(state.training[msg.Epoch])
+	if err != nil {
+		return nil, err
+	}
+	state.training[msg.Epoch] = spool
+	return spool, nil
+}
+
+// close removes the spilled features.
+func (state *TrainingActor) close() {
+	for _, spool := range state.training {
+		spool.close()
+	}
+	if state.validation != nil {
+		state.validation.close()
+	}
+	if state.test != nil {
+		state.test.close()
+	}
+	state.training, state.validation, state.test = nil, nil, nil
+}
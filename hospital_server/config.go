@@ -0,0 +1,254 @@
+package main
+
+import (
+	"agentske/preprocessing"
+	nn "agentske/training"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// datasetConfig is the hospital's dataset layout. It starts from the defaults and is overridden
+// by the configuration file, then the environment, then the command line flags.
+var datasetConfig preprocessing.DatasetConfig
+
+func loadDatasetConfig() error {
+	configFile := flag.String("dataset-config", os.Getenv("HOSPITAL_DATASET_CONFIG"), "JSON file describing the dataset root, class folders and split")
+	root := flag.String("data-root", "", "directory holding the class folders (env HOSPITAL_DATA_ROOT)")
+	trainRatio := flag.Float64("train-ratio", 0, "fraction of the training images used for training, the rest validates (env HOSPITAL_TRAIN_RATIO)")
+	testRatio := flag.Float64("test-ratio", 0, "fraction of the training images held out for a test after training, taken from validation (env HOSPITAL_TEST_RATIO)")
+	seed := flag.Int64("split-seed", 0, "seed of the training/validation split (env HOSPITAL_SPLIT_SEED)")
+	stratify := flag.Bool("stratify", true, "split every label by the ratios (env HOSPITAL_SPLIT_STRATIFY)")
+	groupByPatient := flag.Bool("group-by-patient", true, "keep all images of a patient in one split (env HOSPITAL_SPLIT_GROUP_BY_PATIENT)")
+	chunkSize := flag.Int("chunk-size", 0, "images whose features are sent to training together, 0 for the default (env HOSPITAL_CHUNK_SIZE)")
+	workers := flag.Int("workers", 0, "images preprocessed in parallel, 0 for one per CPU (env HOSPITAL_PREPROCESS_WORKERS)")
+	resize := flag.String("resize", "", "geometry the features are computed at, e.g. resize:size=224x224,policy=letterbox,kernel=bilinear (env HOSPITAL_RESIZE)")
+	normalization := flag.String("normalization", "", "intensity normalization before feature extraction, e.g. clahe:tiles=8x8,clip=2 (env HOSPITAL_NORMALIZATION)")
+	segmentation := flag.String("segmentation", "", "region of interest the features are restricted to, e.g. lung:margin=4 (env HOSPITAL_SEGMENTATION)")
+	featureNormalization := flag.String("feature-normalization", "", "normalization of the extracted features, e.g. hellinger+zscore (env HOSPITAL_FEATURE_NORMALIZATION)")
+	cache := flag.String("feature-cache", "", "directory keeping the extracted features, so later runs with the same preprocessing reuse them (env HOSPITAL_FEATURE_CACHE)")
+	flag.Parse()
+
+	datasetConfig = preprocessing.DefaultDatasetConfig()
+	if *configFile != "" {
+		var err error
+		if datasetConfig, err = preprocessing.LoadDatasetConfig(*configFile, datasetConfig); err != nil {
+			return err
+		}
+	}
+
+	if env := os.Getenv("HOSPITAL_DATA_ROOT"); env != "" {
+		datasetConfig.Root = env
+	}
+	if env := os.Getenv("HOSPITAL_TRAIN_RATIO"); env != "" {
+		ratio, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_TRAIN_RATIO: %w", err)
+		}
+		setTrainRatio(&datasetConfig, ratio)
+	}
+	if env := os.Getenv("HOSPITAL_TEST_RATIO"); env != "" {
+		ratio, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_TEST_RATIO: %w", err)
+		}
+		setTestRatio(&datasetConfig, ratio)
+	}
+	if env := os.Getenv("HOSPITAL_SPLIT_SEED"); env != "" {
+		s, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_SPLIT_SEED: %w", err)
+		}
+		datasetConfig.Split.Seed = s
+	}
+	if env := os.Getenv("HOSPITAL_SPLIT_STRATIFY"); env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_SPLIT_STRATIFY: %w", err)
+		}
+		datasetConfig.Split.Stratify = b
+	}
+	if env := os.Getenv("HOSPITAL_SPLIT_GROUP_BY_PATIENT"); env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_SPLIT_GROUP_BY_PATIENT: %w", err)
+		}
+		datasetConfig.Split.GroupByPatient = b
+	}
+	if env := os.Getenv("HOSPITAL_PREPROCESS_WORKERS"); env != "" {
+		w, err := strconv.Atoi(env)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_PREPROCESS_WORKERS: %w", err)
+		}
+		datasetConfig.Workers = w
+	}
+	if env := os.Getenv("HOSPITAL_CHUNK_SIZE"); env != "" {
+		size, err := strconv.Atoi(env)
+		if err != nil {
+			return fmt.Errorf("Invalid HOSPITAL_CHUNK_SIZE: %w", err)
+		}
+		datasetConfig.ChunkSize = size
+	}
+	// The salt is only read from the environment, so it does not show up in the process list
+	if env := os.Getenv("HOSPITAL_DEIDENTIFICATION_SALT"); env != "" {
+		datasetConfig.Deidentification.Enabled = true
+		datasetConfig.Deidentification.Salt = env
+	}
+	if env := os.Getenv("HOSPITAL_RESIZE"); env != "" {
+		datasetConfig.Resize = env
+	}
+	if env := os.Getenv("HOSPITAL_NORMALIZATION"); env != "" {
+		datasetConfig.Normalization = env
+	}
+	if env := os.Getenv("HOSPITAL_SEGMENTATION"); env != "" {
+		datasetConfig.Segmentation = env
+	}
+	if env := os.Getenv("HOSPITAL_FEATURE_NORMALIZATION"); env != "" {
+		datasetConfig.FeatureNormalization = env
+	}
+	if env := os.Getenv("HOSPITAL_FEATURE_CACHE"); env != "" {
+		datasetConfig.Cache = env
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "data-root":
+			datasetConfig.Root = *root
+		case "train-ratio":
+			setTrainRatio(&datasetConfig, *trainRatio)
+		case "test-ratio":
+			setTestRatio(&datasetConfig, *testRatio)
+		case "split-seed":
+			datasetConfig.Split.Seed = *seed
+		case "stratify":
+			datasetConfig.Split.Stratify = *stratify
+		case "group-by-patient":
+			datasetConfig.Split.GroupByPatient = *groupByPatient
+		case "workers":
+			datasetConfig.Workers = *workers
+		case "chunk-size":
+			datasetConfig.ChunkSize = *chunkSize
+		case "resize":
+			datasetConfig.Resize = *resize
+		case "normalization":
+			datasetConfig.Normalization = *normalization
+		case "segmentation":
+			datasetConfig.Segmentation = *segmentation
+		case "feature-normalization":
+			datasetConfig.FeatureNormalization = *featureNormalization
+		case "feature-cache":
+			datasetConfig.Cache = *cache
+		}
+	})
+	return validateDatasetConfig(datasetConfig)
+}
+
+// setTrainRatio validates the images neither trained on nor held out for the test.
+func setTrainRatio(config *preprocessing.DatasetConfig, ratio float64) {
+	config.Split.Train = ratio
+	config.Split.Validation = 1 - ratio - config.Split.Test
+}
+
+// setTestRatio takes the test images from the validation split.
+func setTestRatio(config *preprocessing.DatasetConfig, ratio float64) {
+	config.Split.Test = ratio
+	config.Split.Validation = 1 - config.Split.Train - ratio
+}
+
+// datasetConfigFor applies the overrides of a request to the hospital's dataset configuration:
+// a JSON body in the dataset configuration format, then the root, train_ratio, test_ratio and seed query parameters.
+// The root, the class folders and the manifests have to stay inside the hospital's dataset root.
+func datasetConfigFor(r *http.Request) (preprocessing.DatasetConfig, error) {
+	config := datasetConfig
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return config, err
+	}
+	if len(body) > 0 {
+		if config, err = preprocessing.MergeDatasetConfig(config, body); err != nil {
+			return config, err
+		}
+		// Requests can not weaken the hospital's de-identification
+		if datasetConfig.Deidentification.Enabled {
+			config.Deidentification = datasetConfig.Deidentification
+		}
+		// nor make it write to another directory
+		config.Cache = datasetConfig.Cache
+	}
+
+	query := r.URL.Query()
+	if root := query.Get("root"); root != "" {
+		config.Root = root
+	}
+	if value := query.Get("train_ratio"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return config, fmt.Errorf("Invalid train_ratio: %w", err)
+		}
+		setTrainRatio(&config, ratio)
+	}
+	if value := query.Get("test_ratio"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return config, fmt.Errorf("Invalid test_ratio: %w", err)
+		}
+		setTestRatio(&config, ratio)
+	}
+	if value := query.Get("seed"); value != "" {
+		s, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return config, fmt.Errorf("Invalid seed: %w", err)
+		}
+		config.Split.Seed = s
+	}
+	if err := checkDatasetPaths(config); err != nil {
+		return config, err
+	}
+	return config, validateDatasetConfig(config)
+}
+
+// validateDatasetConfig also checks the augmentation against the epochs the hospital trains.
+func validateDatasetConfig(config preprocessing.DatasetConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	return config.Augmentation.ValidateEpochs(nn.DefaultEpochs)
+}
+
+// checkDatasetPaths rejects a configuration which reads images or manifests outside the hospital's
+// dataset root, so requests can not read arbitrary directories.
+func checkDatasetPaths(config preprocessing.DatasetConfig) error {
+	root, err := filepath.Abs(datasetConfig.Root)
+	if err != nil {
+		return err
+	}
+	paths := []string{config.Root}
+	for _, manifest := range []string{config.TrainingManifest, config.EvaluationManifest} {
+		if manifest != "" {
+			paths = append(paths, filepath.Join(config.Root, manifest))
+		}
+	}
+	for _, class := range config.Classes {
+		for _, folder := range []string{class.TrainingFolder, class.EvaluationFolder} {
+			if folder != "" {
+				paths = append(paths, filepath.Join(config.Root, folder))
+			}
+		}
+	}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside the dataset root %s", path, datasetConfig.Root)
+		}
+	}
+	return nil
+}
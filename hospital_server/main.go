@@ -2,21 +2,37 @@ package main
 
 import (
 	actors "agentske/hospital_server/actors"
+	utils "agentske/hospital_server/proto_conversion"
+	"agentske/preprocessing"
 	messages "agentske/proto"
+	nn "agentske/training"
 	"fmt"
 	"github.com/asynkron/protoactor-go/actor"
 	"github.com/asynkron/protoactor-go/remote"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 func main() {
+	if err := loadDatasetConfig(); err != nil {
+		log.Fatal("Invalid dataset configuration: ", err)
+	}
+	log.Println("Reading images from", datasetConfig.Root)
 	http.HandleFunc("/training", handleTraining)
 	http.HandleFunc("/evaluation", handleEvaluation)
+	http.HandleFunc("/crossvalidation", handleCrossValidation)
 	http.ListenAndServe(":8080", nil)
 }
 
 func handleTraining(w http.ResponseWriter, r *http.Request) {
+	dataset, err := datasetConfigFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Create a new actor system for each request
 	actorSystem := actor.NewActorSystem()
 	decider := func(reason interface{}) actor.Directive {
@@ -37,15 +53,20 @@ func handleTraining(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		panic(err)
-		return
 	}
 	// Send a message to the actor
-	rootContext.Send(pid, &messages.ActivateLocalTraining{AggregationActor: spawnResponse.Pid})
+	rootContext.Send(pid, &messages.ActivateLocalTraining{AggregationActor: spawnResponse.Pid, Dataset: utils.ConvertToProtoDatasetConfig(dataset)})
 
 	fmt.Fprintln(w, "Request processed")
 }
 
 func handleEvaluation(w http.ResponseWriter, r *http.Request) {
+	dataset, err := datasetConfigFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Create a new actor system for each request
 	actorSystem := actor.NewActorSystem()
 	decider := func(reason interface{}) actor.Directive {
@@ -62,14 +83,86 @@ func handleEvaluation(w http.ResponseWriter, r *http.Request) {
 	props := actor.PropsFromProducer(actors.NewCoordinationActor, actor.WithSupervisor(supervisor))
 
 	pid := rootContext.Spawn(props)
+	// Nothing is evaluated, so the coordination actor and the remote are stopped again
+	fail := func(message string, status int) {
+		rootContext.Stop(pid)
+		actors.Remote.Shutdown(true)
+		http.Error(w, message, status)
+	}
 	spawnResponse, err := actors.Remote.SpawnNamed("127.0.0.1:8091", "AggregationActor", "AggregationActor", time.Second)
-
 	if err != nil {
-		panic(err)
+		fail(fmt.Sprintf("Could not reach the aggregator: %v", err), http.StatusBadGateway)
+		return
+	}
+	// Pin the requested registry version (or tag) to a version number before evaluating it
+	resolved, err := rootContext.RequestFuture(spawnResponse.Pid, &messages.ResolveModelVersion{ModelVersion: r.URL.Query().Get("version")}, 5*time.Second).Result()
+	if err != nil {
+		fail(fmt.Sprintf("The aggregator did not resolve the model version: %v", err), http.StatusBadGateway)
 		return
 	}
+	version, ok := resolved.(*messages.ResolvedModelVersion)
+	if !ok {
+		fail("Unexpected response to the model version request", http.StatusBadGateway)
+		return
+	}
+	if version.Error != "" {
+		fail(version.Error, http.StatusBadRequest)
+		return
+	}
+
 	// Send a message to the actor
-	rootContext.Send(pid, &messages.ActivateEvaluation{AggregationActor: spawnResponse.Pid})
+	rootContext.Send(pid, &messages.ActivateEvaluation{AggregationActor: spawnResponse.Pid, ModelVersion: strconv.FormatUint(version.Version, 10), Dataset: utils.ConvertToProtoDatasetConfig(dataset)})
 
-	fmt.Fprintln(w, "Request processed")
+	fmt.Fprintln(w, "Request processed, evaluating model version", version.Version, version.Tags)
+}
+
+// handleCrossValidation assesses a model trained on the hospital's images alone with stratified k-fold
+// cross-validation and responds with the metrics of every fold and their mean and standard deviation.
+// The folds query parameter sets their number, the split seed their assignment.
+func handleCrossValidation(w http.ResponseWriter, r *http.Request) {
+	dataset, err := datasetConfigFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pipeline, err := dataset.FeaturePipeline()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	config := nn.DefaultCrossValidationConfig()
+	config.Seed = dataset.Split.Seed
+	config.Standardize = pipeline.Standardized()
+	if value := r.URL.Query().Get("folds"); value != "" {
+		if config.Folds, err = strconv.Atoi(value); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid folds: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, err := preprocessing.PreprocessImagesForCrossValidation(dataset)
+	if err != nil {
+		log.Println("Preprocessing failed:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	dataProto, err := utils.ConvertToProtoData(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	X, Y, _ := utils.GetDataSetsFromProto(dataProto)
+	var groups []string
+	if dataset.Split.GroupByPatient {
+		for _, meta := range data.Metadata {
+			groups = append(groups, meta.PatientID)
+		}
+	}
+	report, err := nn.CrossValidate(X, Y, groups, config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Print("Cross-validation:\n", report)
+	fmt.Fprint(w, report)
 }
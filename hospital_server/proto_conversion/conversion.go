@@ -20,6 +20,10 @@ func ConvertToProtoData(data preprocessing.Data) (*messages.Data, error) {
 		protoData.Histograms = append(protoData.Histograms, protoHist)
 	}
 
+	for _, meta := range data.Metadata {
+		protoData.Metadata = append(protoData.Metadata, ConvertToProtoMetadata(meta))
+	}
+
 	return protoData, nil
 }
 
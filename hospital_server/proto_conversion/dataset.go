@@ -0,0 +1,165 @@
+package proto_conversion
+
+import (
+	"agentske/preprocessing"
+	messages "agentske/proto"
+)
+
+func ConvertToProtoDatasetConfig(config preprocessing.DatasetConfig) *messages.DatasetConfig {
+	protoConfig := &messages.DatasetConfig{
+		Root:                 config.Root,
+		TrainingManifest:     config.TrainingManifest,
+		EvaluationManifest:   config.EvaluationManifest,
+		Workers:              int32(config.Workers),
+		ChunkSize:            int32(config.ChunkSize),
+		Features:             config.Features,
+		Resize:               config.Resize,
+		Normalization:        config.Normalization,
+		Segmentation:         config.Segmentation,
+		FeatureNormalization: config.FeatureNormalization,
+		Cache:                config.Cache,
+		Split: &messages.DatasetSplit{
+			Train:          config.Split.Train,
+			Validation:     config.Split.Validation,
+			Test:           config.Split.Test,
+			Seed:           config.Split.Seed,
+			Stratify:       config.Split.Stratify,
+			GroupByPatient: config.Split.GroupByPatient,
+		},
+		Deidentification: &messages.Deidentification{
+			Enabled:                 config.Deidentification.Enabled,
+			Salt:                    config.Deidentification.Salt,
+			MaxDateShift:            int32(config.Deidentification.MaxDateShift),
+			Actions:                 config.Deidentification.Actions,
+			AllowBurnedInAnnotation: config.Deidentification.AllowBurnedInAnnotation,
+		},
+		Augmentation: &messages.Augmentation{
+			Rotation:    config.Augmentation.Rotation,
+			Translation: config.Augmentation.Translation,
+			Scale:       config.Augmentation.Scale,
+			Flip:        config.Augmentation.Flip,
+			Brightness:  config.Augmentation.Brightness,
+			Contrast:    config.Augmentation.Contrast,
+			Noise:       config.Augmentation.Noise,
+			Copies:      int32(config.Augmentation.Copies),
+			Epochs:      int32(config.Augmentation.Epochs),
+			Seed:        config.Augmentation.Seed,
+		},
+	}
+	for _, scale := range config.LBP {
+		protoConfig.Lbp = append(protoConfig.Lbp, &messages.LBPParams{
+			Radius:    uint32(scale.Radius),
+			Neighbors: uint32(scale.Neighbors),
+			GridX:     uint32(scale.GridX),
+			GridY:     uint32(scale.GridY),
+			Histogram: scale.Histogram,
+			Operator:  scale.Operator,
+			Tolerance: uint32(scale.Tolerance),
+		})
+	}
+	for _, name := range config.ClassNames() {
+		class := config.Classes[name]
+		protoConfig.Classes = append(protoConfig.Classes, &messages.DatasetClass{
+			Name:             name,
+			Label:            class.Label,
+			TrainingFolder:   class.TrainingFolder,
+			EvaluationFolder: class.EvaluationFolder,
+		})
+	}
+	return protoConfig
+}
+
+// GetDatasetConfigFromProto falls back to the default layout when no configuration was sent.
+func GetDatasetConfigFromProto(protoConfig *messages.DatasetConfig) preprocessing.DatasetConfig {
+	if protoConfig == nil {
+		return preprocessing.DefaultDatasetConfig()
+	}
+	config := preprocessing.DatasetConfig{
+		Root:                 protoConfig.Root,
+		Classes:              make(map[string]preprocessing.ClassConfig),
+		TrainingManifest:     protoConfig.TrainingManifest,
+		EvaluationManifest:   protoConfig.EvaluationManifest,
+		Workers:              int(protoConfig.Workers),
+		ChunkSize:            int(protoConfig.ChunkSize),
+		Features:             protoConfig.Features,
+		Resize:               protoConfig.Resize,
+		Normalization:        protoConfig.Normalization,
+		Segmentation:         protoConfig.Segmentation,
+		FeatureNormalization: protoConfig.FeatureNormalization,
+		Cache:                protoConfig.Cache,
+	}
+	for _, class := range protoConfig.Classes {
+		config.Classes[class.Name] = preprocessing.ClassConfig{
+			Label:            class.Label,
+			TrainingFolder:   class.TrainingFolder,
+			EvaluationFolder: class.EvaluationFolder,
+		}
+	}
+	for _, scale := range protoConfig.Lbp {
+		config.LBP = append(config.LBP, preprocessing.Params{
+			Radius:    uint8(scale.Radius),
+			Neighbors: uint8(scale.Neighbors),
+			GridX:     uint8(scale.GridX),
+			GridY:     uint8(scale.GridY),
+			Histogram: scale.Histogram,
+			Operator:  scale.Operator,
+			Tolerance: uint8(scale.Tolerance),
+		})
+	}
+	if protoConfig.Split != nil {
+		config.Split = preprocessing.SplitConfig{
+			Train:          protoConfig.Split.Train,
+			Validation:     protoConfig.Split.Validation,
+			Test:           protoConfig.Split.Test,
+			Seed:           protoConfig.Split.Seed,
+			Stratify:       protoConfig.Split.Stratify,
+			GroupByPatient: protoConfig.Split.GroupByPatient,
+		}
+	}
+	if deid := protoConfig.Deidentification; deid != nil {
+		config.Deidentification = preprocessing.DeidentificationConfig{
+			Enabled:                 deid.Enabled,
+			Salt:                    deid.Salt,
+			MaxDateShift:            int(deid.MaxDateShift),
+			Actions:                 deid.Actions,
+			AllowBurnedInAnnotation: deid.AllowBurnedInAnnotation,
+		}
+	}
+	if augmentation := protoConfig.Augmentation; augmentation != nil {
+		config.Augmentation = preprocessing.AugmentationConfig{
+			Rotation:    augmentation.Rotation,
+			Translation: augmentation.Translation,
+			Scale:       augmentation.Scale,
+			Flip:        augmentation.Flip,
+			Brightness:  augmentation.Brightness,
+			Contrast:    augmentation.Contrast,
+			Noise:       augmentation.Noise,
+			Copies:      int(augmentation.Copies),
+			Epochs:      int(augmentation.Epochs),
+			Seed:        augmentation.Seed,
+		}
+	}
+	return config
+}
+
+func ConvertToProtoMetadata(meta preprocessing.ImageMetadata) *messages.ImageMetadata {
+	return &messages.ImageMetadata{
+		Path:            meta.Path,
+		PatientId:       meta.PatientID,
+		AcquisitionDate: meta.AcquisitionDate,
+		View:            meta.View,
+		Sex:             meta.Sex,
+		AgeBand:         meta.AgeBand,
+	}
+}
+
+func GetMetadataFromProto(meta *messages.ImageMetadata) preprocessing.ImageMetadata {
+	return preprocessing.ImageMetadata{
+		Path:            meta.Path,
+		PatientID:       meta.PatientId,
+		AcquisitionDate: meta.AcquisitionDate,
+		View:            meta.View,
+		Sex:             meta.Sex,
+		AgeBand:         meta.AgeBand,
+	}
+}
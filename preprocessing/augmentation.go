@@ -0,0 +1,183 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// AugmentationConfig describes the random variations of the training images. Validation and
+// evaluation images are never augmented. Every variation is drawn uniformly up to its maximum.
+type AugmentationConfig struct {
+	// Maximum rotation in degrees, in either direction
+	Rotation float64 `json:"rotation"`
+	// Maximum translation as a fraction of the image size
+	Translation float64 `json:"translation"`
+	// Maximum relative change of the size, 0.1 scales between 90% and 110%
+	Scale float64 `json:"scale"`
+	// Probability of a horizontal flip
+	Flip float64 `json:"flip"`
+	// Maximum brightness offset as a fraction of the intensity range
+	Brightness float64 `json:"brightness"`
+	// Maximum relative change of the contrast around the mean intensity
+	Contrast float64 `json:"contrast"`
+	// Standard deviation of the Gaussian noise in gray levels
+	Noise float64 `json:"noise"`
+	// Augmented copies of every training image, added to the originals
+	Copies int `json:"copies"`
+	// Augmented versions of the training split, epoch e trains on version e modulo Epochs. Used instead
+	// of Copies to see new variations every epoch. Limited to the epochs trained, see ValidateEpochs.
+	Epochs int   `json:"epochs"`
+	Seed   int64 `json:"seed"`
+}
+
+// Enabled reports whether the training split is augmented.
+func (c AugmentationConfig) Enabled() bool {
+	return c.Copies > 0 || c.Epochs > 0
+}
+
+func (c AugmentationConfig) Validate() error {
+	if c.Copies < 0 || c.Epochs < 0 {
+		return errors.New("The augmentation copies and epochs can not be negative")
+	}
+	if c.Copies > 0 && c.Epochs > 0 {
+		return errors.New("Augment the training images either with fixed copies or per epoch, not both")
+	}
+	switch {
+	case c.Rotation < 0 || c.Rotation > 180:
+		return errors.New("The augmentation rotation must be between 0 and 180 degrees")
+	case c.Translation < 0 || c.Translation > 0.5:
+		return errors.New("The augmentation translation must be between 0 and 0.5")
+	case c.Scale < 0 || c.Scale >= 1:
+		return errors.New("The augmentation scale must be at least 0 and below 1")
+	case c.Flip < 0 || c.Flip > 1:
+		return errors.New("The augmentation flip probability must be between 0 and 1")
+	case c.Brightness < 0 || c.Brightness > 1 || c.Contrast < 0 || c.Contrast > 1:
+		return errors.New("The augmentation brightness and contrast must be between 0 and 1")
+	case c.Noise < 0:
+		return errors.New("The augmentation noise can not be negative")
+	}
+	return nil
+}
+
+// ValidateEpochs checks the augmented versions against the number of epochs trained, versions beyond
+// them would never be trained on.
+func (c AugmentationConfig) ValidateEpochs(trained int) error {
+	if c.Epochs > trained {
+		return fmt.Errorf("Only %d epochs are trained, not all of %d augmented epochs", trained, c.Epochs)
+	}
+	return nil
+}
+
+// augmentedSplit is one version of the training split: the records and the variation of every one
+// of them, nil for the originals. augment is nil when no record is augmented.
+type augmentedSplit struct {
+	records []ManifestRecord
+	augment func(i int) *augmentation
+}
+
+// trainingSplits returns the versions of the training split to emit. The variations are drawn in
+// order from the seed as the records are emitted, so they are never held ahead of their chunk and
+// do not depend on how the images are scheduled.
+func (c AugmentationConfig) trainingSplits(records []ManifestRecord) []augmentedSplit {
+	if !c.Enabled() {
+		return []augmentedSplit{{records: records}}
+	}
+	rng := rand.New(rand.NewSource(c.Seed))
+	if c.Epochs > 0 {
+		splits := make([]augmentedSplit, c.Epochs)
+		for e := range splits {
+			splits[e].records = records
+			splits[e].augment = func(int) *augmentation { return c.sample(rng) }
+		}
+		return splits
+	}
+	// The originals, then every copy of all images, each in the order of the split
+	split := augmentedSplit{augment: func(i int) *augmentation {
+		if i < len(records) {
+			return nil
+		}
+		return c.sample(rng)
+	}}
+	for i := 0; i <= c.Copies; i++ {
+		split.records = append(split.records, records...)
+	}
+	return []augmentedSplit{split}
+}
+
+// augmentation is the variation applied to one image.
+type augmentation struct {
+	// Rotation in radians, scale factor and translation in pixels per side length
+	angle, scale, dx, dy float64
+	flip                 bool
+	// Intensity offset in gray levels and contrast factor
+	brightness, contrast float64
+	noise                float64
+	noiseSeed            int64
+}
+
+func (c AugmentationConfig) sample(rng *rand.Rand) *augmentation {
+	uniform := func(max float64) float64 { return (2*rng.Float64() - 1) * max }
+	return &augmentation{
+		angle:      uniform(c.Rotation) * math.Pi / 180,
+		scale:      1 + uniform(c.Scale),
+		dx:         uniform(c.Translation),
+		dy:         uniform(c.Translation),
+		flip:       rng.Float64() < c.Flip,
+		brightness: uniform(c.Brightness) * 255,
+		contrast:   1 + uniform(c.Contrast),
+		noise:      c.Noise,
+		noiseSeed:  rng.Int63(),
+	}
+}
+
+// apply returns the augmented pixels. The geometric transformation maps every output pixel back to
+// the input around the image center and interpolates bilinearly; pixels mapped outside the image
+// repeat its border, so the transformation adds no artificial edges.
+func (a *augmentation) apply(pixels [][]uint8) [][]uint8 {
+	width := len(pixels)
+	height := len(pixels[0])
+	cx, cy := float64(width-1)/2, float64(height-1)/2
+	cos, sin := math.Cos(a.angle), math.Sin(a.angle)
+
+	var sum float64
+	for _, column := range pixels {
+		for _, v := range column {
+			sum += float64(v)
+		}
+	}
+	mean := sum / float64(width*height)
+	rng := rand.New(rand.NewSource(a.noiseSeed))
+
+	sample := func(x, y float64) float64 {
+		x0, y0 := math.Floor(x), math.Floor(y)
+		fx, fy := x-x0, y-y0
+		at := func(x, y int) float64 { return float64(pixels[clamp(x, width)][clamp(y, height)]) }
+		ix, iy := int(x0), int(y0)
+		top := (1-fx)*at(ix, iy) + fx*at(ix+1, iy)
+		bottom := (1-fx)*at(ix, iy+1) + fx*at(ix+1, iy+1)
+		return (1-fy)*top + fy*bottom
+	}
+
+	out := make([][]uint8, width)
+	for x := range out {
+		out[x] = make([]uint8, height)
+		for y := range out[x] {
+			// Undo the translation, the scaling and the rotation, then the flip
+			u := float64(x) - cx - a.dx*float64(width)
+			v := float64(y) - cy - a.dy*float64(height)
+			u, v = (cos*u+sin*v)/a.scale, (-sin*u+cos*v)/a.scale
+			if a.flip {
+				u = -u
+			}
+			value := sample(u+cx, v+cy)
+			value = (value-mean)*a.contrast + mean + a.brightness
+			if a.noise > 0 {
+				value += rng.NormFloat64() * a.noise
+			}
+			out[x][y] = uint8(math.Round(math.Max(0, math.Min(value, 255))))
+		}
+	}
+	return out
+}
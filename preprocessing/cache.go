@@ -0,0 +1,92 @@
+package preprocessing
+
+import (
+	"agentske/fileutil"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// featureCacheVersion is part of every cache key. Increase it whenever the same pipeline description
+// starts to produce other features, e.g. after fixing an extractor, so the stale entries are not reused.
+const featureCacheVersion = 1
+
+// featureCache keeps the features of every image on disk, addressed by the content of the image and
+// everything else that determines them: the feature pipeline, the de-identification profile and the
+// manifest record. Changing any of them changes the keys, so stale entries are never read; they stay on
+// disk until the directory is cleared. Augmented images are not cached.
+type featureCache struct {
+	dir string
+	// Digest of the configuration shared by all images
+	config [sha256.Size]byte
+}
+
+// featureCacheEntry holds the features of an image and its de-identified metadata, which for a DICOM
+// image comes from the header and would otherwise need the image to be decoded.
+type featureCacheEntry struct {
+	Features []float64     `json:"features"`
+	Metadata ImageMetadata `json:"metadata"`
+}
+
+// newFeatureCache returns nil when dir is empty, which disables the cache.
+func newFeatureCache(dir string, features FeaturePipeline, deid DeidentificationConfig) (*featureCache, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("Could not create the feature cache: %w", err)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s\x00", featureCacheVersion, features.String())
+	// The salt only enters the digest, it is not stored
+	if err := json.NewEncoder(h).Encode(deid); err != nil {
+		return nil, err
+	}
+	c := &featureCache{dir: dir}
+	h.Sum(c.config[:0])
+	return c, nil
+}
+
+// key addresses the features of the image content listed as the record.
+func (c *featureCache) key(content []byte, record ImageMetadata) string {
+	image := sha256.Sum256(content)
+	h := sha256.New()
+	h.Write(c.config[:])
+	h.Write(image[:])
+	json.NewEncoder(h).Encode(record)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path spreads the entries over subdirectories by the first byte of the key.
+func (c *featureCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// load returns the cached entry. Unreadable entries count as missing and are replaced.
+func (c *featureCache) load(key string) (featureCacheEntry, bool) {
+	var entry featureCacheEntry
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Features == nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// store writes the entry atomically, so concurrent runs and crashes never leave a partial entry.
+func (c *featureCache) store(key string, entry featureCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(path, data)
+}
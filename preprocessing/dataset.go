@@ -0,0 +1,292 @@
+package preprocessing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ClassConfig maps a class to its label and to the folders holding its images, relative to the dataset root.
+type ClassConfig struct {
+	Label            float64 `json:"label"`
+	TrainingFolder   string  `json:"training_folder"`
+	EvaluationFolder string  `json:"evaluation_folder"`
+}
+
+// SplitConfig holds the fractions of the training images used for training, validation and testing.
+type SplitConfig struct {
+	Train      float64 `json:"train"`
+	Validation float64 `json:"validation"`
+	// Held out images evaluated once after training, no test split when 0
+	Test float64 `json:"test"`
+	Seed int64   `json:"seed"`
+	// Split every label by the ratios, so small sites still validate on both classes
+	Stratify bool `json:"stratify"`
+	// Keep all images of a patient in one split, images without a patient id are split on their own
+	GroupByPatient bool `json:"group_by_patient"`
+}
+
+// DatasetConfig describes where a hospital keeps its images and how they are split.
+// A manifest, relative to the root, lists the images of a phase instead of the class folders.
+type DatasetConfig struct {
+	Root               string                 `json:"root"`
+	Classes            map[string]ClassConfig `json:"classes"`
+	TrainingManifest   string                 `json:"training_manifest"`
+	EvaluationManifest string                 `json:"evaluation_manifest"`
+	Split              SplitConfig            `json:"split"`
+	// Images preprocessed in parallel, 0 for one per CPU
+	Workers int `json:"workers"`
+	// Images whose features are emitted together, 0 for DefaultChunkSize
+	ChunkSize int `json:"chunk_size"`
+	// Scales of the LBP features, one object or a list in JSON
+	LBP Scales `json:"lbp"`
+	// Feature pipeline, e.g. lbp:r=1,p=8+hog:cells=6x6, used instead of the LBP scales when set
+	Features string `json:"features"`
+	// Geometry the features are computed at, e.g. resize:size=224x224,policy=letterbox,kernel=bilinear,
+	// 150x150 stretched when empty
+	Resize string `json:"resize"`
+	// Intensity normalization applied before the features, e.g. window:low=10,high=245+clahe:tiles=8x8,clip=2
+	Normalization string `json:"normalization"`
+	// Region of interest the features are restricted to, e.g. lung:margin=4, the whole image when empty
+	Segmentation string `json:"segmentation"`
+	// Normalization of the extracted features, e.g. hellinger+zscore, the raw features when empty
+	FeatureNormalization string `json:"feature_normalization"`
+	// Profile applied to the metadata of every image before it leaves preprocessing
+	Deidentification DeidentificationConfig `json:"deidentification"`
+	// Random variations of the training images
+	Augmentation AugmentationConfig `json:"augmentation"`
+	// Directory keeping the extracted features for later runs, no cache when empty
+	Cache string `json:"cache"`
+}
+
+// DefaultDatasetConfig returns the layout the project started with: data/<class>_training and data/<class>_eval.
+func DefaultDatasetConfig() DatasetConfig {
+	return DatasetConfig{
+		Root: "data",
+		Classes: map[string]ClassConfig{
+			"normal": {Label: 0.0, TrainingFolder: "normal_training", EvaluationFolder: "normal_eval"},
+			"covid":  {Label: 1.0, TrainingFolder: "covid_training", EvaluationFolder: "covid_eval"},
+		},
+		Split: SplitConfig{Train: 0.8, Validation: 0.2, Seed: 42, Stratify: true, GroupByPatient: true},
+		LBP:   Scales{lbphParams},
+	}
+}
+
+// LoadDatasetConfig reads a JSON dataset configuration. Fields missing from the file keep their value in base.
+func LoadDatasetConfig(path string, base DatasetConfig) (DatasetConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return base, err
+	}
+	return MergeDatasetConfig(base, data)
+}
+
+// MergeDatasetConfig overrides the fields of base present in the JSON document.
+// A classes object replaces the whole class mapping.
+func MergeDatasetConfig(base DatasetConfig, data []byte) (DatasetConfig, error) {
+	var override struct {
+		Root                 *string                 `json:"root"`
+		Classes              map[string]ClassConfig  `json:"classes"`
+		TrainingManifest     *string                 `json:"training_manifest"`
+		EvaluationManifest   *string                 `json:"evaluation_manifest"`
+		Split                json.RawMessage         `json:"split"`
+		Workers              *int                    `json:"workers"`
+		ChunkSize            *int                    `json:"chunk_size"`
+		LBP                  Scales                  `json:"lbp"`
+		Features             *string                 `json:"features"`
+		Resize               *string                 `json:"resize"`
+		Normalization        *string                 `json:"normalization"`
+		Segmentation         *string                 `json:"segmentation"`
+		FeatureNormalization *string                 `json:"feature_normalization"`
+		Deidentification     *DeidentificationConfig `json:"deidentification"`
+		Augmentation         *AugmentationConfig     `json:"augmentation"`
+		Cache                *string                 `json:"cache"`
+	}
+	if err := json.Unmarshal(data, &override); err != nil {
+		return base, fmt.Errorf("Invalid dataset configuration: %w", err)
+	}
+	config := base
+	if override.Root != nil {
+		config.Root = *override.Root
+	}
+	if override.Classes != nil {
+		config.Classes = override.Classes
+	}
+	if override.TrainingManifest != nil {
+		config.TrainingManifest = *override.TrainingManifest
+	}
+	if override.EvaluationManifest != nil {
+		config.EvaluationManifest = *override.EvaluationManifest
+	}
+	// The split options missing from the document keep their value
+	if override.Split != nil {
+		if err := json.Unmarshal(override.Split, &config.Split); err != nil {
+			return base, fmt.Errorf("Invalid dataset split: %w", err)
+		}
+	}
+	if override.Workers != nil {
+		config.Workers = *override.Workers
+	}
+	if override.ChunkSize != nil {
+		config.ChunkSize = *override.ChunkSize
+	}
+	if override.LBP != nil {
+		config.LBP = override.LBP
+	}
+	if override.Features != nil {
+		config.Features = *override.Features
+	}
+	if override.Resize != nil {
+		config.Resize = *override.Resize
+	}
+	if override.Normalization != nil {
+		config.Normalization = *override.Normalization
+	}
+	if override.Segmentation != nil {
+		config.Segmentation = *override.Segmentation
+	}
+	if override.FeatureNormalization != nil {
+		config.FeatureNormalization = *override.FeatureNormalization
+	}
+	if override.Deidentification != nil {
+		config.Deidentification = *override.Deidentification
+	}
+	if override.Augmentation != nil {
+		config.Augmentation = *override.Augmentation
+	}
+	if override.Cache != nil {
+		config.Cache = *override.Cache
+	}
+	return config, nil
+}
+
+// Validate checks the class mapping and the split ratios.
+func (c DatasetConfig) Validate() error {
+	if c.Root == "" {
+		return errors.New("The dataset root is not set")
+	}
+	hasManifest := c.TrainingManifest != "" || c.EvaluationManifest != ""
+	if len(c.Classes) == 0 && !hasManifest {
+		return errors.New("The dataset has no classes")
+	}
+	for name, class := range c.Classes {
+		// With a manifest, classes may only name labels
+		if class.TrainingFolder == "" && class.EvaluationFolder == "" && !hasManifest {
+			return fmt.Errorf("Class %s has no training or evaluation folder", name)
+		}
+	}
+	if c.Split.Train <= 0 || c.Split.Validation < 0 || c.Split.Test < 0 {
+		return errors.New("The split ratios must be positive")
+	}
+	if c.Workers < 0 || c.ChunkSize < 0 {
+		return errors.New("The number of workers and the chunk size can not be negative")
+	}
+	if _, err := c.FeaturePipeline(); err != nil {
+		return err
+	}
+	if err := c.Deidentification.Validate(); err != nil {
+		return err
+	}
+	if err := c.Augmentation.Validate(); err != nil {
+		return err
+	}
+	if math.Abs(c.Split.Train+c.Split.Validation+c.Split.Test-1) > 1e-9 {
+		return errors.New("The split ratios must add up to 1")
+	}
+	return nil
+}
+
+// FeaturePipeline creates the resize, the normalization steps, the segmentation, the extractors and the
+// feature normalization of the configured features.
+func (c DatasetConfig) FeaturePipeline() (FeaturePipeline, error) {
+	var pipeline FeaturePipeline
+	var err error
+	if c.Features != "" {
+		pipeline, err = ParsePipeline(c.Features)
+	} else {
+		pipeline, err = c.LBP.Pipeline()
+	}
+	if err != nil {
+		return pipeline, err
+	}
+	if c.Resize != "" {
+		if pipeline.Resize != (ResizeParams{}) {
+			return pipeline, errors.New("The resize is set both in the features and in the resize field")
+		}
+		if pipeline.Resize, err = ParseResize(c.Resize); err != nil {
+			return pipeline, err
+		}
+	}
+	if c.Normalization != "" {
+		if len(pipeline.Normalization) > 0 {
+			return pipeline, errors.New("The normalization is set both in the features and in the normalization field")
+		}
+		if pipeline.Normalization, err = ParseNormalization(c.Normalization); err != nil {
+			return pipeline, err
+		}
+	}
+	if c.Segmentation != "" {
+		if pipeline.Segmentation != nil {
+			return pipeline, errors.New("The segmentation is set both in the features and in the segmentation field")
+		}
+		if pipeline.Segmentation, err = NewSegmenter(c.Segmentation); err != nil {
+			return pipeline, err
+		}
+	}
+	if c.FeatureNormalization != "" {
+		if pipeline.FeatureNormalization != nil {
+			return pipeline, errors.New("The feature normalization is set both in the features and in the feature normalization field")
+		}
+		pipeline.FeatureNormalization, err = ParseFeatureNormalization(c.FeatureNormalization)
+	}
+	return pipeline, err
+}
+
+// ClassNames returns the class names ordered by label and name, so images are always read in the same order.
+func (c DatasetConfig) ClassNames() []string {
+	names := make([]string, 0, len(c.Classes))
+	for name := range c.Classes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		li, lj := c.Classes[names[i]].Label, c.Classes[names[j]].Label
+		if li != lj {
+			return li < lj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// datasetRecords lists the training or evaluation images from the phase's manifest, or from the class folders.
+func datasetRecords(config DatasetConfig, training bool) ([]ManifestRecord, error) {
+	manifest := config.EvaluationManifest
+	if training {
+		manifest = config.TrainingManifest
+	}
+	if manifest != "" {
+		return LoadManifest(filepath.Join(config.Root, manifest), config.Classes)
+	}
+
+	var records []ManifestRecord
+	for _, name := range config.ClassNames() {
+		class := config.Classes[name]
+		folder := class.EvaluationFolder
+		if training {
+			folder = class.TrainingFolder
+		}
+		if folder == "" {
+			continue
+		}
+		classRecords, err := listImages(filepath.Join(config.Root, folder), class.Label)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading %s images: %w", name, err)
+		}
+		records = append(records, classRecords...)
+	}
+	return records, nil
+}
@@ -0,0 +1,276 @@
+package preprocessing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// De-identification actions
+const (
+	DeidKeep   = "keep"
+	DeidRemove = "remove"
+	// Replace the value with a salted pseudonym, the same for equal values
+	DeidHash = "hash"
+	// Shift a date by the number of days of its patient
+	DeidShift = "shift"
+	// Prefix of the action replacing the value with a constant, e.g. replace=ANONYMOUS
+	DeidReplace = "replace="
+)
+
+// DeidentificationConfig is the profile applied to the metadata of every image before it leaves
+// preprocessing. The salt never leaves the hospital: the dataset configuration is only sent to local actors.
+type DeidentificationConfig struct {
+	Enabled bool   `json:"enabled"`
+	Salt    string `json:"salt"`
+	// Dates are shifted by up to this many days, by the same amount for all images of a patient
+	MaxDateShift int `json:"max_date_shift"`
+	// Actions by metadata field (path, patient_id, acquisition_date, view, sex, age_band) or DICOM tag
+	// such as (0010,0010). They override the default profile.
+	Actions map[string]string `json:"actions"`
+	// Images whose DICOM header reports burned in annotations are refused unless this is set
+	AllowBurnedInAnnotation bool `json:"allow_burned_in_annotation"`
+}
+
+// defaultDeidActions keep what the models need and pseudonymize or shift the rest.
+var defaultDeidActions = map[string]string{
+	"path":             DeidHash,
+	"patient_id":       DeidHash,
+	"acquisition_date": DeidShift,
+	"view":             DeidKeep,
+	"sex":              DeidKeep,
+	"age_band":         DeidKeep,
+}
+
+// defaultDICOMActions follow the basic profile of DICOM PS3.15 for the identifying elements, the
+// other elements are kept, except private ones which are always removed.
+var defaultDICOMActions = map[DICOMTag]string{
+	NewDICOMTag(0x0008, 0x0018): DeidHash,   // SOP Instance UID
+	NewDICOMTag(0x0008, 0x0020): DeidShift,  // Study Date
+	NewDICOMTag(0x0008, 0x0021): DeidShift,  // Series Date
+	NewDICOMTag(0x0008, 0x0022): DeidShift,  // Acquisition Date
+	NewDICOMTag(0x0008, 0x0023): DeidShift,  // Content Date
+	NewDICOMTag(0x0008, 0x0050): DeidRemove, // Accession Number
+	NewDICOMTag(0x0008, 0x0080): DeidRemove, // Institution Name
+	NewDICOMTag(0x0008, 0x0081): DeidRemove, // Institution Address
+	NewDICOMTag(0x0008, 0x0090): DeidRemove, // Referring Physician's Name
+	NewDICOMTag(0x0008, 0x1010): DeidRemove, // Station Name
+	NewDICOMTag(0x0008, 0x1040): DeidRemove, // Institutional Department Name
+	NewDICOMTag(0x0008, 0x1048): DeidRemove, // Physician(s) of Record
+	NewDICOMTag(0x0008, 0x1050): DeidRemove, // Performing Physician's Name
+	NewDICOMTag(0x0008, 0x1070): DeidRemove, // Operators' Name
+	NewDICOMTag(0x0010, 0x0010): DeidRemove, // Patient's Name
+	NewDICOMTag(0x0010, 0x0020): DeidHash,   // Patient ID
+	NewDICOMTag(0x0010, 0x0030): DeidRemove, // Patient's Birth Date
+	NewDICOMTag(0x0010, 0x0032): DeidRemove, // Patient's Birth Time
+	NewDICOMTag(0x0010, 0x1000): DeidRemove, // Other Patient IDs
+	NewDICOMTag(0x0010, 0x1001): DeidRemove, // Other Patient Names
+	NewDICOMTag(0x0010, 0x1040): DeidRemove, // Patient's Address
+	NewDICOMTag(0x0010, 0x2154): DeidRemove, // Patient's Telephone Numbers
+	NewDICOMTag(0x0010, 0x4000): DeidRemove, // Patient Comments
+	NewDICOMTag(0x0020, 0x000D): DeidHash,   // Study Instance UID
+	NewDICOMTag(0x0020, 0x000E): DeidHash,   // Series Instance UID
+	NewDICOMTag(0x0020, 0x0010): DeidRemove, // Study ID
+	NewDICOMTag(0x0020, 0x4000): DeidRemove, // Image Comments
+}
+
+var dicomTagPattern = regexp.MustCompile(`^\(([0-9A-Fa-f]{4}),([0-9A-Fa-f]{4})\)$`)
+
+func init() {
+	// The implicit syntax has no VRs, the decoder only keeps the elements it knows to be text
+	for tag := range defaultDICOMActions {
+		implicitTextTags[tag] = true
+	}
+	implicitTextTags[tagBurnedInAnnotation] = true
+}
+
+// Validate checks the salt and the actions of an enabled profile.
+func (c DeidentificationConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Salt == "" {
+		return errors.New("De-identification needs a salt for the pseudonyms")
+	}
+	if c.MaxDateShift < 0 {
+		return errors.New("The maximum date shift can not be negative")
+	}
+	_, _, err := c.actions()
+	return err
+}
+
+// actions merges the configured actions into the default profile.
+func (c DeidentificationConfig) actions() (map[string]string, map[DICOMTag]string, error) {
+	fields := make(map[string]string)
+	for field, action := range defaultDeidActions {
+		fields[field] = action
+	}
+	tags := make(map[DICOMTag]string)
+	for tag, action := range defaultDICOMActions {
+		tags[tag] = action
+	}
+	for key, action := range c.Actions {
+		switch {
+		case action == DeidKeep, action == DeidRemove, action == DeidHash, action == DeidShift, strings.HasPrefix(action, DeidReplace):
+		default:
+			return nil, nil, fmt.Errorf("Unknown de-identification action %q for %s, expected %s, %s, %s, %s or %s<value>",
+				action, key, DeidKeep, DeidRemove, DeidHash, DeidShift, DeidReplace)
+		}
+		if match := dicomTagPattern.FindStringSubmatch(key); match != nil {
+			var group, element uint16
+			fmt.Sscanf(match[1]+" "+match[2], "%x %x", &group, &element)
+			tags[NewDICOMTag(group, element)] = action
+			continue
+		}
+		if _, ok := defaultDeidActions[key]; !ok {
+			return nil, nil, fmt.Errorf("Unknown de-identification field %q, expected a metadata field or a DICOM tag such as (0010,0010)", key)
+		}
+		if key == "path" && action == DeidKeep {
+			return nil, nil, errors.New("The image path identifies the patient in many archives, it can only be hashed, replaced or removed")
+		}
+		if action == DeidShift && key != "acquisition_date" {
+			return nil, nil, fmt.Errorf("Only dates can be shifted, not %s", key)
+		}
+		fields[key] = action
+	}
+	return fields, tags, nil
+}
+
+// Deidentifier applies a de-identification profile. A nil Deidentifier keeps everything.
+type Deidentifier struct {
+	config DeidentificationConfig
+	fields map[string]string
+	tags   map[DICOMTag]string
+}
+
+// NewDeidentifier returns nil when the profile is disabled.
+func NewDeidentifier(config DeidentificationConfig) (*Deidentifier, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	fields, tags, _ := config.actions()
+	return &Deidentifier{config: config, fields: fields, tags: tags}, nil
+}
+
+// pseudonym is the hex encoded HMAC of the value, 128 bits are plenty to avoid collisions.
+func (d *Deidentifier) pseudonym(value string) string {
+	mac := hmac.New(sha256.New, []byte(d.config.Salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// dateShift is the number of days the dates of a patient move, derived from the salted patient id so
+// the intervals between the images of a patient are kept.
+func (d *Deidentifier) dateShift(patient string) int {
+	if d.config.MaxDateShift == 0 {
+		return 0
+	}
+	mac := hmac.New(sha256.New, []byte(d.config.Salt))
+	mac.Write([]byte("date shift\x00" + patient))
+	span := uint64(2*d.config.MaxDateShift + 1)
+	return int(binary.BigEndian.Uint64(mac.Sum(nil))%span) - d.config.MaxDateShift
+}
+
+// apply performs an action on a value. Dates use the layout.
+func (d *Deidentifier) apply(action, value, patient, layout string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+	switch {
+	case action == DeidKeep:
+		return value, nil
+	case action == DeidRemove:
+		return "", nil
+	case action == DeidHash:
+		return d.pseudonym(value), nil
+	case action == DeidShift:
+		date, err := time.Parse(layout, value)
+		if err != nil {
+			// A date which can not be shifted can not be kept either
+			return "", fmt.Errorf("The date %q can not be shifted", value)
+		}
+		return date.AddDate(0, 0, d.dateShift(patient)).Format(layout), nil
+	}
+	return strings.TrimPrefix(action, DeidReplace), nil
+}
+
+// Path returns the name under which an image may appear in logs and outputs.
+func (d *Deidentifier) Path(path string) string {
+	if d == nil {
+		return path
+	}
+	name, _ := d.apply(d.fields["path"], path, "", "")
+	if name == "" {
+		return "<image>"
+	}
+	return name
+}
+
+// Metadata de-identifies the metadata of an image.
+func (d *Deidentifier) Metadata(meta ImageMetadata) (ImageMetadata, error) {
+	if d == nil {
+		return meta, nil
+	}
+	patient := meta.PatientID
+	var err error
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"path", &meta.Path},
+		{"patient_id", &meta.PatientID},
+		{"acquisition_date", &meta.AcquisitionDate},
+		{"view", &meta.View},
+		{"sex", &meta.Sex},
+		{"age_band", &meta.AgeBand},
+	} {
+		if *field.value, err = d.apply(d.fields[field.name], *field.value, patient, "2006-01-02"); err != nil {
+			return meta, err
+		}
+	}
+	return meta, nil
+}
+
+// DICOM de-identifies the header of a DICOM image. It refuses images with burned in annotations,
+// which no header processing can remove.
+func (d *Deidentifier) DICOM(image *DICOMImage) error {
+	if d == nil {
+		return nil
+	}
+	if strings.EqualFold(image.Tags[tagBurnedInAnnotation], "YES") && !d.config.AllowBurnedInAnnotation {
+		return errors.New("The image has burned in annotations which can not be de-identified")
+	}
+	patient := image.Tags[tagPatientID]
+	for tag, value := range image.Tags {
+		// Private elements may hold anything
+		if tag.Group()%2 == 1 {
+			delete(image.Tags, tag)
+			continue
+		}
+		action, ok := d.tags[tag]
+		if !ok {
+			continue
+		}
+		deidentified, err := d.apply(action, value, patient, "20060102")
+		if err != nil {
+			return err
+		}
+		if deidentified == "" {
+			delete(image.Tags, tag)
+		} else {
+			image.Tags[tag] = deidentified
+		}
+	}
+	var err error
+	image.Metadata, err = d.Metadata(image.Metadata)
+	return err
+}
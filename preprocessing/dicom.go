@@ -0,0 +1,580 @@
+package preprocessing
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DICOMTag identifies a data element by its group and element number.
+type DICOMTag uint32
+
+func NewDICOMTag(group, element uint16) DICOMTag {
+	return DICOMTag(uint32(group)<<16 | uint32(element))
+}
+
+func (t DICOMTag) Group() uint16   { return uint16(t >> 16) }
+func (t DICOMTag) Element() uint16 { return uint16(t) }
+
+func (t DICOMTag) String() string {
+	return fmt.Sprintf("(%04X,%04X)", t.Group(), t.Element())
+}
+
+// Tags read by the DICOM decoder
+var (
+	tagTransferSyntax       = NewDICOMTag(0x0002, 0x0010)
+	tagStudyDate            = NewDICOMTag(0x0008, 0x0020)
+	tagAcquisitionDate      = NewDICOMTag(0x0008, 0x0022)
+	tagContentDate          = NewDICOMTag(0x0008, 0x0023)
+	tagPatientID            = NewDICOMTag(0x0010, 0x0020)
+	tagPatientSex           = NewDICOMTag(0x0010, 0x0040)
+	tagPatientAge           = NewDICOMTag(0x0010, 0x1010)
+	tagViewPosition         = NewDICOMTag(0x0018, 0x5101)
+	tagSamplesPerPixel      = NewDICOMTag(0x0028, 0x0002)
+	tagPhotometric          = NewDICOMTag(0x0028, 0x0004)
+	tagPlanarConfiguration  = NewDICOMTag(0x0028, 0x0006)
+	tagNumberOfFrames       = NewDICOMTag(0x0028, 0x0008)
+	tagRows                 = NewDICOMTag(0x0028, 0x0010)
+	tagColumns              = NewDICOMTag(0x0028, 0x0011)
+	tagBitsAllocated        = NewDICOMTag(0x0028, 0x0100)
+	tagBitsStored           = NewDICOMTag(0x0028, 0x0101)
+	tagPixelRepresentation  = NewDICOMTag(0x0028, 0x0103)
+	tagBurnedInAnnotation   = NewDICOMTag(0x0028, 0x0301)
+	tagWindowCenter         = NewDICOMTag(0x0028, 0x1050)
+	tagWindowWidth          = NewDICOMTag(0x0028, 0x1051)
+	tagRescaleIntercept     = NewDICOMTag(0x0028, 0x1052)
+	tagRescaleSlope         = NewDICOMTag(0x0028, 0x1053)
+	tagPixelData            = NewDICOMTag(0x7FE0, 0x0010)
+	tagItem                 = NewDICOMTag(0xFFFE, 0xE000)
+	tagItemDelimitation     = NewDICOMTag(0xFFFE, 0xE00D)
+	tagSequenceDelimitation = NewDICOMTag(0xFFFE, 0xE0DD)
+)
+
+// Transfer syntaxes, all but JPEG extended are supported
+const (
+	syntaxImplicitLittle = "1.2.840.10008.1.2"
+	syntaxExplicitLittle = "1.2.840.10008.1.2.1"
+	syntaxDeflated       = "1.2.840.10008.1.2.1.99"
+	syntaxExplicitBig    = "1.2.840.10008.1.2.2"
+	syntaxJPEGBaseline   = "1.2.840.10008.1.2.4.50"
+	// 12 bit JPEG, which image/jpeg can not decode
+	syntaxJPEGExtended = "1.2.840.10008.1.2.4.51"
+)
+
+// maxInflatedDICOM limits the size of a deflated data set once inflated, so a small crafted file can
+// not exhaust the memory. It holds far more than a single uncompressed radiograph.
+const maxInflatedDICOM = 256 << 20
+
+// dicomPreamble is the length of the preamble before the DICM prefix of a DICOM file.
+const dicomPreamble = 128
+
+// undefinedLength marks sequences, items and encapsulated pixel data ended by a delimiter.
+const undefinedLength = 0xFFFFFFFF
+
+func init() {
+	image.RegisterFormat("dicom", strings.Repeat("?", dicomPreamble)+"DICM", func(r io.Reader) (image.Image, error) {
+		d, err := DecodeDICOM(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.Image, nil
+	}, func(r io.Reader) (image.Config, error) {
+		d, err := DecodeDICOM(r)
+		if err != nil {
+			return image.Config{}, err
+		}
+		return image.Config{ColorModel: d.Image.ColorModel(), Width: d.Image.Bounds().Dx(), Height: d.Image.Bounds().Dy()}, nil
+	})
+}
+
+// DICOMImage is the first frame of a DICOM file with the metadata of its header.
+type DICOMImage struct {
+	// Grayscale images are 16 bit, after the rescale, the window and the MONOCHROME1 inversion
+	Image image.Image
+	// Metadata holds the patient id, acquisition date, view, sex and age band of the header
+	Metadata ImageMetadata
+	// Tags holds the textual top level elements of the header, sequences are skipped
+	Tags map[DICOMTag]string
+	// TransferSyntax is the UID the data set was encoded with
+	TransferSyntax string
+}
+
+// IsDICOM reports whether the start of a file, at least 132 bytes of it, has the DICM prefix.
+func IsDICOM(header []byte) bool {
+	return len(header) >= dicomPreamble+4 && string(header[dicomPreamble:dicomPreamble+4]) == "DICM"
+}
+
+// DecodeDICOM reads a DICOM Part 10 file in the implicit or explicit VR little endian, explicit VR big
+// endian, deflated or JPEG baseline transfer syntax.
+func DecodeDICOM(r io.Reader) (*DICOMImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d, syntax, err := parseDICOM(data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DICOMImage{Tags: make(map[DICOMTag]string), TransferSyntax: syntax}
+	for tag, value := range d.values {
+		if tag != tagPixelData && d.textual[tag] {
+			result.Tags[tag] = trimDICOMString(value)
+		}
+	}
+	result.Metadata = d.metadata()
+	if result.Image, err = d.image(syntax); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReadDICOMMetadata reads the metadata of a DICOM header without decoding the pixel data.
+func ReadDICOMMetadata(r io.Reader) (ImageMetadata, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+	d, _, err := parseDICOM(data, true)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+	return d.metadata(), nil
+}
+
+// parseDICOM reads the elements of a DICOM file, up to the pixel data when headerOnly is set, and
+// returns them with the transfer syntax.
+func parseDICOM(data []byte, headerOnly bool) (*dicomReader, string, error) {
+	if !IsDICOM(data) {
+		return nil, "", errors.New("The file is not a DICOM file, the DICM prefix is missing")
+	}
+
+	// The file meta information is always explicit VR little endian
+	d := &dicomReader{data: data, pos: dicomPreamble + 4, order: binary.LittleEndian, explicit: true,
+		values: make(map[DICOMTag][]byte), textual: make(map[DICOMTag]bool)}
+	for d.pos < len(d.data) && d.peekGroup() == 0x0002 {
+		if err := d.readElement(); err != nil {
+			return nil, "", err
+		}
+	}
+	syntax := trimDICOMString(d.values[tagTransferSyntax])
+	switch syntax {
+	case syntaxImplicitLittle:
+		d.explicit = false
+	case syntaxExplicitLittle, syntaxJPEGBaseline:
+	case syntaxExplicitBig:
+		d.order = binary.BigEndian
+	case syntaxDeflated:
+		inflated, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(d.data[d.pos:])), maxInflatedDICOM+1))
+		if err != nil {
+			return nil, "", fmt.Errorf("Invalid deflated DICOM data set: %w", err)
+		}
+		if len(inflated) > maxInflatedDICOM {
+			return nil, "", fmt.Errorf("The deflated DICOM data set inflates to more than %d MiB", maxInflatedDICOM>>20)
+		}
+		d.data, d.pos = inflated, 0
+	case syntaxJPEGExtended:
+		return nil, "", fmt.Errorf("Unsupported DICOM transfer syntax %q, 12 bit JPEG extended can not be decoded", syntax)
+	default:
+		return nil, "", fmt.Errorf("Unsupported DICOM transfer syntax %q", syntax)
+	}
+	for d.pos < len(d.data) {
+		if headerOnly && d.peekTag() == tagPixelData {
+			break
+		}
+		if err := d.readElement(); err != nil {
+			return nil, "", err
+		}
+	}
+	return d, syntax, nil
+}
+
+// dicomReader walks the data elements of a data set and keeps the values of the top level ones.
+type dicomReader struct {
+	data     []byte
+	pos      int
+	order    binary.ByteOrder
+	explicit bool
+	values   map[DICOMTag][]byte
+	// textual marks the elements whose value representation is a string
+	textual map[DICOMTag]bool
+	// fragments of encapsulated pixel data, the basic offset table excluded
+	fragments [][]byte
+}
+
+// Value representations with a 4 byte length in the explicit syntaxes
+var longVRs = map[string]bool{"OB": true, "OD": true, "OF": true, "OL": true, "OV": true, "OW": true, "SQ": true, "SV": true, "UC": true, "UN": true, "UR": true, "UT": true, "UV": true}
+
+// Value representations which are strings
+var textVRs = map[string]bool{"AE": true, "AS": true, "CS": true, "DA": true, "DS": true, "DT": true, "IS": true, "LO": true, "LT": true, "PN": true, "SH": true, "ST": true, "TM": true, "UC": true, "UI": true, "UR": true, "UT": true}
+
+// implicitTextTags are the textual elements recognised in the implicit syntax, which has no VRs.
+var implicitTextTags = map[DICOMTag]bool{
+	tagStudyDate: true, tagAcquisitionDate: true, tagContentDate: true, tagPatientID: true, tagPatientSex: true,
+	tagPatientAge: true, tagViewPosition: true, tagPhotometric: true, tagNumberOfFrames: true,
+	tagWindowCenter: true, tagWindowWidth: true, tagRescaleIntercept: true, tagRescaleSlope: true,
+}
+
+var errTruncatedDICOM = errors.New("The DICOM file is truncated")
+
+func (d *dicomReader) need(n int) error {
+	if n < 0 || d.pos+n > len(d.data) {
+		return errTruncatedDICOM
+	}
+	return nil
+}
+
+func (d *dicomReader) peekGroup() uint16 {
+	if d.pos+2 > len(d.data) {
+		return 0
+	}
+	// The meta information is little endian, whatever the data set uses
+	return binary.LittleEndian.Uint16(d.data[d.pos:])
+}
+
+// peekTag returns the tag of the next element in the byte order of the data set, 0 at the end.
+func (d *dicomReader) peekTag() DICOMTag {
+	if d.pos+4 > len(d.data) {
+		return 0
+	}
+	return NewDICOMTag(d.order.Uint16(d.data[d.pos:]), d.order.Uint16(d.data[d.pos+2:]))
+}
+
+func (d *dicomReader) readTag() (DICOMTag, error) {
+	if err := d.need(4); err != nil {
+		return 0, err
+	}
+	tag := NewDICOMTag(d.order.Uint16(d.data[d.pos:]), d.order.Uint16(d.data[d.pos+2:]))
+	d.pos += 4
+	return tag, nil
+}
+
+func (d *dicomReader) readUint32() (uint32, error) {
+	if err := d.need(4); err != nil {
+		return 0, err
+	}
+	v := d.order.Uint32(d.data[d.pos:])
+	d.pos += 4
+	return v, nil
+}
+
+// readHeader reads the tag, the value representation (empty in the implicit syntax) and the value length.
+func (d *dicomReader) readHeader() (DICOMTag, string, uint32, error) {
+	tag, err := d.readTag()
+	if err != nil {
+		return 0, "", 0, err
+	}
+	// Items and delimiters never have a VR
+	if tag.Group() == 0xFFFE || !d.explicit {
+		length, err := d.readUint32()
+		return tag, "", length, err
+	}
+	if err := d.need(4); err != nil {
+		return 0, "", 0, err
+	}
+	vr := string(d.data[d.pos : d.pos+2])
+	if longVRs[vr] {
+		d.pos += 4
+		length, err := d.readUint32()
+		return tag, vr, length, err
+	}
+	length := uint32(d.order.Uint16(d.data[d.pos+2:]))
+	d.pos += 4
+	return tag, vr, length, nil
+}
+
+func (d *dicomReader) readElement() error {
+	tag, vr, length, err := d.readHeader()
+	if err != nil {
+		return err
+	}
+	if length == undefinedLength {
+		if tag == tagPixelData {
+			return d.readFragments()
+		}
+		// A sequence, or an element of unknown VR holding one
+		return d.skipSequence()
+	}
+	if err := d.need(int(length)); err != nil {
+		return err
+	}
+	if vr != "SQ" {
+		d.values[tag] = d.data[d.pos : d.pos+int(length)]
+		d.textual[tag] = textVRs[vr] || (vr == "" && implicitTextTags[tag])
+	}
+	d.pos += int(length)
+	return nil
+}
+
+// skipSequence skips the items of a sequence of undefined length up to its delimiter.
+func (d *dicomReader) skipSequence() error {
+	for {
+		tag, _, length, err := d.readHeader()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tag == tagSequenceDelimitation:
+			return nil
+		case tag == tagItem && length == undefinedLength:
+			if err := d.skipItem(); err != nil {
+				return err
+			}
+		case tag == tagItem:
+			if err := d.need(int(length)); err != nil {
+				return err
+			}
+			d.pos += int(length)
+		default:
+			return fmt.Errorf("Invalid DICOM sequence, unexpected element %s", tag)
+		}
+	}
+}
+
+// skipItem skips the elements of an item of undefined length up to its delimiter.
+func (d *dicomReader) skipItem() error {
+	// Nested elements are not kept
+	values, textual := d.values, d.textual
+	defer func() { d.values, d.textual = values, textual }()
+	d.values, d.textual = make(map[DICOMTag][]byte), make(map[DICOMTag]bool)
+	for {
+		if err := d.need(4); err != nil {
+			return err
+		}
+		if NewDICOMTag(d.order.Uint16(d.data[d.pos:]), d.order.Uint16(d.data[d.pos+2:])) == tagItemDelimitation {
+			_, _, _, err := d.readHeader()
+			return err
+		}
+		if err := d.readElement(); err != nil {
+			return err
+		}
+	}
+}
+
+// readFragments reads encapsulated pixel data: a basic offset table item followed by the fragments.
+func (d *dicomReader) readFragments() error {
+	first := true
+	for {
+		tag, _, length, err := d.readHeader()
+		if err != nil {
+			return err
+		}
+		if tag == tagSequenceDelimitation {
+			return nil
+		}
+		if tag != tagItem || length == undefinedLength {
+			return errors.New("Invalid encapsulated DICOM pixel data")
+		}
+		if err := d.need(int(length)); err != nil {
+			return err
+		}
+		if !first {
+			d.fragments = append(d.fragments, d.data[d.pos:d.pos+int(length)])
+		}
+		first = false
+		d.pos += int(length)
+	}
+}
+
+// uint16 reads an US element, def when it is missing.
+func (d *dicomReader) uint16(tag DICOMTag, def int) int {
+	value := d.values[tag]
+	if len(value) < 2 {
+		return def
+	}
+	return int(d.order.Uint16(value))
+}
+
+// number reads the first value of a DS or IS element.
+func (d *dicomReader) number(tag DICOMTag) (float64, bool) {
+	text := trimDICOMString(d.values[tag])
+	if first, _, ok := strings.Cut(text, `\`); ok {
+		text = first
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	return v, err == nil
+}
+
+func (d *dicomReader) string(tag DICOMTag) string {
+	return trimDICOMString(d.values[tag])
+}
+
+func trimDICOMString(value []byte) string {
+	return strings.TrimRight(strings.TrimSpace(string(value)), "\x00 ")
+}
+
+// metadata maps the header to the fields of a manifest.
+func (d *dicomReader) metadata() ImageMetadata {
+	var meta ImageMetadata
+	meta.PatientID = d.string(tagPatientID)
+	for _, tag := range []DICOMTag{tagAcquisitionDate, tagContentDate, tagStudyDate} {
+		if date := d.string(tag); len(date) == 8 {
+			meta.AcquisitionDate = date[:4] + "-" + date[4:6] + "-" + date[6:]
+			break
+		}
+	}
+	switch view := strings.ToUpper(d.string(tagViewPosition)); view {
+	case "PA", "AP":
+		meta.View = view
+	}
+	switch sex := strings.ToUpper(d.string(tagPatientSex)); sex {
+	case "M", "F":
+		meta.Sex = sex
+	}
+	meta.AgeBand = dicomAgeBand(d.string(tagPatientAge))
+	return meta
+}
+
+// dicomAgeBand turns an age string such as 045Y into its decade, 40-49. Ages in days, weeks or months are 0-9.
+func dicomAgeBand(age string) string {
+	if len(age) != 4 {
+		return ""
+	}
+	n, err := strconv.Atoi(age[:3])
+	if err != nil {
+		return ""
+	}
+	switch age[3] {
+	case 'D', 'W', 'M':
+		return "0-9"
+	case 'Y':
+		decade := n / 10 * 10
+		return fmt.Sprintf("%d-%d", decade, decade+9)
+	}
+	return ""
+}
+
+// image decodes the first frame of the pixel data.
+func (d *dicomReader) image(syntax string) (image.Image, error) {
+	rows, columns := d.uint16(tagRows, 0), d.uint16(tagColumns, 0)
+	if rows == 0 || columns == 0 {
+		return nil, errors.New("The DICOM file has no image size")
+	}
+	samples := d.uint16(tagSamplesPerPixel, 1)
+	photometric := d.string(tagPhotometric)
+
+	if syntax == syntaxJPEGBaseline {
+		if len(d.fragments) == 0 {
+			return nil, errors.New("The DICOM file has no encapsulated pixel data")
+		}
+		// Without a basic offset table all fragments of a single frame file make up the frame
+		frame := d.fragments[0]
+		if frames, ok := d.number(tagNumberOfFrames); !ok || frames <= 1 {
+			frame = bytes.Join(d.fragments, nil)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(frame))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid DICOM JPEG pixel data: %w", err)
+		}
+		gray, ok := img.(*image.Gray)
+		if !ok {
+			return img, nil
+		}
+		bounds := gray.Bounds()
+		values := make([]float64, 0, bounds.Dx()*bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				values = append(values, float64(gray.GrayAt(x, y).Y))
+			}
+		}
+		return d.monochrome(values, bounds.Dx(), bounds.Dy(), photometric), nil
+	}
+
+	pixels, ok := d.values[tagPixelData]
+	if !ok {
+		return nil, errors.New("The DICOM file has no pixel data")
+	}
+	bitsAllocated := d.uint16(tagBitsAllocated, 8)
+	if bitsAllocated != 8 && bitsAllocated != 16 {
+		return nil, fmt.Errorf("Unsupported DICOM bits allocated %d, expected 8 or 16", bitsAllocated)
+	}
+	bytesPerSample := bitsAllocated / 8
+	frameSize := rows * columns * samples * bytesPerSample
+	if len(pixels) < frameSize {
+		return nil, errors.New("The DICOM pixel data is shorter than the image")
+	}
+
+	if samples == 3 {
+		if bitsAllocated != 8 {
+			return nil, errors.New("Unsupported DICOM color image, expected 8 bits per sample")
+		}
+		planar := d.uint16(tagPlanarConfiguration, 0) == 1
+		img := image.NewRGBA(image.Rect(0, 0, columns, rows))
+		for i := 0; i < rows*columns; i++ {
+			var r, g, b uint8
+			if planar {
+				r, g, b = pixels[i], pixels[rows*columns+i], pixels[2*rows*columns+i]
+			} else {
+				r, g, b = pixels[3*i], pixels[3*i+1], pixels[3*i+2]
+			}
+			img.Pix[4*i], img.Pix[4*i+1], img.Pix[4*i+2], img.Pix[4*i+3] = r, g, b, 255
+		}
+		return img, nil
+	}
+	if samples != 1 {
+		return nil, fmt.Errorf("Unsupported DICOM samples per pixel %d", samples)
+	}
+
+	bitsStored := d.uint16(tagBitsStored, bitsAllocated)
+	signed := d.uint16(tagPixelRepresentation, 0) == 1
+	values := make([]float64, rows*columns)
+	for i := range values {
+		var raw uint32
+		if bytesPerSample == 1 {
+			raw = uint32(pixels[i])
+		} else {
+			raw = uint32(d.order.Uint16(pixels[2*i:]))
+		}
+		raw &= 1<<uint(bitsStored) - 1
+		if signed && raw&(1<<uint(bitsStored-1)) != 0 {
+			values[i] = float64(int64(raw) - 1<<uint(bitsStored))
+		} else {
+			values[i] = float64(raw)
+		}
+	}
+	return d.monochrome(values, columns, rows, photometric), nil
+}
+
+// monochrome applies the rescale, the window and the MONOCHROME1 inversion to the stored values and
+// returns a 16 bit image. Without a window the range of the image is used.
+func (d *dicomReader) monochrome(values []float64, columns, rows int, photometric string) *image.Gray16 {
+	slope, ok := d.number(tagRescaleSlope)
+	if !ok || slope == 0 {
+		slope = 1
+	}
+	intercept, _ := d.number(tagRescaleIntercept)
+	low, high := math.Inf(1), math.Inf(-1)
+	for i, v := range values {
+		values[i] = v*slope + intercept
+		low, high = math.Min(low, values[i]), math.Max(high, values[i])
+	}
+	center, hasCenter := d.number(tagWindowCenter)
+	width, hasWidth := d.number(tagWindowWidth)
+	if hasCenter && hasWidth && width >= 1 {
+		// The linear VOI function of the standard
+		low, high = center-0.5-(width-1)/2, center-0.5+(width-1)/2
+	}
+	span := high - low
+	if span <= 0 {
+		span = 1
+	}
+	invert := photometric == "MONOCHROME1"
+	img := image.NewGray16(image.Rect(0, 0, columns, rows))
+	for i, v := range values {
+		scaled := math.Max(0, math.Min((v-low)/span, 1))
+		if invert {
+			scaled = 1 - scaled
+		}
+		img.SetGray16(i%columns, i/columns, color.Gray16{Y: uint16(math.Round(scaled * 0xFFFF))})
+	}
+	return img
+}
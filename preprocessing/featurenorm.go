@@ -0,0 +1,136 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Feature normalization steps, applied to the extracted features in order
+const (
+	// Divide every region by the sum of its magnitudes, so histograms of differently sized regions compare
+	FeatureL1 = "l1"
+	// Divide every region by its Euclidean norm
+	FeatureL2 = "l2"
+	// Take the square root of every magnitude, keeping the sign, so large bins dominate less
+	FeatureSqrt = "sqrt"
+	// l1 followed by sqrt: dot products of the features become the Hellinger kernel of the histograms
+	FeatureHellinger = "hellinger"
+	// Standardize every feature with the mean and standard deviation of the training images. The
+	// statistics are pooled over the hospitals before the model is trained, stored with it and applied
+	// by the network, so evaluation and inference use the same ones. It has to be the last step. The
+	// variance is pooled from the squared deviations from the pooled mean, so raw counts keep their
+	// precision, but with secure aggregation every sum is a fixed-point number which has to stay below
+	// 2^39; normalize raw histograms of large datasets first, e.g. with l1+zscore.
+	FeatureZScore = "zscore"
+)
+
+var featureNormalizations = []string{FeatureL1, FeatureL2, FeatureSqrt, FeatureHellinger, FeatureZScore}
+
+// RegionExtractor is an extractor whose features consist of regions of equal size, e.g. the histogram
+// of every grid cell. The l1 and l2 steps normalize every region on its own; the features of other
+// extractors count as one region.
+type RegionExtractor interface {
+	FeatureExtractor
+	RegionSize() int
+}
+
+// ParseFeatureNormalization reads steps joined with "+", e.g. hellinger+zscore.
+func ParseFeatureNormalization(spec string) ([]string, error) {
+	var steps []string
+	for _, step := range strings.Split(spec, "+") {
+		step = strings.TrimSpace(step)
+		if !isFeatureNormalizationStep(step) {
+			return nil, fmt.Errorf("Unknown feature normalization %q, expected one of %s", step, strings.Join(featureNormalizations, ", "))
+		}
+		steps = append(steps, step)
+	}
+	for _, step := range steps[:len(steps)-1] {
+		if step == FeatureZScore {
+			return nil, errors.New("The zscore has to be the last feature normalization step")
+		}
+	}
+	return steps, nil
+}
+
+// isFeatureNormalization reports whether every step of a description names a feature normalization.
+func isFeatureNormalization(spec string) bool {
+	for _, step := range strings.Split(spec, "+") {
+		if !isFeatureNormalizationStep(strings.TrimSpace(step)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isFeatureNormalizationStep(step string) bool {
+	for _, name := range featureNormalizations {
+		if step == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Standardized reports whether the network standardizes the features with fitted statistics.
+func (p FeaturePipeline) Standardized() bool {
+	steps := p.FeatureNormalization
+	return len(steps) > 0 && steps[len(steps)-1] == FeatureZScore
+}
+
+// normalizeFeatures applies the steps which need no fitted statistics, region by region.
+func (p FeaturePipeline) normalizeFeatures(features []float64) {
+	offset := 0
+	for _, extractor := range p.Extractors {
+		dimension := extractor.Dimension()
+		region := dimension
+		if r, ok := extractor.(RegionExtractor); ok && r.RegionSize() > 0 {
+			region = r.RegionSize()
+		}
+		for start := offset; start < offset+dimension; start += region {
+			values := features[start : start+region]
+			for _, step := range p.FeatureNormalization {
+				switch step {
+				case FeatureL1:
+					normalizeRegion(values, 1)
+				case FeatureL2:
+					normalizeRegion(values, 2)
+				case FeatureSqrt:
+					signedSqrt(values)
+				case FeatureHellinger:
+					normalizeRegion(values, 1)
+					signedSqrt(values)
+				}
+			}
+		}
+		offset += dimension
+	}
+}
+
+// normalizeRegion divides the values by their L1 or L2 norm, leaving regions without any value as they are.
+func normalizeRegion(values []float64, order int) {
+	var norm float64
+	for _, v := range values {
+		if order == 1 {
+			norm += math.Abs(v)
+		} else {
+			norm += v * v
+		}
+	}
+	if order == 2 {
+		norm = math.Sqrt(norm)
+	}
+	if norm == 0 {
+		return
+	}
+	for i := range values {
+		values[i] /= norm
+	}
+}
+
+func signedSqrt(values []float64) {
+	for i, v := range values {
+		values[i] = math.Copysign(math.Sqrt(math.Abs(v)), v)
+	}
+}
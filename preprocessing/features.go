@@ -0,0 +1,399 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FeatureExtractor computes a feature vector of fixed length from the grayscale pixels of an image.
+type FeatureExtractor interface {
+	// Dimension is the length of every feature vector
+	Dimension() int
+	Extract(pixels [][]uint8) ([]float64, error)
+	// String describes the extractor in the form ParsePipeline reads, e.g. hog:cells=6x6,bins=9,block=2
+	String() string
+}
+
+// ExtractorFactory creates an extractor from its options, the part of the description after the colon.
+type ExtractorFactory func(options string) (FeatureExtractor, error)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = make(map[string]ExtractorFactory)
+)
+
+// RegisterExtractor makes an extractor available to pipelines under the name.
+func RegisterExtractor(name string, factory ExtractorFactory) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	if _, exists := extractors[name]; exists {
+		panic("feature extractor registered twice: " + name)
+	}
+	extractors[name] = factory
+}
+
+// Extractors returns the names of the registered extractors.
+func Extractors() []string {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	var names []string
+	for name := range extractors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewExtractor creates an extractor from a description such as lbp:r=1,p=8,grid=5x5,histogram=uniform.
+func NewExtractor(description string) (FeatureExtractor, error) {
+	name, options, _ := strings.Cut(strings.TrimSpace(description), ":")
+	extractorsMu.RLock()
+	factory, ok := extractors[name]
+	extractorsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown feature extractor %q, expected one of %s", name, strings.Join(Extractors(), ", "))
+	}
+	return factory(options)
+}
+
+// FeaturePipeline brings every image to the geometry of Resize, normalizes its intensities, optionally
+// restricts it to its region of interest, then runs its extractors on the resulting pixels,
+// concatenates their features in order and normalizes them.
+type FeaturePipeline struct {
+	// Resize is the default 150x150 stretch when left zero
+	Resize        ResizeParams
+	Normalization []Normalizer
+	// Segmentation is nil when the features cover the whole image
+	Segmentation Segmenter
+	Extractors   []FeatureExtractor
+	// Steps such as l1 or zscore, see FeatureL1
+	FeatureNormalization []string
+}
+
+// pipelineStageSeparator separates the preprocessing steps, the extractors and the feature normalization in a description.
+const pipelineStageSeparator = " | "
+
+// ParsePipeline creates the steps of a description such as resize:size=224x224+equalize+lung | lbp:r=1,p=8+hog | l1+zscore.
+// The optional part before the extractors lists a resize, normalization steps and a segmentation, in this
+// order and each optional, the optional part after them feature normalization steps, all joined with "+".
+func ParsePipeline(spec string) (FeaturePipeline, error) {
+	var pipeline FeaturePipeline
+	stages := strings.Split(spec, strings.TrimSpace(pipelineStageSeparator))
+	if len(stages) > 3 {
+		return pipeline, fmt.Errorf("Invalid feature pipeline %q, expected at most three stages separated by bars", spec)
+	}
+	var preprocessing, extraction, normalization string
+	switch {
+	case len(stages) == 3:
+		preprocessing, extraction, normalization = stages[0], stages[1], stages[2]
+	case len(stages) == 2 && isFeatureNormalization(stages[1]):
+		extraction, normalization = stages[0], stages[1]
+	case len(stages) == 2:
+		preprocessing, extraction = stages[0], stages[1]
+	default:
+		extraction = stages[0]
+	}
+	if strings.TrimSpace(preprocessing) != "" {
+		if err := pipeline.parsePreprocessing(preprocessing); err != nil {
+			return pipeline, err
+		}
+	}
+	if strings.TrimSpace(normalization) != "" {
+		var err error
+		if pipeline.FeatureNormalization, err = ParseFeatureNormalization(normalization); err != nil {
+			return pipeline, err
+		}
+	}
+	for _, description := range strings.Split(extraction, "+") {
+		extractor, err := NewExtractor(description)
+		if err != nil {
+			return pipeline, err
+		}
+		pipeline.Extractors = append(pipeline.Extractors, extractor)
+	}
+	return pipeline, nil
+}
+
+// parsePreprocessing creates the resize, which has to come first, the normalization steps and the
+// segmentation, which has to come last.
+func (p *FeaturePipeline) parsePreprocessing(spec string) error {
+	descriptions := strings.Split(spec, "+")
+	if isResize(descriptions[0]) {
+		var err error
+		if p.Resize, err = ParseResize(descriptions[0]); err != nil {
+			return err
+		}
+		descriptions = descriptions[1:]
+	}
+	if len(descriptions) > 0 && isSegmenter(descriptions[len(descriptions)-1]) {
+		var err error
+		if p.Segmentation, err = NewSegmenter(descriptions[len(descriptions)-1]); err != nil {
+			return err
+		}
+		descriptions = descriptions[:len(descriptions)-1]
+	}
+	for _, description := range descriptions {
+		if isResize(description) {
+			return errors.New("The resize has to come before the other preprocessing steps")
+		}
+		if isSegmenter(description) {
+			return errors.New("The segmentation has to follow the normalization steps")
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	var err error
+	p.Normalization, err = ParseNormalization(strings.Join(descriptions, "+"))
+	return err
+}
+
+func (p FeaturePipeline) Dimension() int {
+	total := 0
+	for _, extractor := range p.Extractors {
+		total += extractor.Dimension()
+	}
+	return total
+}
+
+func (p FeaturePipeline) String() string {
+	descriptions := make([]string, len(p.Extractors))
+	for i, extractor := range p.Extractors {
+		descriptions[i] = extractor.String()
+	}
+	extraction := strings.Join(descriptions, "+")
+
+	// The default geometry is left out, so descriptions of models trained before resizing was configurable still match
+	var preprocessing []string
+	if resize := p.Resize.orDefault(); resize != defaultResize {
+		preprocessing = append(preprocessing, resize.String())
+	}
+	if len(p.Normalization) > 0 {
+		preprocessing = append(preprocessing, normalizationString(p.Normalization))
+	}
+	if p.Segmentation != nil {
+		preprocessing = append(preprocessing, p.Segmentation.String())
+	}
+	description := extraction
+	if len(preprocessing) > 0 {
+		description = strings.Join(preprocessing, "+") + pipelineStageSeparator + description
+	}
+	if len(p.FeatureNormalization) > 0 {
+		description += pipelineStageSeparator + strings.Join(p.FeatureNormalization, "+")
+	}
+	return description
+}
+
+// Extract resizes the decoded image and converts it to grayscale once, normalizes and segments it,
+// concatenates the features of every extractor and normalizes them, except for the fitted zscore.
+func (p FeaturePipeline) Extract(img image.Image) ([]float64, error) {
+	return p.extract(img, nil)
+}
+
+// extract augments the grayscale pixels, unless augment is nil, before they are normalized.
+func (p FeaturePipeline) extract(img image.Image, augment *augmentation) ([]float64, error) {
+	if img == nil {
+		return nil, errors.New("The image passed to the feature pipeline is nil")
+	}
+	if len(p.Extractors) == 0 {
+		return nil, errors.New("The feature pipeline has no extractors")
+	}
+	pixels := GetPixels(p.Resize.orDefault().Apply(img))
+	if augment != nil {
+		pixels = augment.apply(pixels)
+	}
+	for _, step := range p.Normalization {
+		step.Normalize(pixels)
+	}
+	var mask [][]bool
+	if p.Segmentation != nil {
+		pixels, mask = p.Segmentation.Segment(pixels)
+	}
+	features := make([]float64, 0, p.Dimension())
+	for _, extractor := range p.Extractors {
+		var f []float64
+		var err error
+		if masked, ok := extractor.(MaskedExtractor); ok && mask != nil {
+			f, err = masked.ExtractMasked(pixels, mask)
+		} else {
+			f, err = extractor.Extract(pixels)
+		}
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, f...)
+	}
+	p.normalizeFeatures(features)
+	return features, nil
+}
+
+// extractorOptions reads the comma separated key=value options of an extractor description.
+// Every option has to be read, so misspelled options are reported instead of ignored.
+type extractorOptions struct {
+	name   string
+	values map[string]string
+	err    error
+}
+
+func parseOptions(name, options string) *extractorOptions {
+	o := &extractorOptions{name: name, values: make(map[string]string)}
+	if strings.TrimSpace(options) == "" {
+		return o
+	}
+	for _, field := range strings.Split(options, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			o.fail(fmt.Errorf("option %q has no value", field))
+			continue
+		}
+		o.values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return o
+}
+
+func (o *extractorOptions) fail(err error) {
+	if o.err == nil {
+		o.err = fmt.Errorf("Invalid %s options: %w", o.name, err)
+	}
+}
+
+func (o *extractorOptions) take(key string) (string, bool) {
+	value, ok := o.values[key]
+	delete(o.values, key)
+	return value, ok
+}
+
+func (o *extractorOptions) int(key string, def, min int) int {
+	value, ok := o.take(key)
+	if !ok {
+		return def
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < min {
+		o.fail(fmt.Errorf("%s must be an integer of at least %d", key, min))
+		return def
+	}
+	return v
+}
+
+func (o *extractorOptions) float(key string, def, min, max float64) float64 {
+	value, ok := o.take(key)
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < min || v > max {
+		o.fail(fmt.Errorf("%s must be a number between %g and %g", key, min, max))
+		return def
+	}
+	return v
+}
+
+func (o *extractorOptions) bool(key string, def bool) bool {
+	value, ok := o.take(key)
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		o.fail(fmt.Errorf("%s must be true or false", key))
+		return def
+	}
+	return v
+}
+
+// ints reads a list separated by "/", e.g. distances=1/2/4.
+func (o *extractorOptions) ints(key string, def []int, min int) []int {
+	value, ok := o.take(key)
+	if !ok {
+		return def
+	}
+	var list []int
+	for _, item := range strings.Split(value, "/") {
+		v, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || v < min {
+			o.fail(fmt.Errorf("%s must be a list of integers of at least %d separated by /", key, min))
+			return def
+		}
+		list = append(list, v)
+	}
+	return list
+}
+
+// grid reads a size such as 6x6.
+func (o *extractorOptions) grid(key string, defX, defY int) (int, int) {
+	value, ok := o.take(key)
+	if !ok {
+		return defX, defY
+	}
+	xs, ys, _ := strings.Cut(value, "x")
+	x, errX := strconv.Atoi(xs)
+	y, errY := strconv.Atoi(ys)
+	if errX != nil || errY != nil || x < 1 || y < 1 {
+		o.fail(fmt.Errorf("%s must have the form XxY", key))
+		return defX, defY
+	}
+	return x, y
+}
+
+// done reports the first invalid or unknown option.
+func (o *extractorOptions) done() error {
+	for key := range o.values {
+		o.fail(fmt.Errorf("unknown option %q", key))
+	}
+	return o.err
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, "/")
+}
+
+// lbpExtractor is the LBP histogram of one scale.
+type lbpExtractor struct {
+	params    Params
+	dimension int
+}
+
+func init() {
+	RegisterExtractor("lbp", func(options string) (FeatureExtractor, error) {
+		params, err := ParseParams("lbp:" + options)
+		if err != nil {
+			return nil, err
+		}
+		return newLBPExtractor(params)
+	})
+}
+
+func newLBPExtractor(params Params) (*lbpExtractor, error) {
+	dimension, err := params.Dimension()
+	if err != nil {
+		return nil, err
+	}
+	return &lbpExtractor{params: params, dimension: dimension}, nil
+}
+
+func (e *lbpExtractor) Dimension() int { return e.dimension }
+
+func (e *lbpExtractor) String() string { return e.params.String() }
+
+// RegionSize is the number of bins of every cell histogram.
+func (e *lbpExtractor) RegionSize() int {
+	return e.dimension / (int(e.params.GridX) * int(e.params.GridY) * e.params.histograms())
+}
+
+func (e *lbpExtractor) Extract(pixels [][]uint8) ([]float64, error) {
+	return lbpHistogram(pixels, nil, e.params)
+}
+
+func (e *lbpExtractor) ExtractMasked(pixels [][]uint8, mask [][]bool) ([]float64, error) {
+	return lbpHistogram(pixels, mask, e.params)
+}
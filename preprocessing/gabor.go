@@ -0,0 +1,127 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math"
+)
+
+// gaborExtractor filters the image with a bank of Gabor filters, one per orientation and wavelength,
+// and returns the mean and the standard deviation of every filter's response magnitude. The responses
+// are computed every step pixels, which is plenty for these statistics and keeps large kernels affordable.
+type gaborExtractor struct {
+	orientations int
+	wavelengths  []int
+	step         int
+	kernels      []gaborKernel
+}
+
+// gaborKernel holds the even (cosine) and odd (sine) parts of a filter.
+type gaborKernel struct {
+	half      int
+	even, odd []float64
+}
+
+func init() {
+	RegisterExtractor("gabor", func(options string) (FeatureExtractor, error) {
+		o := parseOptions("gabor", options)
+		e := &gaborExtractor{
+			orientations: o.int("orientations", 4, 1),
+			wavelengths:  o.ints("wavelengths", []int{4, 8, 16}, 2),
+			step:         o.int("step", 4, 1),
+		}
+		if err := o.done(); err != nil {
+			return nil, err
+		}
+		for _, wavelength := range e.wavelengths {
+			for k := 0; k < e.orientations; k++ {
+				theta := math.Pi * float64(k) / float64(e.orientations)
+				e.kernels = append(e.kernels, newGaborKernel(float64(wavelength), theta))
+			}
+		}
+		return e, nil
+	})
+}
+
+// newGaborKernel uses a bandwidth of one octave (sigma = 0.56 wavelength) and an aspect ratio of 0.5.
+func newGaborKernel(wavelength, theta float64) gaborKernel {
+	const gamma = 0.5
+	sigma := 0.56 * wavelength
+	half := int(math.Ceil(3 * sigma))
+	size := 2*half + 1
+	k := gaborKernel{half: half, even: make([]float64, size*size), odd: make([]float64, size*size)}
+	var evenSum, weightSum float64
+	for dx := -half; dx <= half; dx++ {
+		for dy := -half; dy <= half; dy++ {
+			xr := float64(dx)*math.Cos(theta) + float64(dy)*math.Sin(theta)
+			yr := -float64(dx)*math.Sin(theta) + float64(dy)*math.Cos(theta)
+			envelope := math.Exp(-(xr*xr + gamma*gamma*yr*yr) / (2 * sigma * sigma))
+			i := (dx+half)*size + dy + half
+			k.even[i] = envelope * math.Cos(2*math.Pi*xr/wavelength)
+			k.odd[i] = envelope * math.Sin(2*math.Pi*xr/wavelength)
+			evenSum += k.even[i]
+			weightSum += envelope
+		}
+	}
+	// Remove the DC response of the even part, so the brightness of a region does not matter
+	for dx := -half; dx <= half; dx++ {
+		for dy := -half; dy <= half; dy++ {
+			xr := float64(dx)*math.Cos(theta) + float64(dy)*math.Sin(theta)
+			yr := -float64(dx)*math.Sin(theta) + float64(dy)*math.Cos(theta)
+			envelope := math.Exp(-(xr*xr + gamma*gamma*yr*yr) / (2 * sigma * sigma))
+			k.even[(dx+half)*size+dy+half] -= envelope * evenSum / weightSum
+		}
+	}
+	return k
+}
+
+func (e *gaborExtractor) Dimension() int {
+	return 2 * len(e.kernels)
+}
+
+func (e *gaborExtractor) String() string {
+	return fmt.Sprintf("gabor:orientations=%d,wavelengths=%s,step=%d", e.orientations, joinInts(e.wavelengths), e.step)
+}
+
+func (e *gaborExtractor) Extract(pixels [][]uint8) ([]float64, error) {
+	return e.ExtractMasked(pixels, nil)
+}
+
+// ExtractMasked only takes the statistics over the responses at pixels inside the mask.
+func (e *gaborExtractor) ExtractMasked(pixels [][]uint8, mask [][]bool) ([]float64, error) {
+	width := len(pixels)
+	height := len(pixels[0])
+
+	features := make([]float64, 0, e.Dimension())
+	for _, k := range e.kernels {
+		size := 2*k.half + 1
+		var sum, sumSquares, n float64
+		for x := 0; x < width; x += e.step {
+			for y := 0; y < height; y += e.step {
+				if !maskContains(mask, x, y) {
+					continue
+				}
+				var re, im float64
+				for dx := -k.half; dx <= k.half; dx++ {
+					column := pixels[clamp(x+dx, width)]
+					row := (dx + k.half) * size
+					for dy := -k.half; dy <= k.half; dy++ {
+						v := float64(column[clamp(y+dy, height)]) / 255
+						re += v * k.even[row+dy+k.half]
+						im += v * k.odd[row+dy+k.half]
+					}
+				}
+				magnitude := math.Hypot(re, im)
+				sum += magnitude
+				sumSquares += magnitude * magnitude
+				n++
+			}
+		}
+		if n == 0 {
+			features = append(features, 0, 0)
+			continue
+		}
+		mean := sum / n
+		features = append(features, mean, math.Sqrt(math.Max(sumSquares/n-mean*mean, 0)))
+	}
+	return features, nil
+}
@@ -0,0 +1,132 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math"
+)
+
+// glcmExtractor computes Haralick statistics of gray-level co-occurrence matrices. The pixels are
+// quantized to levels gray levels, and for every distance a symmetric, normalized matrix is built
+// for the angles 0, 45, 90 and 135 degrees. Each matrix contributes its contrast, dissimilarity,
+// homogeneity, energy, correlation and entropy.
+type glcmExtractor struct {
+	levels    int
+	distances []int
+}
+
+// glcmAngles are the pixel offsets of the four angles.
+var glcmAngles = [][2]int{{1, 0}, {1, -1}, {0, -1}, {-1, -1}}
+
+const glcmStatistics = 6
+
+func init() {
+	RegisterExtractor("glcm", func(options string) (FeatureExtractor, error) {
+		o := parseOptions("glcm", options)
+		e := &glcmExtractor{
+			levels:    o.int("levels", 16, 2),
+			distances: o.ints("distances", []int{1, 2, 4}, 1),
+		}
+		if err := o.done(); err != nil {
+			return nil, err
+		}
+		if e.levels > 256 {
+			return nil, fmt.Errorf("Invalid glcm options: at most 256 levels")
+		}
+		return e, nil
+	})
+}
+
+func (e *glcmExtractor) Dimension() int {
+	return len(e.distances) * len(glcmAngles) * glcmStatistics
+}
+
+func (e *glcmExtractor) String() string {
+	return fmt.Sprintf("glcm:levels=%d,distances=%s", e.levels, joinInts(e.distances))
+}
+
+func (e *glcmExtractor) Extract(pixels [][]uint8) ([]float64, error) {
+	return e.ExtractMasked(pixels, nil)
+}
+
+// ExtractMasked only counts the pairs of pixels which are both inside the mask.
+func (e *glcmExtractor) ExtractMasked(pixels [][]uint8, mask [][]bool) ([]float64, error) {
+	width := len(pixels)
+	height := len(pixels[0])
+
+	quantized := make([][]int, width)
+	for x := range pixels {
+		quantized[x] = make([]int, height)
+		for y, v := range pixels[x] {
+			quantized[x][y] = int(v) * e.levels / 256
+		}
+	}
+
+	features := make([]float64, 0, e.Dimension())
+	matrix := make([]float64, e.levels*e.levels)
+	for _, d := range e.distances {
+		for _, angle := range glcmAngles {
+			dx, dy := angle[0]*d, angle[1]*d
+			for i := range matrix {
+				matrix[i] = 0
+			}
+			var pairs float64
+			for x := 0; x < width; x++ {
+				for y := 0; y < height; y++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || nx >= width || ny < 0 || ny >= height {
+						continue
+					}
+					if !maskContains(mask, x, y) || !maskContains(mask, nx, ny) {
+						continue
+					}
+					a, b := quantized[x][y], quantized[nx][ny]
+					// Symmetric: count the pair in both directions
+					matrix[a*e.levels+b]++
+					matrix[b*e.levels+a]++
+					pairs += 2
+				}
+			}
+			features = append(features, haralick(matrix, e.levels, pairs)...)
+		}
+	}
+	return features, nil
+}
+
+// haralick returns the statistics of a co-occurrence matrix with the given number of pairs.
+func haralick(matrix []float64, levels int, pairs float64) []float64 {
+	stats := make([]float64, glcmStatistics)
+	if pairs == 0 {
+		return stats
+	}
+	var mean, variance float64
+	for i := 0; i < levels; i++ {
+		for j := 0; j < levels; j++ {
+			mean += float64(i) * matrix[i*levels+j] / pairs
+		}
+	}
+	var contrast, dissimilarity, homogeneity, energy, entropy, covariance float64
+	for i := 0; i < levels; i++ {
+		for j := 0; j < levels; j++ {
+			p := matrix[i*levels+j] / pairs
+			if p == 0 {
+				continue
+			}
+			diff := float64(i - j)
+			contrast += p * diff * diff
+			dissimilarity += p * math.Abs(diff)
+			homogeneity += p / (1 + diff*diff)
+			energy += p * p
+			entropy -= p * math.Log2(p)
+			// The matrix is symmetric, so rows and columns have the same mean and variance
+			variance += p * (float64(i) - mean) * (float64(i) - mean)
+			covariance += p * (float64(i) - mean) * (float64(j) - mean)
+		}
+	}
+	// A constant image has no variance, its gray levels are perfectly correlated
+	correlation := 1.0
+	if variance > 0 {
+		correlation = covariance / variance
+	}
+	stats[0], stats[1], stats[2], stats[3], stats[4], stats[5] = contrast, dissimilarity, homogeneity, energy, correlation, entropy
+	return stats
+}
@@ -0,0 +1,153 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// Histogram modes, i.e. how LBP codes are mapped to histogram bins
+const (
+	// One bin per code, 2^P bins
+	HistogramFull = "full"
+	// One bin per uniform pattern (at most two 0/1 transitions) and one for all others, P*(P-1)+3 bins
+	HistogramUniform = "uniform"
+	// One bin per pattern up to rotation
+	HistogramRotationInvariant = "ri"
+	// One bin per number of ones of the uniform patterns and one for all others, P+2 bins
+	HistogramRotationInvariantUniform = "riu2"
+)
+
+// DefaultHistogram is used when Params leave the histogram mode empty.
+const DefaultHistogram = HistogramUniform
+
+// maxTableNeighbors limits the modes which need a lookup table over all 2^P codes.
+const maxTableNeighbors = 16
+
+// binning maps the LBP codes of one neighbor count to histogram bins.
+type binning struct {
+	bins int
+	// Bin of every code, nil when the bin is computed directly
+	table []uint32
+	bin   func(code uint64) int
+}
+
+var binnings sync.Map
+
+// binningFor returns the cached binning of the mode and neighbor count.
+func binningFor(mode string, neighbors uint8) (*binning, error) {
+	if mode == "" {
+		mode = DefaultHistogram
+	}
+	key := fmt.Sprintf("%s/%d", mode, neighbors)
+	if b, ok := binnings.Load(key); ok {
+		return b.(*binning), nil
+	}
+	b, err := newBinning(mode, int(neighbors))
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := binnings.LoadOrStore(key, b)
+	return actual.(*binning), nil
+}
+
+func newBinning(mode string, p int) (*binning, error) {
+	switch mode {
+	case HistogramFull:
+		if p > maxTableNeighbors {
+			return nil, fmt.Errorf("The %s histogram supports at most %d neighbors", mode, maxTableNeighbors)
+		}
+		return &binning{bins: 1 << uint(p), bin: func(code uint64) int { return int(code) }}, nil
+
+	case HistogramUniform:
+		return &binning{bins: p*(p-1) + 3, bin: func(code uint64) int { return uniformBin(code, p) }}, nil
+
+	case HistogramRotationInvariantUniform:
+		return &binning{bins: p + 2, bin: func(code uint64) int {
+			if transitions(code, p) > 2 {
+				return p + 1
+			}
+			return bits.OnesCount64(code)
+		}}, nil
+
+	case HistogramRotationInvariant:
+		if p > maxTableNeighbors {
+			return nil, fmt.Errorf("The %s histogram supports at most %d neighbors", mode, maxTableNeighbors)
+		}
+		// Number the smallest rotations of all codes in increasing order
+		minimal := make([]uint64, 1<<uint(p))
+		seen := make(map[uint64]bool)
+		var distinct []uint64
+		for code := range minimal {
+			minimal[code] = minRotation(uint64(code), p)
+			if !seen[minimal[code]] {
+				seen[minimal[code]] = true
+				distinct = append(distinct, minimal[code])
+			}
+		}
+		sort.Slice(distinct, func(i, j int) bool { return distinct[i] < distinct[j] })
+		index := make(map[uint64]uint32, len(distinct))
+		for i, code := range distinct {
+			index[code] = uint32(i)
+		}
+		table := make([]uint32, len(minimal))
+		for code, m := range minimal {
+			table[code] = index[m]
+		}
+		return &binning{bins: len(distinct), table: table}, nil
+	}
+	return nil, fmt.Errorf("Unknown histogram mode %q, expected %s, %s, %s or %s", mode,
+		HistogramFull, HistogramUniform, HistogramRotationInvariant, HistogramRotationInvariantUniform)
+}
+
+// apply replaces every code with its bin.
+func (b *binning) apply(codes [][]uint64) {
+	for _, row := range codes {
+		for i, code := range row {
+			if b.table != nil {
+				row[i] = uint64(b.table[code])
+			} else {
+				row[i] = uint64(b.bin(code))
+			}
+		}
+	}
+}
+
+// rotateRight rotates the lowest p bits of the code by one.
+func rotateRight(code uint64, p int) uint64 {
+	return (code >> 1) | ((code & 1) << uint(p-1))
+}
+
+// transitions counts the circular 0/1 changes between neighboring bits.
+func transitions(code uint64, p int) int {
+	return bits.OnesCount64(code ^ rotateRight(code, p))
+}
+
+func minRotation(code uint64, p int) uint64 {
+	min := code
+	for i := 1; i < p; i++ {
+		code = rotateRight(code, p)
+		if code < min {
+			min = code
+		}
+	}
+	return min
+}
+
+// uniformBin numbers the uniform patterns: 0 for no ones, 1 for all ones, then every run of
+// k ones (1 <= k < p) at each of the p start positions. Non-uniform codes share the last bin.
+func uniformBin(code uint64, p int) int {
+	ones := bits.OnesCount64(code)
+	switch {
+	case transitions(code, p) > 2:
+		return p*(p-1) + 2
+	case ones == 0:
+		return 0
+	case ones == p:
+		return 1
+	}
+	// The run starts at the one bit whose predecessor (circularly) is zero
+	start := bits.TrailingZeros64(code & ^(code<<1 | code>>uint(p-1)))
+	return 2 + (ones-1)*p + start
+}
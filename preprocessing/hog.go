@@ -0,0 +1,134 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// hogExtractor is a histogram of oriented gradients: the image is divided into a grid of cells, each
+// cell gets a histogram of its unsigned gradient orientations weighted by magnitude, and the cell
+// histograms are normalized (L2-Hys) over every overlapping block of block x block cells.
+type hogExtractor struct {
+	cellsX, cellsY int
+	bins           int
+	block          int
+}
+
+func init() {
+	RegisterExtractor("hog", func(options string) (FeatureExtractor, error) {
+		o := parseOptions("hog", options)
+		e := &hogExtractor{}
+		e.cellsX, e.cellsY = o.grid("cells", 6, 6)
+		e.bins = o.int("bins", 9, 2)
+		e.block = o.int("block", 2, 1)
+		if err := o.done(); err != nil {
+			return nil, err
+		}
+		if e.block > e.cellsX || e.block > e.cellsY {
+			return nil, errors.New("Invalid hog options: the block is larger than the cell grid")
+		}
+		return e, nil
+	})
+}
+
+func (e *hogExtractor) Dimension() int {
+	return (e.cellsX - e.block + 1) * (e.cellsY - e.block + 1) * e.block * e.block * e.bins
+}
+
+// RegionSize is the length of every block descriptor.
+func (e *hogExtractor) RegionSize() int {
+	return e.block * e.block * e.bins
+}
+
+func (e *hogExtractor) String() string {
+	return fmt.Sprintf("hog:cells=%dx%d,bins=%d,block=%d", e.cellsX, e.cellsY, e.bins, e.block)
+}
+
+func (e *hogExtractor) Extract(pixels [][]uint8) ([]float64, error) {
+	return e.ExtractMasked(pixels, nil)
+}
+
+// ExtractMasked leaves the gradients of the pixels outside the mask out of the cell histograms.
+func (e *hogExtractor) ExtractMasked(pixels [][]uint8, mask [][]bool) ([]float64, error) {
+	width := len(pixels)
+	if width < e.cellsX || len(pixels[0]) < e.cellsY {
+		return nil, errors.New("The image is smaller than the HOG cell grid")
+	}
+	height := len(pixels[0])
+
+	// Orientation histogram of every cell
+	cells := make([][]float64, e.cellsX*e.cellsY)
+	for i := range cells {
+		cells[i] = make([]float64, e.bins)
+	}
+	binWidth := math.Pi / float64(e.bins)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if !maskContains(mask, x, y) {
+				continue
+			}
+			// Centered differences, one sided at the border
+			gx := float64(pixels[clamp(x+1, width)][y]) - float64(pixels[clamp(x-1, width)][y])
+			gy := float64(pixels[x][clamp(y+1, height)]) - float64(pixels[x][clamp(y-1, height)])
+			magnitude := math.Hypot(gx, gy)
+			if magnitude == 0 {
+				continue
+			}
+			// Unsigned orientation in [0, pi), split between the two nearest bin centers
+			angle := math.Atan2(gy, gx)
+			if angle < 0 {
+				angle += math.Pi
+			}
+			position := angle/binWidth - 0.5
+			lower := int(math.Floor(position))
+			fraction := position - float64(lower)
+			cell := cells[(x*e.cellsX/width)*e.cellsY+y*e.cellsY/height]
+			cell[(lower+e.bins)%e.bins] += magnitude * (1 - fraction)
+			cell[(lower+1)%e.bins] += magnitude * fraction
+		}
+	}
+
+	features := make([]float64, 0, e.Dimension())
+	block := make([]float64, 0, e.block*e.block*e.bins)
+	for bx := 0; bx+e.block <= e.cellsX; bx++ {
+		for by := 0; by+e.block <= e.cellsY; by++ {
+			block = block[:0]
+			for cx := bx; cx < bx+e.block; cx++ {
+				for cy := by; cy < by+e.block; cy++ {
+					block = append(block, cells[cx*e.cellsY+cy]...)
+				}
+			}
+			// L2 normalize, clip at 0.2 and normalize again
+			normalizeL2(block)
+			for i, v := range block {
+				block[i] = math.Min(v, 0.2)
+			}
+			normalizeL2(block)
+			features = append(features, block...)
+		}
+	}
+	return features, nil
+}
+
+func normalizeL2(values []float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v * v
+	}
+	norm := math.Sqrt(sum + 1e-6)
+	for i := range values {
+		values[i] /= norm
+	}
+}
+
+// clamp keeps an index inside [0, n).
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
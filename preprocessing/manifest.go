@@ -0,0 +1,198 @@
+package preprocessing
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImageMetadata describes where an image comes from. Images read from class folders only have a path.
+type ImageMetadata struct {
+	Path            string `json:"path"`
+	PatientID       string `json:"patient_id"`
+	AcquisitionDate string `json:"acquisition_date"`
+	View            string `json:"view"`
+	Sex             string `json:"sex"`
+	AgeBand         string `json:"age_band"`
+}
+
+// ManifestRecord is one image listed in a dataset manifest.
+type ManifestRecord struct {
+	ImageMetadata
+	Label float64
+}
+
+// manifestColumns are the columns of a CSV manifest. Only path and label are required.
+var manifestColumns = []string{"path", "label", "patient_id", "acquisition_date", "view", "sex", "age_band"}
+
+// manifestEntry is a record as written in a JSON manifest. The label is a number or a class name.
+type manifestEntry struct {
+	ImageMetadata
+	Label json.RawMessage `json:"label"`
+}
+
+// LoadManifest reads a CSV or JSON manifest, chosen by the file extension. Labels may be numbers or
+// names of the configured classes. Relative image paths are resolved against the manifest's directory.
+func LoadManifest(path string, classes map[string]ClassConfig) ([]ManifestRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []ManifestRecord
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		records, err = readCSVManifest(file, classes)
+	case ".json":
+		records, err = readJSONManifest(file, classes)
+	default:
+		return nil, fmt.Errorf("Unknown manifest format %q, expected .csv or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading manifest %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("Manifest %s lists no images", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range records {
+		// Manifests written on Windows use backslashes
+		records[i].Path = filepath.FromSlash(strings.ReplaceAll(records[i].Path, "\\", "/"))
+		if !filepath.IsAbs(records[i].Path) {
+			records[i].Path = filepath.Join(dir, records[i].Path)
+		}
+	}
+	return records, nil
+}
+
+func readCSVManifest(r io.Reader, classes map[string]ClassConfig) ([]ManifestRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range manifestColumns[:2] {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("The manifest has no %s column", required)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records []ManifestRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		label, err := parseLabel(field(row, "label"), classes)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		record := ManifestRecord{
+			ImageMetadata: ImageMetadata{
+				Path:            field(row, "path"),
+				PatientID:       field(row, "patient_id"),
+				AcquisitionDate: field(row, "acquisition_date"),
+				View:            field(row, "view"),
+				Sex:             field(row, "sex"),
+				AgeBand:         field(row, "age_band"),
+			},
+			Label: label,
+		}
+		if err := normalizeMetadata(&record.ImageMetadata); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readJSONManifest(r io.Reader, classes map[string]ClassConfig) ([]ManifestRecord, error) {
+	var entries []manifestEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, err
+	}
+	records := make([]ManifestRecord, len(entries))
+	for i, entry := range entries {
+		var value string
+		if err := json.Unmarshal(entry.Label, &value); err != nil {
+			// Not a string, so it has to be a number
+			value = string(entry.Label)
+		}
+		label, err := parseLabel(value, classes)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+		records[i] = ManifestRecord{ImageMetadata: entry.ImageMetadata, Label: label}
+		if err := normalizeMetadata(&records[i].ImageMetadata); err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+	}
+	return records, nil
+}
+
+// parseLabel accepts a numeric label or the name of a configured class.
+func parseLabel(value string, classes map[string]ClassConfig) (float64, error) {
+	if value == "" {
+		return 0, errors.New("The label is missing")
+	}
+	if class, ok := classes[value]; ok {
+		return class.Label, nil
+	}
+	label, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Unknown label %q", value)
+	}
+	return label, nil
+}
+
+// normalizeMetadata checks the fields and brings them to one spelling: views PA or AP, sex M or F, dates YYYY-MM-DD.
+func normalizeMetadata(meta *ImageMetadata) error {
+	if meta.Path == "" {
+		return errors.New("The image path is missing")
+	}
+	switch strings.ToUpper(meta.View) {
+	case "":
+	case "PA", "AP":
+		meta.View = strings.ToUpper(meta.View)
+	default:
+		return fmt.Errorf("Unknown view %q, expected PA or AP", meta.View)
+	}
+	switch strings.ToUpper(meta.Sex) {
+	case "":
+	case "M", "MALE":
+		meta.Sex = "M"
+	case "F", "FEMALE":
+		meta.Sex = "F"
+	default:
+		return fmt.Errorf("Unknown sex %q, expected M or F", meta.Sex)
+	}
+	if meta.AcquisitionDate != "" {
+		if _, err := time.Parse("2006-01-02", meta.AcquisitionDate); err != nil {
+			return fmt.Errorf("Invalid acquisition date %q, expected YYYY-MM-DD", meta.AcquisitionDate)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package preprocessing
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"strings"
+)
+
+// Scales are LBP parameters computed on every image, whose histograms are concatenated in order.
+type Scales []Params
+
+// UnmarshalJSON accepts a single scale as an object as well as a list of scales.
+// Fields missing from a scale default to the parameters of the first default scale.
+func (s *Scales) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		raw = []json.RawMessage{data}
+	} else if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	scales := make(Scales, len(raw))
+	for i := range raw {
+		scales[i] = lbphParams
+		if err := json.Unmarshal(raw[i], &scales[i]); err != nil {
+			return err
+		}
+	}
+	*s = scales
+	return nil
+}
+
+func (s Scales) Validate() error {
+	if len(s) == 0 {
+		return errors.New("At least one LBP scale is needed")
+	}
+	for _, p := range s {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dimension is the length of the concatenated feature vector.
+func (s Scales) Dimension() (int, error) {
+	total := 0
+	for _, p := range s {
+		d, err := p.Dimension()
+		if err != nil {
+			return 0, err
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// String joins the descriptions of the scales with "+".
+func (s Scales) String() string {
+	descriptions := make([]string, len(s))
+	for i, p := range s {
+		descriptions[i] = p.String()
+	}
+	return strings.Join(descriptions, "+")
+}
+
+// Pipeline turns every scale into an LBP extractor.
+func (s Scales) Pipeline() (FeaturePipeline, error) {
+	if err := s.Validate(); err != nil {
+		return FeaturePipeline{}, err
+	}
+	var pipeline FeaturePipeline
+	for _, p := range s {
+		extractor, err := newLBPExtractor(p)
+		if err != nil {
+			return pipeline, err
+		}
+		pipeline.Extractors = append(pipeline.Extractors, extractor)
+	}
+	return pipeline, nil
+}
+
+// Histograms converts the image to grayscale once and concatenates the LBP histograms of every scale.
+func (s Scales) Histograms(img image.Image) ([]float64, error) {
+	if img == nil {
+		return nil, errors.New("The image passed to the ApplyLBP function is nil")
+	}
+	pixels := GetPixels(img)
+	var hist []float64
+	for _, p := range s {
+		scaleHist, err := lbpHistogram(pixels, nil, p)
+		if err != nil {
+			return nil, err
+		}
+		hist = append(hist, scaleHist...)
+	}
+	return hist, nil
+}
@@ -0,0 +1,284 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Normalizer adjusts the intensities of the grayscale pixels of an image in place, before features
+// are extracted. Images of different devices and sites differ mostly in exposure and contrast.
+type Normalizer interface {
+	Normalize(pixels [][]uint8)
+	// String describes the step in the form ParseNormalization reads, e.g. clahe:tiles=8x8,clip=2
+	String() string
+}
+
+// NormalizerFactory creates a normalization step from its options, the part of the description after the colon.
+type NormalizerFactory func(options string) (Normalizer, error)
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = make(map[string]NormalizerFactory)
+)
+
+// RegisterNormalizer makes a normalization step available to pipelines under the name.
+func RegisterNormalizer(name string, factory NormalizerFactory) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+	if _, exists := normalizers[name]; exists {
+		panic("normalizer registered twice: " + name)
+	}
+	normalizers[name] = factory
+}
+
+// Normalizers returns the names of the registered normalization steps.
+func Normalizers() []string {
+	normalizersMu.RLock()
+	defer normalizersMu.RUnlock()
+	var names []string
+	for name := range normalizers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewNormalizer creates a normalization step from a description such as gamma:gamma=0.8.
+func NewNormalizer(description string) (Normalizer, error) {
+	name, options, _ := strings.Cut(strings.TrimSpace(description), ":")
+	normalizersMu.RLock()
+	factory, ok := normalizers[name]
+	normalizersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown normalization %q, expected one of %s", name, strings.Join(Normalizers(), ", "))
+	}
+	return factory(options)
+}
+
+// ParseNormalization creates the steps of a description such as window:low=20,high=235+clahe, applied in order.
+func ParseNormalization(spec string) ([]Normalizer, error) {
+	var steps []Normalizer
+	for _, description := range strings.Split(spec, "+") {
+		step, err := NewNormalizer(description)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func normalizationString(steps []Normalizer) string {
+	descriptions := make([]string, len(steps))
+	for i, step := range steps {
+		descriptions[i] = step.String()
+	}
+	return strings.Join(descriptions, "+")
+}
+
+func init() {
+	RegisterNormalizer("equalize", func(options string) (Normalizer, error) {
+		if err := parseOptions("equalize", options).done(); err != nil {
+			return nil, err
+		}
+		return equalizer{}, nil
+	})
+	RegisterNormalizer("clahe", func(options string) (Normalizer, error) {
+		o := parseOptions("clahe", options)
+		n := clahe{}
+		n.tilesX, n.tilesY = o.grid("tiles", 8, 8)
+		n.clip = o.float("clip", 2, 1, 256)
+		if err := o.done(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	})
+	RegisterNormalizer("gamma", func(options string) (Normalizer, error) {
+		o := parseOptions("gamma", options)
+		g := o.float("gamma", 1, 0.01, 100)
+		if err := o.done(); err != nil {
+			return nil, err
+		}
+		return newGamma(g), nil
+	})
+	RegisterNormalizer("window", func(options string) (Normalizer, error) {
+		o := parseOptions("window", options)
+		low := o.int("low", 0, 0)
+		high := o.int("high", 255, 0)
+		if err := o.done(); err != nil {
+			return nil, err
+		}
+		if high > 255 || low >= high {
+			return nil, errors.New("Invalid window options: low must be below high and both within 0 and 255")
+		}
+		return newWindow(uint8(low), uint8(high)), nil
+	})
+}
+
+// lookupTable maps every intensity to a new one.
+type lookupTable [256]uint8
+
+func (t *lookupTable) apply(pixels [][]uint8) {
+	for _, column := range pixels {
+		for y, v := range column {
+			column[y] = t[v]
+		}
+	}
+}
+
+// equalizer spreads the intensities of the whole image evenly over 0..255.
+type equalizer struct{}
+
+func (equalizer) String() string { return "equalize" }
+
+func (equalizer) Normalize(pixels [][]uint8) {
+	var hist [256]int
+	total := 0
+	for _, column := range pixels {
+		for _, v := range column {
+			hist[v]++
+		}
+		total += len(column)
+	}
+	// The darkest intensity present maps to 0
+	var cdf, cdfMin int
+	for _, count := range hist {
+		if count > 0 {
+			cdfMin = count
+			break
+		}
+	}
+	if total == cdfMin {
+		return
+	}
+	var table lookupTable
+	for v, count := range hist {
+		cdf += count
+		if cdf > cdfMin {
+			table[v] = uint8(math.Round(255 * float64(cdf-cdfMin) / float64(total-cdfMin)))
+		}
+	}
+	table.apply(pixels)
+}
+
+// clahe is contrast limited adaptive histogram equalization: every tile of a tilesX x tilesY grid is
+// equalized with a histogram clipped at clip times the mean bin count, the excess being spread over all
+// bins, and every pixel interpolates bilinearly between the mappings of the four nearest tile centers.
+type clahe struct {
+	tilesX, tilesY int
+	clip           float64
+}
+
+func (n clahe) String() string {
+	return fmt.Sprintf("clahe:tiles=%dx%d,clip=%g", n.tilesX, n.tilesY, n.clip)
+}
+
+func (n clahe) Normalize(pixels [][]uint8) {
+	width := len(pixels)
+	if width == 0 {
+		return
+	}
+	height := len(pixels[0])
+	// Images smaller than the grid get one tile per pixel
+	tilesX, tilesY := n.tilesX, n.tilesY
+	if tilesX > width {
+		tilesX = width
+	}
+	if tilesY > height {
+		tilesY = height
+	}
+
+	tables := make([]lookupTable, tilesX*tilesY)
+	for tx := 0; tx < tilesX; tx++ {
+		for ty := 0; ty < tilesY; ty++ {
+			x0, x1 := tx*width/tilesX, (tx+1)*width/tilesX
+			y0, y1 := ty*height/tilesY, (ty+1)*height/tilesY
+			tables[tx*tilesY+ty] = n.tileTable(pixels, x0, x1, y0, y1)
+		}
+	}
+
+	// Position of a pixel in tile coordinates, where tile centers are at integers
+	position := func(i, size, tiles int) (int, int, float64) {
+		p := (float64(i)+0.5)*float64(tiles)/float64(size) - 0.5
+		lower := int(math.Floor(p))
+		fraction := p - float64(lower)
+		return clamp(lower, tiles), clamp(lower+1, tiles), fraction
+	}
+	for x := 0; x < width; x++ {
+		x0, x1, fx := position(x, width, tilesX)
+		for y := 0; y < height; y++ {
+			y0, y1, fy := position(y, height, tilesY)
+			v := pixels[x][y]
+			top := (1-fx)*float64(tables[x0*tilesY+y0][v]) + fx*float64(tables[x1*tilesY+y0][v])
+			bottom := (1-fx)*float64(tables[x0*tilesY+y1][v]) + fx*float64(tables[x1*tilesY+y1][v])
+			pixels[x][y] = uint8(math.Round((1-fy)*top + fy*bottom))
+		}
+	}
+}
+
+// tileTable equalizes the clipped histogram of the pixels in [x0, x1) x [y0, y1).
+func (n clahe) tileTable(pixels [][]uint8, x0, x1, y0, y1 int) lookupTable {
+	var hist [256]float64
+	for x := x0; x < x1; x++ {
+		for _, v := range pixels[x][y0:y1] {
+			hist[v]++
+		}
+	}
+	area := float64((x1 - x0) * (y1 - y0))
+	limit := math.Max(n.clip*area/256, 1)
+	var excess float64
+	for v := range hist {
+		if hist[v] > limit {
+			excess += hist[v] - limit
+			hist[v] = limit
+		}
+	}
+	var table lookupTable
+	var cdf float64
+	for v := range hist {
+		cdf += hist[v] + excess/256
+		table[v] = uint8(math.Round(math.Min(255*cdf/area, 255)))
+	}
+	return table
+}
+
+// gamma maps v to 255 * (v/255)^gamma: a gamma below 1 brightens dark regions, above 1 darkens them.
+type gamma struct {
+	gamma float64
+	table lookupTable
+}
+
+func newGamma(g float64) *gamma {
+	n := &gamma{gamma: g}
+	for v := range n.table {
+		n.table[v] = uint8(math.Round(255 * math.Pow(float64(v)/255, g)))
+	}
+	return n
+}
+
+func (n *gamma) String() string { return fmt.Sprintf("gamma:gamma=%g", n.gamma) }
+
+func (n *gamma) Normalize(pixels [][]uint8) { n.table.apply(pixels) }
+
+// window stretches the intensities between low and high to 0..255 and saturates the others.
+type window struct {
+	low, high uint8
+	table     lookupTable
+}
+
+func newWindow(low, high uint8) *window {
+	n := &window{low: low, high: high}
+	for v := range n.table {
+		scaled := 255 * (float64(v) - float64(low)) / float64(high-low)
+		n.table[v] = uint8(math.Round(math.Max(0, math.Min(scaled, 255))))
+	}
+	return n
+}
+
+func (n *window) String() string { return fmt.Sprintf("window:low=%d,high=%d", n.low, n.high) }
+
+func (n *window) Normalize(pixels [][]uint8) { n.table.apply(pixels) }
@@ -0,0 +1,143 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Operators, i.e. how the neighbors on the circle are turned into codes
+const (
+	// Bit p is set when neighbor p is at least as bright as the center
+	OperatorLBP = "lbp"
+	// Local ternary patterns: neighbors brighter than center+tolerance set a bit of the upper code,
+	// neighbors darker than center-tolerance a bit of the lower code. Both get their own histograms.
+	OperatorLTP = "ltp"
+	// Center-symmetric LBP: bit i is set when neighbor i is brighter than the opposite neighbor
+	// by more than the tolerance, giving P/2 bits
+	OperatorCSLBP = "cslbp"
+)
+
+// operator returns the operator, OperatorLBP when it is empty.
+func (p Params) operator() string {
+	if p.Operator == "" {
+		return OperatorLBP
+	}
+	return p.Operator
+}
+
+// codeBits is the number of bits of every code the operator produces.
+func (p Params) codeBits() uint8 {
+	if p.operator() == OperatorCSLBP {
+		return p.Neighbors / 2
+	}
+	return p.Neighbors
+}
+
+// histogramMode returns the histogram mode, with the operator's default when it is empty.
+func (p Params) histogramMode() string {
+	if p.Histogram != "" {
+		return p.Histogram
+	}
+	if p.operator() == OperatorCSLBP {
+		// The few center-symmetric codes need no compaction
+		return HistogramFull
+	}
+	return DefaultHistogram
+}
+
+// histograms is the number of histograms every grid cell gets.
+func (p Params) histograms() int {
+	if p.operator() == OperatorLTP {
+		return 2
+	}
+	return 1
+}
+
+func (p Params) validateOperator() error {
+	switch p.operator() {
+	case OperatorLBP:
+		if p.Tolerance != 0 {
+			return errors.New("The lbp operator has no tolerance")
+		}
+	case OperatorLTP:
+	case OperatorCSLBP:
+		if p.Neighbors%2 != 0 {
+			return errors.New("The cslbp operator needs an even number of neighbors")
+		}
+		if p.histogramMode() != HistogramFull {
+			return errors.New("The cslbp operator only supports the full histogram")
+		}
+	default:
+		return fmt.Errorf("Unknown LBP operator %q, expected %s, %s or %s", p.Operator, OperatorLBP, OperatorLTP, OperatorCSLBP)
+	}
+	return nil
+}
+
+// operatorCodes applies the operator to every pixel with a complete circle around it. LTP returns the
+// upper and the lower codes, the other operators a single code per pixel.
+func operatorCodes(pixels [][]uint8, params Params) ([][][]uint64, error) {
+	// Get the image size (width and height)
+	width := len(pixels)
+	height := 0
+	if width > 0 {
+		height = len(pixels[0])
+	}
+	r := int(params.Radius)
+	if width <= 2*r || height <= 2*r {
+		return nil, errors.New("The image passed to the ApplyLBP function is smaller than the LBP circle")
+	}
+
+	samples := circleSamples(float64(params.Radius), int(params.Neighbors))
+	values := make([]float64, len(samples))
+	tolerance := float64(params.Tolerance)
+	operator := params.operator()
+
+	codes := make([][][]uint64, params.histograms())
+	for i := range codes {
+		codes[i] = make([][]uint64, width-2*r)
+	}
+	half := len(samples) / 2
+	for x := r; x < width-r; x++ {
+		for i := range codes {
+			codes[i][x-r] = make([]uint64, height-2*r)
+		}
+		for y := r; y < height-r; y++ {
+			center := float64(pixels[x][y])
+			for p, s := range samples {
+				values[p] = s.value(pixels, x, y)
+			}
+
+			switch operator {
+			case OperatorLTP:
+				var upper, lower uint64
+				for p, v := range values {
+					if v >= center+tolerance {
+						upper |= 1 << uint(p)
+					} else if v <= center-tolerance {
+						lower |= 1 << uint(p)
+					}
+				}
+				codes[0][x-r][y-r], codes[1][x-r][y-r] = upper, lower
+
+			case OperatorCSLBP:
+				var code uint64
+				for p := 0; p < half; p++ {
+					if values[p]-values[p+half] > tolerance {
+						code |= 1 << uint(p)
+					}
+				}
+				codes[0][x-r][y-r] = code
+
+			default:
+				var code uint64
+				for p, v := range values {
+					if v >= center {
+						code |= 1 << uint(p)
+					}
+				}
+				codes[0][x-r][y-r] = code
+			}
+		}
+	}
+	return codes, nil
+}
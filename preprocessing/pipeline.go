@@ -0,0 +1,126 @@
+package preprocessing
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// extraction holds what every chunk of a stream extracts its features with, built once per stream.
+type extraction struct {
+	features  FeaturePipeline
+	deid      *Deidentifier
+	cache     *featureCache
+	workers   int
+	chunkSize int
+}
+
+func newExtraction(config DatasetConfig) (*extraction, error) {
+	features, err := config.FeaturePipeline()
+	if err != nil {
+		return nil, err
+	}
+	deid, err := NewDeidentifier(config.Deidentification)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := newFeatureCache(config.Cache, features, config.Deidentification)
+	if err != nil {
+		return nil, err
+	}
+	return &extraction{features: features, deid: deid, cache: cache, workers: config.Workers, chunkSize: config.ChunkSize}, nil
+}
+
+// extractFeatures runs decode → resize → grayscale → normalization → extractors for every image on a
+// bounded pool of workers. Each histogram is stored at the index of its image, so the output order does not
+// depend on the scheduling. On failure the error of the first failing image is returned.
+// Images with an entry in augmentations, which may be nil, are augmented first.
+func extractFeatures(records []ManifestRecord, augmentations []*augmentation, ex *extraction) (*Data, error) {
+	if len(records) == 0 {
+		return nil, errors.New("The dataset has no images")
+	}
+	workers := ex.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(records) {
+		workers = len(records)
+	}
+
+	data := &Data{
+		Labels:     make([]float64, len(records)),
+		Histograms: make([][]float64, len(records)),
+		Metadata:   make([]ImageMetadata, len(records)),
+	}
+	errs := make([]error, len(records))
+	var failed atomic.Bool
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var augment *augmentation
+				if augmentations != nil {
+					augment = augmentations[i]
+				}
+				var err error
+				data.Histograms[i], data.Metadata[i], err = imageFeatures(records[i].ImageMetadata, augment, ex.features, ex.deid, ex.cache)
+				if err != nil {
+					errs[i] = err
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i, record := range records {
+		// Stop handing out images once one failed
+		if failed.Load() {
+			break
+		}
+		data.Labels[i] = record.Label
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// imageFeatures extracts the features of an image, or takes them from the cache, which may be nil,
+// when they were extracted before with the same configuration.
+func imageFeatures(record ImageMetadata, augment *augmentation, features FeaturePipeline, deid *Deidentifier,
+	cache *featureCache) ([]float64, ImageMetadata, error) {
+	content, err := readImage(record.Path, deid)
+	if err != nil {
+		return nil, ImageMetadata{Path: deid.Path(record.Path)}, err
+	}
+	var key string
+	if cache != nil && augment == nil {
+		key = cache.key(content, record)
+		if entry, ok := cache.load(key); ok {
+			return entry.Features, entry.Metadata, nil
+		}
+	}
+	img, metadata, err := decodeImage(content, record, deid)
+	if err != nil {
+		return nil, metadata, err
+	}
+	histogram, err := features.extract(img, augment)
+	if err != nil {
+		return nil, metadata, err
+	}
+	if key != "" {
+		// A cache which can not be written, e.g. on a full disk, only costs the reuse
+		cache.store(key, featureCacheEntry{Features: histogram, Metadata: metadata})
+	}
+	return histogram, metadata, nil
+}
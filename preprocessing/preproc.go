@@ -1,24 +1,33 @@
 package preprocessing
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
+	"io/fs"
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
-
-	"github.com/nfnt/resize"
+	"strings"
 )
 
 type Params struct {
-	Radius    uint8
-	Neighbors uint8
-	GridX     uint8
-	GridY     uint8
+	Radius    uint8 `json:"radius"`
+	Neighbors uint8 `json:"neighbors"`
+	GridX     uint8 `json:"grid_x"`
+	GridY     uint8 `json:"grid_y"`
+	// How codes are binned: full, uniform, ri or riu2, empty for the operator's default
+	Histogram string `json:"histogram"`
+	// lbp, ltp or cslbp, empty for lbp
+	Operator string `json:"operator"`
+	// Intensity difference the ltp and cslbp operators ignore
+	Tolerance uint8 `json:"tolerance"`
 }
 
 var lbphParams = Params{
@@ -26,57 +35,246 @@ var lbphParams = Params{
 	Neighbors: 8,
 	GridX:     5,
 	GridY:     5,
+	// The operator's default, uniform for LBP
+	Histogram: "",
+}
+
+// Validate checks that the LBP circle and the grid are usable.
+func (p Params) Validate() error {
+	if p.Radius == 0 {
+		return errors.New("The LBP radius must be at least 1")
+	}
+	if p.Neighbors == 0 || p.Neighbors > maxNeighbors {
+		return fmt.Errorf("The LBP needs between 1 and %d neighbors", maxNeighbors)
+	}
+	if p.GridX == 0 || p.GridY == 0 {
+		return errors.New("The LBP grid must have at least one cell in each direction")
+	}
+	if err := p.validateOperator(); err != nil {
+		return err
+	}
+	_, err := binningFor(p.histogramMode(), p.codeBits())
+	return err
+}
+
+// Dimension is the length of the feature vector: the bins of every histogram of every grid cell.
+func (p Params) Dimension() (int, error) {
+	b, err := binningFor(p.histogramMode(), p.codeBits())
+	if err != nil {
+		return 0, err
+	}
+	return int(p.GridX) * int(p.GridY) * b.bins * p.histograms(), nil
+}
+
+// String describes the parameters in the form ParseParams reads, e.g. lbp:r=1,p=8,grid=5x5,histogram=uniform.
+// It is recorded in the model metadata, so hospitals can check they extract the features the model expects.
+// The operator is only written when it is not plain LBP.
+func (p Params) String() string {
+	s := fmt.Sprintf("lbp:r=%d,p=%d,grid=%dx%d,histogram=%s", p.Radius, p.Neighbors, p.GridX, p.GridY, p.histogramMode())
+	if p.operator() != OperatorLBP {
+		s += fmt.Sprintf(",operator=%s,t=%d", p.operator(), p.Tolerance)
+	}
+	return s
+}
+
+// ParseParams reads parameters in the form String writes.
+func ParseParams(s string) (Params, error) {
+	// Fields missing from the description keep the default parameters
+	p := lbphParams
+	fields, ok := strings.CutPrefix(strings.TrimSpace(s), "lbp:")
+	if !ok {
+		return p, fmt.Errorf("Invalid LBP parameters %q, expected lbp:r=...,p=...,grid=XxY,histogram=...", s)
+	}
+	for _, field := range strings.Split(fields, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(field, "=")
+		var err error
+		switch strings.TrimSpace(key) {
+		case "r":
+			p.Radius, err = parseUint8(value)
+		case "p":
+			p.Neighbors, err = parseUint8(value)
+		case "grid":
+			x, y, _ := strings.Cut(value, "x")
+			if p.GridX, err = parseUint8(x); err == nil {
+				p.GridY, err = parseUint8(y)
+			}
+		case "histogram":
+			p.Histogram = strings.TrimSpace(value)
+		case "operator":
+			p.Operator = strings.TrimSpace(value)
+		case "t":
+			p.Tolerance, err = parseUint8(value)
+		default:
+			err = fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return p, fmt.Errorf("Invalid LBP parameters %q: %w", s, err)
+		}
+	}
+	return p, p.Validate()
+}
+
+func parseUint8(value string) (uint8, error) {
+	v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 8)
+	return uint8(v), err
 }
 
 type Data struct {
 	Labels     []float64
 	Histograms [][]float64
+	// Where every sample comes from, nil when unknown
+	Metadata []ImageMetadata
+	// Version of the augmented training split the samples belong to, see AugmentationConfig.Epochs
+	Epoch int
 }
 
-func readImages(imgLocation string, label float64) ([]image.Image, []float64, error) {
-	var imgPaths []string
+// listImages lists every file below the folder as an image with the label.
+func listImages(imgLocation string, label float64) ([]ManifestRecord, error) {
+	var records []ManifestRecord
 	err := filepath.Walk(imgLocation, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if !info.IsDir() {
-			imgPaths = append(imgPaths, path)
+			records = append(records, ManifestRecord{ImageMetadata: ImageMetadata{Path: path}, Label: label})
 		}
 		return nil
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	var images []image.Image
-	var labels []float64
-	for _, imgPath := range imgPaths {
-		file, err := os.Open(imgPath)
-		if err != nil {
-			return nil, nil, err
+	return records, nil
+}
+
+// readPatientIDs fills the patient ids the records lack from the headers of DICOM images, so the
+// images of a patient can be grouped before the split. Unreadable headers are left to fail when the
+// image is preprocessed. It returns the number of records which still have no patient id.
+func readPatientIDs(records []ManifestRecord) int {
+	missing := 0
+	for i := range records {
+		if records[i].PatientID != "" {
+			continue
 		}
-		defer file.Close()
+		if id := dicomPatientID(records[i].Path); id != "" {
+			records[i].PatientID = id
+		} else {
+			missing++
+		}
+	}
+	return missing
+}
 
-		img, _, err := image.Decode(file)
+// dicomPatientID returns the patient id in the header of a DICOM file, empty for other files.
+func dicomPatientID(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	header := make([]byte, dicomPreamble+4)
+	if _, err := io.ReadFull(file, header); err != nil || !IsDICOM(header) {
+		return ""
+	}
+	meta, err := ReadDICOMMetadata(io.MultiReader(bytes.NewReader(header), file))
+	if err != nil {
+		return ""
+	}
+	return meta.PatientID
+}
+
+// readImage reads the file of an image. Errors name the image as de-identified.
+func readImage(path string, deid *Deidentifier) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, imageError(deid.Path(path), err)
+	}
+	return content, nil
+}
+
+// decodeImage decodes the file content of a record, the feature pipeline resizes it. The metadata of a
+// DICOM header fills the fields the record leaves empty, then the metadata is de-identified. Errors name
+// the image as de-identified.
+func decodeImage(content []byte, record ImageMetadata, deid *Deidentifier) (image.Image, ImageMetadata, error) {
+	name := deid.Path(record.Path)
+	fail := func(err error) (image.Image, ImageMetadata, error) {
+		return nil, ImageMetadata{Path: name}, imageError(name, err)
+	}
+
+	var img image.Image
+	var err error
+	if IsDICOM(content) {
+		dicom, err := DecodeDICOM(bytes.NewReader(content))
 		if err != nil {
-			return nil, nil, err
+			return fail(err)
+		}
+		for _, field := range []struct{ value, fallback *string }{
+			{&record.PatientID, &dicom.Metadata.PatientID},
+			{&record.AcquisitionDate, &dicom.Metadata.AcquisitionDate},
+			{&record.View, &dicom.Metadata.View},
+			{&record.Sex, &dicom.Metadata.Sex},
+			{&record.AgeBand, &dicom.Metadata.AgeBand},
+		} {
+			if *field.value == "" {
+				*field.value = *field.fallback
+			}
+		}
+		if err := deid.DICOM(dicom); err != nil {
+			return fail(err)
 		}
-		resized_image := resize.Resize(150, 150, img, resize.Lanczos3)
+		img = dicom.Image
+	} else if img, _, err = image.Decode(bytes.NewReader(content)); err != nil {
+		return fail(err)
+	}
 
-		images = append(images, resized_image)
-		labels = append(labels, label)
+	if record, err = deid.Metadata(record); err != nil {
+		return fail(err)
 	}
+	return img, record, nil
+}
 
-	return images, labels, nil
+// imageError prefixes the error with the name of the image.
+func imageError(name string, err error) error {
+	// The operating system errors repeat the path
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("%s: %w", name, err)
 }
 
-// getBinaryString function used to get a binary value as a string based on a threshold.
-// Return "1" if the value is equal or higher than the threshold or "0" otherwise.
-func getBinaryString(value, threshold int) string {
-	if value >= threshold {
-		return "1"
-	} else {
-		return "0"
+// lbpHistogram applies the LBP operator to the grayscale pixels and returns the concatenated histograms of its grid.
+// LTP has an upper and a lower histogram for the whole grid. Pixels outside the mask, unless it is nil, are not counted.
+func lbpHistogram(pixels [][]uint8, mask [][]bool, params Params) ([]float64, error) {
+	b, err := binningFor(params.histogramMode(), params.codeBits())
+	if err != nil {
+		return nil, err
 	}
+	codes, err := operatorCodes(pixels, params)
+	if err != nil {
+		return nil, err
+	}
+	// The codes start at the radius, so the mask of the code at (x, y) starts there as well
+	var codeMask [][]bool
+	if mask != nil {
+		r := int(params.Radius)
+		codeMask = make([][]bool, len(mask)-2*r)
+		for x := range codeMask {
+			codeMask[x] = mask[x+r][r : len(mask[x+r])-r]
+		}
+	}
+	var hist []float64
+	for _, c := range codes {
+		b.apply(c)
+		h, err := CalculateMaskedHistograms(c, codeMask, params.GridX, params.GridY, b.bins)
+		if err != nil {
+			return nil, err
+		}
+		hist = append(hist, h...)
+	}
+	return hist, nil
 }
 
 func LBPHistograms(images []image.Image, labels []float64) (*Data, error) {
@@ -94,14 +292,7 @@ func LBPHistograms(images []image.Image, labels []float64) (*Data, error) {
 	// Calculates the LBP operation and gets the histograms for each image.
 	var histograms [][]float64
 	for index := 0; index < len(images); index++ {
-		// Calculate the LBP operation for the current image.
-		pixels, err := CalculateLBP(images[index], lbphParams.Radius, lbphParams.Neighbors)
-		if err != nil {
-			return nil, err
-		}
-
-		// Get the histogram from the current image.
-		hist, err := CalculateHistograms(pixels, lbphParams.GridX, lbphParams.GridY, 64)
+		hist, err := Scales{lbphParams}.Histograms(images[index])
 		if err != nil {
 			return nil, err
 		}
@@ -133,6 +324,7 @@ func GetImageSize(img image.Image) (int, int) {
 }
 
 // GetPixels function returns a 'matrix' ([][]uint8) containing all pixels from the image passed by parameter.
+// The pixel buffers of the common image types are read directly, other images pixel by pixel.
 func GetPixels(img image.Image) [][]uint8 {
 	var pixels [][]uint8
 
@@ -143,31 +335,61 @@ func GetPixels(img image.Image) [][]uint8 {
 
 	// Get the image size
 	width, height := GetImageSize(img)
+	bounds := img.Bounds()
+
+	pixels = make([][]uint8, width)
+	for x := range pixels {
+		pixels[x] = make([]uint8, height)
+	}
 
 	// For each pixel in the image (x, y) convert it to grayscale and store it in the 'matrix'
-	for x := 0; x < width; x++ {
-		var row []uint8
-		for y := 0; y < height; y++ {
-			// Get the RGB from the current pixel
-			r, g, b, _ := img.At(x, y).RGBA()
-
-			// Convert the RGB to Grayscale (red*30% + green*59% + blue*11%)
-			// https://en.wikipedia.org/wiki/Grayscale#Luma_coding_in_video_systems
-			pixel := (float32(r) * 0.299) + (float32(g) * 0.587) + (float32(b) * 0.114)
-
-			// Convert the pixel from uin64 to uint8 (0-255) and append it to the slice
-			row = append(row, uint8(pixel))
+	switch img := img.(type) {
+	case *image.Gray:
+		for y := bounds.Min.Y; y < height; y++ {
+			row := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for x := bounds.Min.X; x < width; x++ {
+				pixels[x][y] = row[x-bounds.Min.X]
+			}
+		}
+	case *image.YCbCr:
+		// The Y channel already is the luma
+		for y := bounds.Min.Y; y < height; y++ {
+			for x := bounds.Min.X; x < width; x++ {
+				pixels[x][y] = img.Y[img.YOffset(x, y)]
+			}
+		}
+	case *image.RGBA:
+		for y := bounds.Min.Y; y < height; y++ {
+			row := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for x := bounds.Min.X; x < width; x++ {
+				i := 4 * (x - bounds.Min.X)
+				pixels[x][y] = luma(uint32(row[i])*0x101, uint32(row[i+1])*0x101, uint32(row[i+2])*0x101)
+			}
+		}
+	default:
+		for x := bounds.Min.X; x < width; x++ {
+			for y := bounds.Min.Y; y < height; y++ {
+				r, g, b, _ := img.At(x, y).RGBA()
+				pixels[x][y] = luma(r, g, b)
+			}
 		}
-		// Append the row (slice) to the pixels 'matrix'
-		pixels = append(pixels, row)
 	}
 
 	// Return all pixels
 	return pixels
 }
 
-// Calculate function calculates the LBP based on the radius and neighbors passed by parameter.
-// The radius and neighbors parameters are not in use.
+// luma converts 16 bit RGB to an 8 bit gray value (red*30% + green*59% + blue*11%)
+// https://en.wikipedia.org/wiki/Grayscale#Luma_coding_in_video_systems
+func luma(r, g, b uint32) uint8 {
+	pixel := (float32(r) * 0.299) + (float32(g) * 0.587) + (float32(b) * 0.114)
+	return uint8(uint32(pixel) >> 8)
+}
+
+// CalculateLBP calculates the circular LBP of the image: for every pixel, the neighbors are sampled
+// on a circle of the radius around it, with bilinear interpolation between pixels, and bit p of the
+// code is set when neighbor p is at least as bright as the pixel. Pixels closer than the radius to
+// the border have no complete circle and are skipped.
 func CalculateLBP(img image.Image, radius, neighbors uint8) ([][]uint64, error) {
 
 	var lbpPixels [][]uint64
@@ -178,53 +400,80 @@ func CalculateLBP(img image.Image, radius, neighbors uint8) ([][]uint64, error)
 	if radius <= 0 {
 		return lbpPixels, errors.New("Invalid radius parameter passed to the ApplyLBP function")
 	}
-	if neighbors <= 0 {
+	if neighbors <= 0 || neighbors > maxNeighbors {
 		return lbpPixels, errors.New("Invalid neighbors parameter passed to the ApplyLBP function")
 	}
 
 	// Get the pixels 'matrix' ([][]uint8)
-	pixels := GetPixels(img)
+	return lbpCodes(GetPixels(img), radius, neighbors)
+}
 
-	// Get the image size (width and height)
-	width, height := GetImageSize(img)
+// lbpCodes calculates the circular LBP of a grayscale 'matrix'.
+func lbpCodes(pixels [][]uint8, radius, neighbors uint8) ([][]uint64, error) {
+	codes, err := operatorCodes(pixels, Params{Radius: radius, Neighbors: neighbors})
+	if err != nil {
+		return nil, err
+	}
+	return codes[0], nil
+}
 
-	// For each pixel in the image
-	for x := 1; x < width-1; x++ {
-		var currentRow []uint64
-		for y := 1; y < height-1; y++ {
-
-			// Get the current pixel as the threshold
-			threshold := int(pixels[x][y])
-
-			binaryResult := ""
-			// Window based on the radius (3x3)
-			for tempX := x - 1; tempX <= x+1; tempX++ {
-				for tempY := y - 1; tempY <= y+1; tempY++ {
-					// Get the binary for all pixels around the threshold
-					if tempX != x || tempY != y {
-						binaryResult += getBinaryString(int(pixels[tempX][tempY]), threshold)
-					}
-				}
-			}
+// maxNeighbors keeps the codes in a uint64 with room to spare.
+const maxNeighbors = 32
 
-			// Convert the binary string to a decimal integer
-			dec, err := strconv.ParseUint(binaryResult, 2, 64)
-			if err != nil {
-				return lbpPixels, errors.New("Error converting binary to uint in the ApplyLBP function")
-			} else {
-				// Append the decimal do the result slice
-				// ParseUint returns a uint64 so we need to convert it to uint8
-				currentRow = append(currentRow, uint64(dec))
-			}
+// circleSample is a neighbor on the LBP circle, interpolated from the four pixels around it.
+type circleSample struct {
+	// Offset of the top left of the four pixels
+	dx, dy int
+	// Weights of the top left, top right, bottom left and bottom right pixels
+	w00, w10, w01, w11 float64
+}
+
+// circleSamples places the neighbors evenly on the circle, starting right of the pixel and going counterclockwise.
+func circleSamples(radius float64, neighbors int) []circleSample {
+	samples := make([]circleSample, neighbors)
+	for p := range samples {
+		angle := 2 * math.Pi * float64(p) / float64(neighbors)
+		// Snap positions which are pixels up to rounding errors of cos and sin
+		sx := math.Round(radius*math.Cos(angle)*1e6) / 1e6
+		sy := math.Round(-radius*math.Sin(angle)*1e6) / 1e6
+		fx, fy := math.Floor(sx), math.Floor(sy)
+		tx, ty := sx-fx, sy-fy
+		samples[p] = circleSample{
+			dx:  int(fx),
+			dy:  int(fy),
+			w00: (1 - tx) * (1 - ty),
+			w10: tx * (1 - ty),
+			w01: (1 - tx) * ty,
+			w11: tx * ty,
 		}
-		// Append the slice to the 'matrix'
-		lbpPixels = append(lbpPixels, currentRow)
 	}
-	return lbpPixels, nil
+	return samples
+}
+
+func (s circleSample) value(pixels [][]uint8, x, y int) float64 {
+	x0, y0 := x+s.dx, y+s.dy
+	v := s.w00 * float64(pixels[x0][y0])
+	// Skip pixels without weight, they may lie outside the image
+	if s.w10 != 0 {
+		v += s.w10 * float64(pixels[x0+1][y0])
+	}
+	if s.w01 != 0 {
+		v += s.w01 * float64(pixels[x0][y0+1])
+	}
+	if s.w11 != 0 {
+		v += s.w11 * float64(pixels[x0+1][y0+1])
+	}
+	// Interpolation of equal pixels must compare equal to the center
+	return math.Round(v*1e9) / 1e9
 }
 
 // Calculate function generates a histogram based on the 'matrix' passed by parameter.
 func CalculateHistograms(pixels [][]uint64, gridX, gridY uint8, bins_per_sub_images int) ([]float64, error) {
+	return CalculateMaskedHistograms(pixels, nil, gridX, gridY, bins_per_sub_images)
+}
+
+// CalculateMaskedHistograms leaves the pixels outside the mask out of the histograms. A nil mask keeps every pixel.
+func CalculateMaskedHistograms(pixels [][]uint64, mask [][]bool, gridX, gridY uint8, bins_per_sub_images int) ([]float64, error) {
 	var hist []float64
 
 	// Check the pixels 'matrix'
@@ -235,18 +484,21 @@ func CalculateHistograms(pixels [][]uint64, gridX, gridY uint8, bins_per_sub_ima
 	// Get the 'matrix' dimensions
 	rows := len(pixels)
 	cols := len(pixels[0])
+	if mask != nil && (len(mask) != rows || len(mask[0]) != cols) {
+		return hist, errors.New("The mask passed to the GetHistogram function does not match the pixels")
+	}
 
-	// Check the grid (X and Y)
-	if gridX <= 0 || int(gridX) >= cols {
+	// Check the grid (X and Y), x indexes the rows of the 'matrix' and y its columns
+	if gridX <= 0 || int(gridX) >= rows {
 		return hist, errors.New("Invalid grid X passed to the GetHistogram function")
 	}
-	if gridY <= 0 || int(gridX) >= rows {
+	if gridY <= 0 || int(gridY) >= cols {
 		return hist, errors.New("Invalid grid Y passed to the GetHistogram function")
 	}
 
 	// Get the size (width and height) of each region
-	gridWidth := cols / int(gridX)
-	gridHeight := rows / int(gridY)
+	gridWidth := rows / int(gridX)
+	gridHeight := cols / int(gridY)
 
 	// Calculates the histogram of each grid
 	for gX := 0; gX < int(gridX); gX++ {
@@ -262,10 +514,10 @@ func CalculateHistograms(pixels [][]uint64, gridX, gridY uint8, bins_per_sub_ima
 
 			// Make sure that no pixel has been leave at the end
 			if gX == int(gridX)-1 {
-				endPosX = cols
+				endPosX = rows
 			}
 			if gY == int(gridY)-1 {
-				endPosY = rows
+				endPosY = cols
 			}
 
 			// Creates the histogram for the current region
@@ -273,7 +525,7 @@ func CalculateHistograms(pixels [][]uint64, gridX, gridY uint8, bins_per_sub_ima
 				for y := startPosY; y < endPosY; y++ {
 					// Make sure we are trying to access a valid position
 					if x < len(pixels) {
-						if y < len(pixels[x]) {
+						if y < len(pixels[x]) && maskContains(mask, x, y) {
 							if int(pixels[x][y]) < len(regionHistogram) {
 								regionHistogram[pixels[x][y]] += 1
 							}
@@ -289,96 +541,164 @@ func CalculateHistograms(pixels [][]uint64, gridX, gridY uint8, bins_per_sub_ima
 	return hist, nil
 }
 
-func shuffleData(data Data, seed int) Data {
+func shuffleRecords(records []ManifestRecord, seed int) []ManifestRecord {
 	rand.Seed(int64(seed))
-	shuffledData := Data{
-		Labels:     make([]float64, len(data.Labels)),
-		Histograms: make([][]float64, len(data.Histograms)),
-	}
+	shuffledRecords := make([]ManifestRecord, len(records))
 
-	perm := rand.Perm(len(data.Histograms))
+	perm := rand.Perm(len(records))
 	for i, j := range perm {
-		shuffledData.Labels[i] = data.Labels[j]
-		shuffledData.Histograms[i] = data.Histograms[j]
+		shuffledRecords[i] = records[j]
 	}
 
-	return shuffledData
+	return shuffledRecords
 }
 
-func splitData(data Data, splitRatio float64, seed int) (Data, Data) {
-	// Shuffle the data first
-	shuffledData := shuffleData(data, seed)
-
-	numSamples := len(shuffledData.Histograms)
-	numTrain := int(float64(numSamples) * splitRatio)
-
-	trainData := Data{
-		Labels:     make([]float64, numTrain),
-		Histograms: make([][]float64, numTrain),
+// splitRecords splits the images before any feature is computed, so the split can be streamed. The
+// records are shuffled and cut into groups, one per patient when grouping by patient and one per image
+// otherwise, so no patient ends up in two splits. With stratification every label is split by the ratios
+// on its own. A group goes to the split its first image falls in, counted in images, and the splits keep
+// the shuffled order.
+func splitRecords(records []ManifestRecord, config SplitConfig) (train, validation, test []ManifestRecord) {
+	shuffledRecords := shuffleRecords(records, int(config.Seed))
+
+	groupOf := make([]int, len(shuffledRecords))
+	var groupSizes []int
+	var groupLabels []map[float64]int
+	patients := make(map[string]int)
+	for i, record := range shuffledRecords {
+		group, ok := patients[record.PatientID]
+		if !ok || !config.GroupByPatient || record.PatientID == "" {
+			group = len(groupSizes)
+			groupSizes = append(groupSizes, 0)
+			groupLabels = append(groupLabels, make(map[float64]int))
+			patients[record.PatientID] = group
+		}
+		groupOf[i] = group
+		groupSizes[group]++
+		groupLabels[group][record.Label]++
 	}
 
-	valData := Data{
-		Labels:     make([]float64, numSamples-numTrain),
-		Histograms: make([][]float64, numSamples-numTrain),
+	// Groups by stratum in shuffled order, a single stratum without stratification
+	strata := make(map[float64][]int)
+	var stratumOrder []float64
+	for group, labels := range groupLabels {
+		stratum := 0.0
+		if config.Stratify {
+			stratum = majorityLabel(labels)
+		}
+		if _, ok := strata[stratum]; !ok {
+			stratumOrder = append(stratumOrder, stratum)
+		}
+		strata[stratum] = append(strata[stratum], group)
 	}
 
-	for i := 0; i < numTrain; i++ {
-		trainData.Labels[i] = shuffledData.Labels[i]
-		trainData.Histograms[i] = shuffledData.Histograms[i]
+	splitOf := make([]int, len(groupSizes))
+	for _, stratum := range stratumOrder {
+		var size int
+		for _, group := range strata[stratum] {
+			size += groupSizes[group]
+		}
+		trainEnd := int(float64(size) * config.Train)
+		validationEnd := int(float64(size) * (config.Train + config.Validation))
+		if config.Test == 0 {
+			validationEnd = size
+		}
+		var before int
+		for _, group := range strata[stratum] {
+			switch {
+			case before < trainEnd:
+				splitOf[group] = 0
+			case before < validationEnd:
+				splitOf[group] = 1
+			default:
+				splitOf[group] = 2
+			}
+			before += groupSizes[group]
+		}
 	}
 
-	for i := numTrain; i < numSamples; i++ {
-		valData.Labels[i-numTrain] = shuffledData.Labels[i]
-		valData.Histograms[i-numTrain] = shuffledData.Histograms[i]
+	for i, record := range shuffledRecords {
+		switch splitOf[groupOf[i]] {
+		case 0:
+			train = append(train, record)
+		case 1:
+			validation = append(validation, record)
+		default:
+			test = append(test, record)
+		}
 	}
-
-	return trainData, valData
+	return train, validation, test
 }
 
-func PreprocessImagesForTraining() (Data, Data) {
-	normalLungsImgLocation := "data\\normal_training"
-	normalLungsImages, normalLabels, err := readImages(normalLungsImgLocation, 0.0)
-	if err != nil {
-		fmt.Println("Error loading normal lung images:", err)
-	}
-
-	covidLungsImgLocation := "data\\covid_training"
-	covidLungsImages, covidLabels, err := readImages(covidLungsImgLocation, 1.0)
-	if err != nil {
-		fmt.Println("Error loading covid lung images: ", err)
-	}
-
-	allImages, allLabels := append(normalLungsImages, covidLungsImages...), append(normalLabels, covidLabels...)
-
-	preprocessedAllImages, err := LBPHistograms(allImages, allLabels)
-	if err != nil {
-		fmt.Println("Error preprocessing images: ", err)
+// majorityLabel returns the most common label, the smallest one on a tie.
+func majorityLabel(counts map[float64]int) float64 {
+	var label float64
+	best := 0
+	for l, count := range counts {
+		if count > best || count == best && l < label {
+			label, best = l, count
+		}
 	}
+	return label
+}
 
-	trainData, validationData := splitData(*preprocessedAllImages, 0.8, 42)
-
-	return trainData, validationData
+// PreprocessImagesForTraining collects the streamed chunks into the training, validation and test sets,
+// the test set being empty without a test split. With per epoch augmentation the training set holds the
+// versions of all epochs one after the other.
+func PreprocessImagesForTraining(config DatasetConfig) (Data, Data, Data, error) {
+	var trainData, validationData, testData Data
+	err := StreamTraining(config, func(split string, chunk Data) error {
+		switch split {
+		case SplitTraining:
+			trainData = appendData(trainData, chunk)
+		case SplitValidation:
+			validationData = appendData(validationData, chunk)
+		default:
+			testData = appendData(testData, chunk)
+		}
+		return nil
+	})
+	return trainData, validationData, testData, err
 }
 
-func PreprocessImagesForEvaluation() Data {
-	normalLungsImgLocation := "data\\normal_eval"
-	normalLungsImages, normalLabels, err := readImages(normalLungsImgLocation, 0.0)
-	if err != nil {
-		fmt.Println("Error loading normal lung images:", err)
+// PreprocessImagesForCrossValidation collects the features of all training images, neither split nor
+// augmented, for the folds to be drawn from.
+func PreprocessImagesForCrossValidation(config DatasetConfig) (Data, error) {
+	var data Data
+	if err := config.Validate(); err != nil {
+		return data, err
 	}
-
-	covidLungsImgLocation := "data\\covid_eval"
-	covidLungsImages, covidLabels, err := readImages(covidLungsImgLocation, 1.0)
+	records, err := datasetRecords(config, true)
 	if err != nil {
-		fmt.Println("Error loading covid lung images: ", err)
+		return data, err
 	}
-
-	allImages, allLabels := append(normalLungsImages, covidLungsImages...), append(normalLabels, covidLabels...)
-
-	preprocessedAllImages, err := LBPHistograms(allImages, allLabels)
+	if len(records) == 0 {
+		return data, errors.New("The dataset has no training images")
+	}
+	ex, err := newExtraction(config)
 	if err != nil {
-		fmt.Println("Error preprocessing images: ", err)
+		return data, err
 	}
+	err = streamRecords(SplitTraining, 0, records, nil, ex, func(_ string, chunk Data) error {
+		data = appendData(data, chunk)
+		return nil
+	})
+	return data, err
+}
+
+// PreprocessImagesForEvaluation collects the streamed chunks into the evaluation set.
+func PreprocessImagesForEvaluation(config DatasetConfig) (Data, error) {
+	var evaluationData Data
+	err := StreamEvaluation(config, func(_ string, chunk Data) error {
+		evaluationData = appendData(evaluationData, chunk)
+		return nil
+	})
+	return evaluationData, err
+}
 
-	return *preprocessedAllImages
+func appendData(data, chunk Data) Data {
+	data.Labels = append(data.Labels, chunk.Labels...)
+	data.Histograms = append(data.Histograms, chunk.Histograms...)
+	data.Metadata = append(data.Metadata, chunk.Metadata...)
+	return data
 }
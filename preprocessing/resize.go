@@ -0,0 +1,150 @@
+package preprocessing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/nfnt/resize"
+)
+
+// Resize policies, i.e. how an image is brought to the target size
+const (
+	// Scale both sides to the target size, distorting images of another aspect ratio
+	ResizeStretch = "stretch"
+	// Fit the image inside the target size and pad the rest with black
+	ResizeLetterbox = "letterbox"
+	// Cover the target size and cut off what sticks out on both sides
+	ResizeCrop = "crop"
+	// Scale the shorter side to the smaller target side and keep the aspect ratio, so the size varies
+	ResizeShorter = "shorter"
+)
+
+// resizeKernels are the interpolation kernels by name.
+var resizeKernels = map[string]resize.InterpolationFunction{
+	"nearest":  resize.NearestNeighbor,
+	"bilinear": resize.Bilinear,
+	"bicubic":  resize.Bicubic,
+	"mitchell": resize.MitchellNetravali,
+	"lanczos2": resize.Lanczos2,
+	"lanczos3": resize.Lanczos3,
+}
+
+// maxResizeSide keeps a typo in the target size from exhausting the memory.
+const maxResizeSide = 4096
+
+// ResizeParams describe the geometry the features are computed at.
+type ResizeParams struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Policy string `json:"policy"`
+	Kernel string `json:"kernel"`
+}
+
+// defaultResize is the geometry the project started with, every image stretched to 150x150.
+var defaultResize = ResizeParams{Width: 150, Height: 150, Policy: ResizeStretch, Kernel: "lanczos3"}
+
+// ParseResize reads a description such as resize:size=224x224,policy=letterbox,kernel=bilinear.
+// Missing options keep the default geometry.
+func ParseResize(description string) (ResizeParams, error) {
+	name, options, _ := strings.Cut(strings.TrimSpace(description), ":")
+	if name != "resize" {
+		return defaultResize, fmt.Errorf("Invalid resize %q, expected resize:size=XxY,policy=...,kernel=...", description)
+	}
+	o := parseOptions("resize", options)
+	r := defaultResize
+	r.Width, r.Height = o.grid("size", r.Width, r.Height)
+	if policy, ok := o.take("policy"); ok {
+		r.Policy = policy
+	}
+	if kernel, ok := o.take("kernel"); ok {
+		r.Kernel = kernel
+	}
+	if err := o.done(); err != nil {
+		return r, err
+	}
+	return r, r.Validate()
+}
+
+// isResize reports whether a step description names a resize.
+func isResize(description string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(description), ":")
+	return name == "resize"
+}
+
+func (r ResizeParams) Validate() error {
+	if r.Width < 1 || r.Height < 1 || r.Width > maxResizeSide || r.Height > maxResizeSide {
+		return fmt.Errorf("The resize target must be between 1 and %d pixels on each side", maxResizeSide)
+	}
+	switch r.Policy {
+	case ResizeStretch, ResizeLetterbox, ResizeCrop, ResizeShorter:
+	default:
+		return fmt.Errorf("Unknown resize policy %q, expected %s, %s, %s or %s", r.Policy,
+			ResizeStretch, ResizeLetterbox, ResizeCrop, ResizeShorter)
+	}
+	if _, ok := resizeKernels[r.Kernel]; !ok {
+		var kernels []string
+		for name := range resizeKernels {
+			kernels = append(kernels, name)
+		}
+		sort.Strings(kernels)
+		return fmt.Errorf("Unknown interpolation kernel %q, expected one of %s", r.Kernel, strings.Join(kernels, ", "))
+	}
+	return nil
+}
+
+func (r ResizeParams) String() string {
+	return fmt.Sprintf("resize:size=%dx%d,policy=%s,kernel=%s", r.Width, r.Height, r.Policy, r.Kernel)
+}
+
+// orDefault replaces the zero value with the default geometry.
+func (r ResizeParams) orDefault() ResizeParams {
+	if r == (ResizeParams{}) {
+		return defaultResize
+	}
+	return r
+}
+
+// Apply brings the image to the target geometry.
+func (r ResizeParams) Apply(img image.Image) image.Image {
+	kernel := resizeKernels[r.Kernel]
+	bounds := img.Bounds()
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	targetWidth, targetHeight := float64(r.Width), float64(r.Height)
+
+	switch r.Policy {
+	case ResizeLetterbox:
+		scale := math.Min(targetWidth/width, targetHeight/height)
+		resized := resize.Resize(scaled(width, scale), scaled(height, scale), img, kernel)
+		return placeCentered(resized, r.Width, r.Height)
+
+	case ResizeCrop:
+		scale := math.Max(targetWidth/width, targetHeight/height)
+		resized := resize.Resize(scaled(width, scale), scaled(height, scale), img, kernel)
+		return placeCentered(resized, r.Width, r.Height)
+
+	case ResizeShorter:
+		scale := math.Min(targetWidth, targetHeight) / math.Min(width, height)
+		return resize.Resize(scaled(width, scale), scaled(height, scale), img, kernel)
+	}
+	return resize.Resize(uint(r.Width), uint(r.Height), img, kernel)
+}
+
+// scaled rounds a scaled side, keeping at least one pixel.
+func scaled(side, scale float64) uint {
+	return uint(math.Max(1, math.Round(side*scale)))
+}
+
+// placeCentered centers the image on a black canvas of the size, cutting off the parts which do not fit.
+func placeCentered(img image.Image, width, height int) image.Image {
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	bounds := img.Bounds()
+	offset := image.Pt((width-bounds.Dx())/2, (height-bounds.Dy())/2)
+	draw.Draw(canvas, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Src)
+	return canvas
+}
@@ -0,0 +1,323 @@
+package preprocessing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Segmenter finds the region of interest of an image, so text markers, shoulders and borders do not
+// contribute to the features.
+type Segmenter interface {
+	// Segment returns the pixels to extract features from, possibly cropped, and the mask of the region
+	// of interest within them, true for the pixels inside
+	Segment(pixels [][]uint8) ([][]uint8, [][]bool)
+	// String describes the step in the form ParsePipeline reads, e.g. lung:components=2,open=2,close=3,margin=4,crop=true
+	String() string
+}
+
+// MaskedExtractor is a feature extractor which can leave the pixels outside a region of interest out of
+// its features. Extractors which are not get the cropped pixels only.
+type MaskedExtractor interface {
+	FeatureExtractor
+	ExtractMasked(pixels [][]uint8, mask [][]bool) ([]float64, error)
+}
+
+// NewSegmenter creates a segmentation step from a description such as lung:margin=8.
+func NewSegmenter(description string) (Segmenter, error) {
+	name, options, _ := strings.Cut(strings.TrimSpace(description), ":")
+	if name != "lung" {
+		return nil, fmt.Errorf("Unknown segmentation %q, expected lung", name)
+	}
+	o := parseOptions("lung", options)
+	s := &lungSegmenter{
+		components: o.int("components", 2, 1),
+		open:       o.int("open", 2, 0),
+		close:      o.int("close", 3, 0),
+		margin:     o.int("margin", 4, 0),
+		crop:       o.bool("crop", true),
+	}
+	if err := o.done(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// isSegmenter reports whether a step description names a segmentation.
+func isSegmenter(description string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(description), ":")
+	return name == "lung"
+}
+
+// lungSegmenter is a classical lung field segmentation: the lungs are the largest dark regions inside
+// the body. Pixels darker than the Otsu threshold are candidates, the ones connected to the image border
+// (air around the body) are dropped, an opening separates the lungs from the trachea and other thin
+// connections, the largest components are kept, and a closing and hole filling add back the vessels
+// and nodules inside the lungs. The image is cropped to the bounding box of the lungs plus a margin.
+type lungSegmenter struct {
+	components int
+	open       int
+	close      int
+	margin     int
+	crop       bool
+}
+
+// minLungArea is the fraction of the image a component needs to count as a lung.
+const minLungArea = 0.01
+
+func (s *lungSegmenter) String() string {
+	return fmt.Sprintf("lung:components=%d,open=%d,close=%d,margin=%d,crop=%s",
+		s.components, s.open, s.close, s.margin, strconv.FormatBool(s.crop))
+}
+
+func (s *lungSegmenter) Segment(pixels [][]uint8) ([][]uint8, [][]bool) {
+	width := len(pixels)
+	height := len(pixels[0])
+
+	threshold := otsuThreshold(pixels)
+	mask := newMask(width, height)
+	for x := range pixels {
+		for y, v := range pixels[x] {
+			mask[x][y] = v <= threshold
+		}
+	}
+	labels, _ := connectedComponents(mask)
+	outside := borderLabels(labels)
+	for x := range mask {
+		for y := range mask[x] {
+			mask[x][y] = mask[x][y] && !outside[labels[x][y]]
+		}
+	}
+	mask = dilate(erode(mask, s.open), s.open)
+	mask = largestComponents(mask, s.components, int(minLungArea*float64(width*height)))
+	mask = erode(dilate(mask, s.close), s.close)
+	fillHoles(mask)
+
+	x0, x1, y0, y1, found := boundingBox(mask)
+	if !found {
+		// Nothing looks like a lung, e.g. an unusual exposure: use the whole image rather than drop it
+		return pixels, nil
+	}
+	if !s.crop {
+		return pixels, mask
+	}
+	x0, y0 = clamp(x0-s.margin, width), clamp(y0-s.margin, height)
+	x1, y1 = clamp(x1+s.margin, width), clamp(y1+s.margin, height)
+	cropped := make([][]uint8, x1-x0+1)
+	croppedMask := make([][]bool, x1-x0+1)
+	for x := x0; x <= x1; x++ {
+		cropped[x-x0] = pixels[x][y0 : y1+1]
+		croppedMask[x-x0] = mask[x][y0 : y1+1]
+	}
+	return cropped, croppedMask
+}
+
+// otsuThreshold returns the intensity which best separates the pixels into a dark and a bright class.
+func otsuThreshold(pixels [][]uint8) uint8 {
+	var hist [256]float64
+	var total, sum float64
+	for _, column := range pixels {
+		for _, v := range column {
+			hist[v]++
+			sum += float64(v)
+		}
+		total += float64(len(column))
+	}
+	var best float64
+	var threshold uint8
+	var darkCount, darkSum float64
+	for v := 0; v < 255; v++ {
+		darkCount += hist[v]
+		darkSum += float64(v) * hist[v]
+		brightCount := total - darkCount
+		if darkCount == 0 || brightCount == 0 {
+			continue
+		}
+		difference := darkSum/darkCount - (sum-darkSum)/brightCount
+		between := darkCount * brightCount * difference * difference
+		if between > best {
+			best, threshold = between, uint8(v)
+		}
+	}
+	return threshold
+}
+
+func newMask(width, height int) [][]bool {
+	mask := make([][]bool, width)
+	for x := range mask {
+		mask[x] = make([]bool, height)
+	}
+	return mask
+}
+
+// connectedComponents labels the 4-connected regions of the mask from 1 and returns their sizes by label.
+func connectedComponents(mask [][]bool) ([][]int, []int) {
+	width := len(mask)
+	height := len(mask[0])
+	labels := make([][]int, width)
+	for x := range labels {
+		labels[x] = make([]int, height)
+	}
+	sizes := []int{0}
+	var stack [][2]int
+	for x := range mask {
+		for y := range mask[x] {
+			if !mask[x][y] || labels[x][y] != 0 {
+				continue
+			}
+			label := len(sizes)
+			sizes = append(sizes, 0)
+			labels[x][y] = label
+			stack = append(stack[:0], [2]int{x, y})
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				sizes[label]++
+				for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+					nx, ny := p[0]+d[0], p[1]+d[1]
+					if nx >= 0 && nx < width && ny >= 0 && ny < height && mask[nx][ny] && labels[nx][ny] == 0 {
+						labels[nx][ny] = label
+						stack = append(stack, [2]int{nx, ny})
+					}
+				}
+			}
+		}
+	}
+	return labels, sizes
+}
+
+// borderLabels returns the labels of the components reaching the edge of the image.
+func borderLabels(labels [][]int) map[int]bool {
+	border := make(map[int]bool)
+	last := len(labels) - 1
+	for x := range labels {
+		for y, label := range labels[x] {
+			if label != 0 && (x == 0 || y == 0 || x == last || y == len(labels[x])-1) {
+				border[label] = true
+			}
+		}
+	}
+	return border
+}
+
+// largestComponents keeps the count largest components of at least minSize pixels.
+func largestComponents(mask [][]bool, count, minSize int) [][]bool {
+	labels, sizes := connectedComponents(mask)
+	keep := make([]bool, len(sizes))
+	for i := 0; i < count; i++ {
+		best := 0
+		for label := 1; label < len(sizes); label++ {
+			if !keep[label] && sizes[label] >= minSize && (best == 0 || sizes[label] > sizes[best]) {
+				best = label
+			}
+		}
+		if best == 0 {
+			break
+		}
+		keep[best] = true
+	}
+	result := newMask(len(mask), len(mask[0]))
+	for x := range labels {
+		for y, label := range labels[x] {
+			result[x][y] = keep[label]
+		}
+	}
+	return result
+}
+
+// fillHoles adds the regions outside the mask which do not reach the image border.
+func fillHoles(mask [][]bool) {
+	background := newMask(len(mask), len(mask[0]))
+	for x := range mask {
+		for y := range mask[x] {
+			background[x][y] = !mask[x][y]
+		}
+	}
+	labels, _ := connectedComponents(background)
+	border := borderLabels(labels)
+	for x := range labels {
+		for y, label := range labels[x] {
+			if label != 0 && !border[label] {
+				mask[x][y] = true
+			}
+		}
+	}
+}
+
+// erode keeps the pixels whose whole (2r+1) x (2r+1) neighborhood is in the mask.
+func erode(mask [][]bool, r int) [][]bool {
+	return morphology(mask, r, true)
+}
+
+// dilate adds the pixels with any pixel of the mask in their (2r+1) x (2r+1) neighborhood.
+func dilate(mask [][]bool, r int) [][]bool {
+	return morphology(mask, r, false)
+}
+
+// morphology filters the square neighborhood separably, first along x, then along y. Erosion treats
+// the pixels outside the image as inside the mask, so regions at the border do not shrink.
+func morphology(mask [][]bool, r int, all bool) [][]bool {
+	if r == 0 {
+		return mask
+	}
+	width := len(mask)
+	height := len(mask[0])
+	pass := func(get func(x, y int) bool, alongX bool) [][]bool {
+		result := newMask(width, height)
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				value := all
+				for d := -r; d <= r; d++ {
+					nx, ny := x, y+d
+					if alongX {
+						nx, ny = x+d, y
+					}
+					if nx < 0 || nx >= width || ny < 0 || ny >= height {
+						continue
+					}
+					if get(nx, ny) != all {
+						value = !all
+						break
+					}
+				}
+				result[x][y] = value
+			}
+		}
+		return result
+	}
+	horizontal := pass(func(x, y int) bool { return mask[x][y] }, true)
+	return pass(func(x, y int) bool { return horizontal[x][y] }, false)
+}
+
+// boundingBox returns the inclusive bounds of the mask.
+func boundingBox(mask [][]bool) (x0, x1, y0, y1 int, found bool) {
+	for x := range mask {
+		for y, inside := range mask[x] {
+			if !inside {
+				continue
+			}
+			if !found {
+				x0, x1, y0, y1, found = x, x, y, y, true
+				continue
+			}
+			if x < x0 {
+				x0 = x
+			}
+			if x > x1 {
+				x1 = x
+			}
+			if y < y0 {
+				y0 = y
+			}
+			if y > y1 {
+				y1 = y
+			}
+		}
+	}
+	return
+}
+
+// maskContains reports whether the pixel is in the region of interest, every pixel being when there is no mask.
+func maskContains(mask [][]bool, x, y int) bool {
+	return mask == nil || mask[x][y]
+}
@@ -0,0 +1,114 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+const (
+	SplitTraining   = "training"
+	SplitValidation = "validation"
+	SplitEvaluation = "evaluation"
+	// Held out training images, only evaluated once training is done
+	SplitTest = "test"
+)
+
+// DefaultChunkSize is the number of images whose features are computed and emitted together.
+const DefaultChunkSize = 256
+
+// ChunkHandler receives the features of the next chunk of a split. Returning an error stops the stream.
+type ChunkHandler func(split string, chunk Data) error
+
+// StreamTraining splits the training images and emits their features chunk by chunk, first the
+// training, then the validation and, when configured, the test split. Only one chunk of decoded images is in memory at a time.
+// With augmentation the training split is emitted with its augmented copies, or once per augmented
+// epoch with the epoch set in the chunks.
+// When grouping by patient, images without a patient id are grouped by the one in their DICOM header.
+func StreamTraining(config DatasetConfig, handle ChunkHandler) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	records, err := datasetRecords(config, true)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("The dataset has no training images")
+	}
+
+	if config.Split.GroupByPatient {
+		if missing := readPatientIDs(records); missing > 0 {
+			log.Printf("%d of %d training images have no patient id, each of them is split on its own\n", missing, len(records))
+		}
+	}
+	train, validation, test := splitRecords(records, config.Split)
+	if len(train) == 0 || len(validation) == 0 || config.Split.Test > 0 && len(test) == 0 {
+		return fmt.Errorf("Splitting %d images left the training, the validation or the test split empty", len(records))
+	}
+	ex, err := newExtraction(config)
+	if err != nil {
+		return err
+	}
+	for epoch, split := range config.Augmentation.trainingSplits(train) {
+		if err := streamRecords(SplitTraining, epoch, split.records, split.augment, ex, handle); err != nil {
+			return err
+		}
+	}
+	if err := streamRecords(SplitValidation, 0, validation, nil, ex, handle); err != nil {
+		return err
+	}
+	if len(test) == 0 {
+		return nil
+	}
+	return streamRecords(SplitTest, 0, test, nil, ex, handle)
+}
+
+// StreamEvaluation emits the features of the evaluation images chunk by chunk.
+func StreamEvaluation(config DatasetConfig, handle ChunkHandler) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	records, err := datasetRecords(config, false)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("The dataset has no evaluation images")
+	}
+	ex, err := newExtraction(config)
+	if err != nil {
+		return err
+	}
+	return streamRecords(SplitEvaluation, 0, records, nil, ex, handle)
+}
+
+// streamRecords emits the features of the records chunk by chunk. augment, if not nil, returns the
+// variation of every record, and is called in order just before its chunk is processed.
+func streamRecords(split string, epoch int, records []ManifestRecord, augment func(i int) *augmentation, ex *extraction, handle ChunkHandler) error {
+	chunkSize := ex.chunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	for start := 0; start < len(records); start += chunkSize {
+		end := start + chunkSize
+		if end > len(records) {
+			end = len(records)
+		}
+		var chunkAugmentations []*augmentation
+		if augment != nil {
+			for i := start; i < end; i++ {
+				chunkAugmentations = append(chunkAugmentations, augment(i))
+			}
+		}
+		chunk, err := extractFeatures(records[start:end], chunkAugmentations, ex)
+		if err != nil {
+			return fmt.Errorf("Error preprocessing images: %w", err)
+		}
+		chunk.Epoch = epoch
+		if err := handle(split, *chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
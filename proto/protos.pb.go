@@ -21,6 +21,157 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type DType int32
+
+const (
+	DType_DTYPE_FLOAT64 DType = 0
+	DType_DTYPE_FLOAT32 DType = 1
+)
+
+// Enum value maps for DType.
+var (
+	DType_name = map[int32]string{
+		0: "DTYPE_FLOAT64",
+		1: "DTYPE_FLOAT32",
+	}
+	DType_value = map[string]int32{
+		"DTYPE_FLOAT64": 0,
+		"DTYPE_FLOAT32": 1,
+	}
+)
+
+func (x DType) Enum() *DType {
+	p := new(DType)
+	*p = x
+	return p
+}
+
+func (x DType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DType) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_proto_enumTypes[0].Descriptor()
+}
+
+func (DType) Type() protoreflect.EnumType {
+	return &file_protos_proto_enumTypes[0]
+}
+
+func (x DType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DType.Descriptor instead.
+func (DType) EnumDescriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{0}
+}
+
+type Encoding int32
+
+const (
+	Encoding_ENCODING_NONE           Encoding = 0
+	Encoding_ENCODING_FLOAT16        Encoding = 1
+	Encoding_ENCODING_QUANTIZED_8BIT Encoding = 2
+	Encoding_ENCODING_QUANTIZED_4BIT Encoding = 3
+	Encoding_ENCODING_TOP_K          Encoding = 4
+)
+
+// Enum value maps for Encoding.
+var (
+	Encoding_name = map[int32]string{
+		0: "ENCODING_NONE",
+		1: "ENCODING_FLOAT16",
+		2: "ENCODING_QUANTIZED_8BIT",
+		3: "ENCODING_QUANTIZED_4BIT",
+		4: "ENCODING_TOP_K",
+	}
+	Encoding_value = map[string]int32{
+		"ENCODING_NONE":           0,
+		"ENCODING_FLOAT16":        1,
+		"ENCODING_QUANTIZED_8BIT": 2,
+		"ENCODING_QUANTIZED_4BIT": 3,
+		"ENCODING_TOP_K":          4,
+	}
+)
+
+func (x Encoding) Enum() *Encoding {
+	p := new(Encoding)
+	*p = x
+	return p
+}
+
+func (x Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_proto_enumTypes[1].Descriptor()
+}
+
+func (Encoding) Type() protoreflect.EnumType {
+	return &file_protos_proto_enumTypes[1]
+}
+
+func (x Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Encoding.Descriptor instead.
+func (Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{1}
+}
+
+// SecAggPurpose keeps rounds summing different vectors apart
+type SecAggPurpose int32
+
+const (
+	SecAggPurpose_SECAGG_PURPOSE_GRADIENTS          SecAggPurpose = 0
+	SecAggPurpose_SECAGG_PURPOSE_FEATURE_SUMS       SecAggPurpose = 1
+	SecAggPurpose_SECAGG_PURPOSE_FEATURE_DEVIATIONS SecAggPurpose = 2
+)
+
+// Enum value maps for SecAggPurpose.
+var (
+	SecAggPurpose_name = map[int32]string{
+		0: "SECAGG_PURPOSE_GRADIENTS",
+		1: "SECAGG_PURPOSE_FEATURE_SUMS",
+		2: "SECAGG_PURPOSE_FEATURE_DEVIATIONS",
+	}
+	SecAggPurpose_value = map[string]int32{
+		"SECAGG_PURPOSE_GRADIENTS":          0,
+		"SECAGG_PURPOSE_FEATURE_SUMS":       1,
+		"SECAGG_PURPOSE_FEATURE_DEVIATIONS": 2,
+	}
+)
+
+func (x SecAggPurpose) Enum() *SecAggPurpose {
+	p := new(SecAggPurpose)
+	*p = x
+	return p
+}
+
+func (x SecAggPurpose) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SecAggPurpose) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_proto_enumTypes[2].Descriptor()
+}
+
+func (SecAggPurpose) Type() protoreflect.EnumType {
+	return &file_protos_proto_enumTypes[2]
+}
+
+func (x SecAggPurpose) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SecAggPurpose.Descriptor instead.
+func (SecAggPurpose) EnumDescriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{2}
+}
+
 type Data struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -28,6 +179,8 @@ type Data struct {
 
 	Labels     []float64    `protobuf:"fixed64,2,rep,packed,name=labels,proto3" json:"labels,omitempty"`
 	Histograms []*Histogram `protobuf:"bytes,3,rep,name=histograms,proto3" json:"histograms,omitempty"`
+	// one entry per sample when the origin of the samples is known
+	Metadata []*ImageMetadata `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty"`
 }
 
 func (x *Data) Reset() {
@@ -76,16 +229,31 @@ func (x *Data) GetHistograms() []*Histogram {
 	return nil
 }
 
-type Histogram struct {
+func (x *Data) GetMetadata() []*ImageMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ImageMetadata struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Values []float64 `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	Path      string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	PatientId string `protobuf:"bytes,2,opt,name=patient_id,json=patientId,proto3" json:"patient_id,omitempty"`
+	// YYYY-MM-DD
+	AcquisitionDate string `protobuf:"bytes,3,opt,name=acquisition_date,json=acquisitionDate,proto3" json:"acquisition_date,omitempty"`
+	// PA or AP
+	View string `protobuf:"bytes,4,opt,name=view,proto3" json:"view,omitempty"`
+	// M or F
+	Sex     string `protobuf:"bytes,5,opt,name=sex,proto3" json:"sex,omitempty"`
+	AgeBand string `protobuf:"bytes,6,opt,name=age_band,json=ageBand,proto3" json:"age_band,omitempty"`
 }
 
-func (x *Histogram) Reset() {
-	*x = Histogram{}
+func (x *ImageMetadata) Reset() {
+	*x = ImageMetadata{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -93,13 +261,13 @@ func (x *Histogram) Reset() {
 	}
 }
 
-func (x *Histogram) String() string {
+func (x *ImageMetadata) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Histogram) ProtoMessage() {}
+func (*ImageMetadata) ProtoMessage() {}
 
-func (x *Histogram) ProtoReflect() protoreflect.Message {
+func (x *ImageMetadata) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -111,29 +279,63 @@ func (x *Histogram) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Histogram.ProtoReflect.Descriptor instead.
-func (*Histogram) Descriptor() ([]byte, []int) {
+// Deprecated: Use ImageMetadata.ProtoReflect.Descriptor instead.
+func (*ImageMetadata) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *Histogram) GetValues() []float64 {
+func (x *ImageMetadata) GetPath() string {
 	if x != nil {
-		return x.Values
+		return x.Path
 	}
-	return nil
+	return ""
+}
+
+func (x *ImageMetadata) GetPatientId() string {
+	if x != nil {
+		return x.PatientId
+	}
+	return ""
+}
+
+func (x *ImageMetadata) GetAcquisitionDate() string {
+	if x != nil {
+		return x.AcquisitionDate
+	}
+	return ""
+}
+
+func (x *ImageMetadata) GetView() string {
+	if x != nil {
+		return x.View
+	}
+	return ""
+}
+
+func (x *ImageMetadata) GetSex() string {
+	if x != nil {
+		return x.Sex
+	}
+	return ""
 }
 
-type TrainingDataSets struct {
+func (x *ImageMetadata) GetAgeBand() string {
+	if x != nil {
+		return x.AgeBand
+	}
+	return ""
+}
+
+type Histogram struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Training   *Data `protobuf:"bytes,1,opt,name=Training,proto3" json:"Training,omitempty"`
-	Validation *Data `protobuf:"bytes,2,opt,name=Validation,proto3" json:"Validation,omitempty"`
+	Values []float64 `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
 }
 
-func (x *TrainingDataSets) Reset() {
-	*x = TrainingDataSets{}
+func (x *Histogram) Reset() {
+	*x = Histogram{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -141,13 +343,13 @@ func (x *TrainingDataSets) Reset() {
 	}
 }
 
-func (x *TrainingDataSets) String() string {
+func (x *Histogram) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TrainingDataSets) ProtoMessage() {}
+func (*Histogram) ProtoMessage() {}
 
-func (x *TrainingDataSets) ProtoReflect() protoreflect.Message {
+func (x *Histogram) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -159,35 +361,34 @@ func (x *TrainingDataSets) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TrainingDataSets.ProtoReflect.Descriptor instead.
-func (*TrainingDataSets) Descriptor() ([]byte, []int) {
+// Deprecated: Use Histogram.ProtoReflect.Descriptor instead.
+func (*Histogram) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *TrainingDataSets) GetTraining() *Data {
-	if x != nil {
-		return x.Training
-	}
-	return nil
-}
-
-func (x *TrainingDataSets) GetValidation() *Data {
+func (x *Histogram) GetValues() []float64 {
 	if x != nil {
-		return x.Validation
+		return x.Values
 	}
 	return nil
 }
 
-type EvaluationDataSets struct {
+// DataChunk carries the features of consecutive images of one split
+type DataChunk struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Validation *Data `protobuf:"bytes,1,opt,name=Validation,proto3" json:"Validation,omitempty"`
+	// training, validation or evaluation
+	Split    string `protobuf:"bytes,1,opt,name=split,proto3" json:"split,omitempty"`
+	Sequence uint32 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Data     *Data  `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// version of the augmented training split, see preprocessing.AugmentationConfig.Epochs
+	Epoch uint32 `protobuf:"varint,4,opt,name=epoch,proto3" json:"epoch,omitempty"`
 }
 
-func (x *EvaluationDataSets) Reset() {
-	*x = EvaluationDataSets{}
+func (x *DataChunk) Reset() {
+	*x = DataChunk{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -195,13 +396,13 @@ func (x *EvaluationDataSets) Reset() {
 	}
 }
 
-func (x *EvaluationDataSets) String() string {
+func (x *DataChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EvaluationDataSets) ProtoMessage() {}
+func (*DataChunk) ProtoMessage() {}
 
-func (x *EvaluationDataSets) ProtoReflect() protoreflect.Message {
+func (x *DataChunk) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -213,26 +414,56 @@ func (x *EvaluationDataSets) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EvaluationDataSets.ProtoReflect.Descriptor instead.
-func (*EvaluationDataSets) Descriptor() ([]byte, []int) {
+// Deprecated: Use DataChunk.ProtoReflect.Descriptor instead.
+func (*DataChunk) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *EvaluationDataSets) GetValidation() *Data {
+func (x *DataChunk) GetSplit() string {
 	if x != nil {
-		return x.Validation
+		return x.Split
+	}
+	return ""
+}
+
+func (x *DataChunk) GetSequence() uint32 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *DataChunk) GetData() *Data {
+	if x != nil {
+		return x.Data
 	}
 	return nil
 }
 
-type ActivatePreprocTraining struct {
+func (x *DataChunk) GetEpoch() uint32 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+// DataStreamEnd follows the last chunk of a dataset
+type DataStreamEnd struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Chunks uint32 `protobuf:"varint,1,opt,name=chunks,proto3" json:"chunks,omitempty"`
+	// description of the preprocessing, the extracted features and their normalization, see preprocessing.FeaturePipeline.String
+	Features string `protobuf:"bytes,2,opt,name=features,proto3" json:"features,omitempty"`
+	// length of every feature vector
+	Dimension uint32 `protobuf:"varint,3,opt,name=dimension,proto3" json:"dimension,omitempty"`
+	// whether the features end with the zscore normalization, see preprocessing.FeaturePipeline.Standardized
+	Standardized bool `protobuf:"varint,4,opt,name=standardized,proto3" json:"standardized,omitempty"`
 }
 
-func (x *ActivatePreprocTraining) Reset() {
-	*x = ActivatePreprocTraining{}
+func (x *DataStreamEnd) Reset() {
+	*x = DataStreamEnd{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -240,13 +471,13 @@ func (x *ActivatePreprocTraining) Reset() {
 	}
 }
 
-func (x *ActivatePreprocTraining) String() string {
+func (x *DataStreamEnd) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActivatePreprocTraining) ProtoMessage() {}
+func (*DataStreamEnd) ProtoMessage() {}
 
-func (x *ActivatePreprocTraining) ProtoReflect() protoreflect.Message {
+func (x *DataStreamEnd) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -258,19 +489,53 @@ func (x *ActivatePreprocTraining) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActivatePreprocTraining.ProtoReflect.Descriptor instead.
-func (*ActivatePreprocTraining) Descriptor() ([]byte, []int) {
+// Deprecated: Use DataStreamEnd.ProtoReflect.Descriptor instead.
+func (*DataStreamEnd) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{4}
 }
 
-type ActivatePreprocEvaluation struct {
+func (x *DataStreamEnd) GetChunks() uint32 {
+	if x != nil {
+		return x.Chunks
+	}
+	return 0
+}
+
+func (x *DataStreamEnd) GetFeatures() string {
+	if x != nil {
+		return x.Features
+	}
+	return ""
+}
+
+func (x *DataStreamEnd) GetDimension() uint32 {
+	if x != nil {
+		return x.Dimension
+	}
+	return 0
+}
+
+func (x *DataStreamEnd) GetStandardized() bool {
+	if x != nil {
+		return x.Standardized
+	}
+	return false
+}
+
+type DatasetClass struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Name  string  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Label float64 `protobuf:"fixed64,2,opt,name=label,proto3" json:"label,omitempty"`
+	// folders relative to the dataset root
+	TrainingFolder   string `protobuf:"bytes,3,opt,name=training_folder,json=trainingFolder,proto3" json:"training_folder,omitempty"`
+	EvaluationFolder string `protobuf:"bytes,4,opt,name=evaluation_folder,json=evaluationFolder,proto3" json:"evaluation_folder,omitempty"`
 }
 
-func (x *ActivatePreprocEvaluation) Reset() {
-	*x = ActivatePreprocEvaluation{}
+func (x *DatasetClass) Reset() {
+	*x = DatasetClass{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -278,13 +543,13 @@ func (x *ActivatePreprocEvaluation) Reset() {
 	}
 }
 
-func (x *ActivatePreprocEvaluation) String() string {
+func (x *DatasetClass) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActivatePreprocEvaluation) ProtoMessage() {}
+func (*DatasetClass) ProtoMessage() {}
 
-func (x *ActivatePreprocEvaluation) ProtoReflect() protoreflect.Message {
+func (x *DatasetClass) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -296,21 +561,55 @@ func (x *ActivatePreprocEvaluation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActivatePreprocEvaluation.ProtoReflect.Descriptor instead.
-func (*ActivatePreprocEvaluation) Descriptor() ([]byte, []int) {
+// Deprecated: Use DatasetClass.ProtoReflect.Descriptor instead.
+func (*DatasetClass) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{5}
 }
 
-type ActivateLocalTraining struct {
+func (x *DatasetClass) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DatasetClass) GetLabel() float64 {
+	if x != nil {
+		return x.Label
+	}
+	return 0
+}
+
+func (x *DatasetClass) GetTrainingFolder() string {
+	if x != nil {
+		return x.TrainingFolder
+	}
+	return ""
+}
+
+func (x *DatasetClass) GetEvaluationFolder() string {
+	if x != nil {
+		return x.EvaluationFolder
+	}
+	return ""
+}
+
+type DatasetSplit struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	AggregationActor *actor.PID `protobuf:"bytes,1,opt,name=AggregationActor,proto3" json:"AggregationActor,omitempty"`
+	Train      float64 `protobuf:"fixed64,1,opt,name=train,proto3" json:"train,omitempty"`
+	Validation float64 `protobuf:"fixed64,2,opt,name=validation,proto3" json:"validation,omitempty"`
+	Seed       int64   `protobuf:"varint,3,opt,name=seed,proto3" json:"seed,omitempty"`
+	// held out images evaluated once after training
+	Test           float64 `protobuf:"fixed64,4,opt,name=test,proto3" json:"test,omitempty"`
+	Stratify       bool    `protobuf:"varint,5,opt,name=stratify,proto3" json:"stratify,omitempty"`
+	GroupByPatient bool    `protobuf:"varint,6,opt,name=group_by_patient,json=groupByPatient,proto3" json:"group_by_patient,omitempty"`
 }
 
-func (x *ActivateLocalTraining) Reset() {
-	*x = ActivateLocalTraining{}
+func (x *DatasetSplit) Reset() {
+	*x = DatasetSplit{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -318,13 +617,13 @@ func (x *ActivateLocalTraining) Reset() {
 	}
 }
 
-func (x *ActivateLocalTraining) String() string {
+func (x *DatasetSplit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActivateLocalTraining) ProtoMessage() {}
+func (*DatasetSplit) ProtoMessage() {}
 
-func (x *ActivateLocalTraining) ProtoReflect() protoreflect.Message {
+func (x *DatasetSplit) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -336,28 +635,88 @@ func (x *ActivateLocalTraining) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActivateLocalTraining.ProtoReflect.Descriptor instead.
-func (*ActivateLocalTraining) Descriptor() ([]byte, []int) {
+// Deprecated: Use DatasetSplit.ProtoReflect.Descriptor instead.
+func (*DatasetSplit) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ActivateLocalTraining) GetAggregationActor() *actor.PID {
+func (x *DatasetSplit) GetTrain() float64 {
 	if x != nil {
-		return x.AggregationActor
+		return x.Train
 	}
-	return nil
+	return 0
 }
 
-type ActivateEvaluation struct {
+func (x *DatasetSplit) GetValidation() float64 {
+	if x != nil {
+		return x.Validation
+	}
+	return 0
+}
+
+func (x *DatasetSplit) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *DatasetSplit) GetTest() float64 {
+	if x != nil {
+		return x.Test
+	}
+	return 0
+}
+
+func (x *DatasetSplit) GetStratify() bool {
+	if x != nil {
+		return x.Stratify
+	}
+	return false
+}
+
+func (x *DatasetSplit) GetGroupByPatient() bool {
+	if x != nil {
+		return x.GroupByPatient
+	}
+	return false
+}
+
+type DatasetConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	AggregationActor *actor.PID `protobuf:"bytes,1,opt,name=AggregationActor,proto3" json:"AggregationActor,omitempty"`
+	Root    string          `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Classes []*DatasetClass `protobuf:"bytes,2,rep,name=classes,proto3" json:"classes,omitempty"`
+	Split   *DatasetSplit   `protobuf:"bytes,3,opt,name=split,proto3" json:"split,omitempty"`
+	// manifests relative to the root, used instead of the class folders
+	TrainingManifest   string `protobuf:"bytes,4,opt,name=training_manifest,json=trainingManifest,proto3" json:"training_manifest,omitempty"`
+	EvaluationManifest string `protobuf:"bytes,5,opt,name=evaluation_manifest,json=evaluationManifest,proto3" json:"evaluation_manifest,omitempty"`
+	// images preprocessed in parallel, 0 for one per CPU
+	Workers int32 `protobuf:"varint,6,opt,name=workers,proto3" json:"workers,omitempty"`
+	// images whose features are sent in one chunk, 0 for the default
+	ChunkSize int32 `protobuf:"varint,7,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	// LBP scales whose histograms are concatenated
+	Lbp []*LBPParams `protobuf:"bytes,8,rep,name=lbp,proto3" json:"lbp,omitempty"`
+	// feature pipeline such as lbp:r=1,p=8+hog:cells=6x6, replaces the LBP scales when set
+	Features string `protobuf:"bytes,9,opt,name=features,proto3" json:"features,omitempty"`
+	// intensity normalization such as clahe:tiles=8x8,clip=2, applied before the features
+	Normalization string `protobuf:"bytes,10,opt,name=normalization,proto3" json:"normalization,omitempty"`
+	// region of interest such as lung:margin=4 the features are restricted to
+	Segmentation string `protobuf:"bytes,11,opt,name=segmentation,proto3" json:"segmentation,omitempty"`
+	// geometry such as resize:size=224x224,policy=letterbox,kernel=bilinear, 150x150 stretched when empty
+	Resize           string            `protobuf:"bytes,12,opt,name=resize,proto3" json:"resize,omitempty"`
+	Deidentification *Deidentification `protobuf:"bytes,13,opt,name=deidentification,proto3" json:"deidentification,omitempty"`
+	Augmentation     *Augmentation     `protobuf:"bytes,14,opt,name=augmentation,proto3" json:"augmentation,omitempty"`
+	// directory keeping the extracted features for later runs, no cache when empty
+	Cache string `protobuf:"bytes,15,opt,name=cache,proto3" json:"cache,omitempty"`
+	// normalization of the extracted features such as hellinger+zscore
+	FeatureNormalization string `protobuf:"bytes,16,opt,name=feature_normalization,json=featureNormalization,proto3" json:"feature_normalization,omitempty"`
 }
 
-func (x *ActivateEvaluation) Reset() {
-	*x = ActivateEvaluation{}
+func (x *DatasetConfig) Reset() {
+	*x = DatasetConfig{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -365,13 +724,13 @@ func (x *ActivateEvaluation) Reset() {
 	}
 }
 
-func (x *ActivateEvaluation) String() string {
+func (x *DatasetConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActivateEvaluation) ProtoMessage() {}
+func (*DatasetConfig) ProtoMessage() {}
 
-func (x *ActivateEvaluation) ProtoReflect() protoreflect.Message {
+func (x *DatasetConfig) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -383,26 +742,143 @@ func (x *ActivateEvaluation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActivateEvaluation.ProtoReflect.Descriptor instead.
-func (*ActivateEvaluation) Descriptor() ([]byte, []int) {
+// Deprecated: Use DatasetConfig.ProtoReflect.Descriptor instead.
+func (*DatasetConfig) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ActivateEvaluation) GetAggregationActor() *actor.PID {
+func (x *DatasetConfig) GetRoot() string {
 	if x != nil {
-		return x.AggregationActor
+		return x.Root
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetClasses() []*DatasetClass {
+	if x != nil {
+		return x.Classes
 	}
 	return nil
 }
 
-type GetTrainingActor struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+func (x *DatasetConfig) GetSplit() *DatasetSplit {
+	if x != nil {
+		return x.Split
+	}
+	return nil
+}
+
+func (x *DatasetConfig) GetTrainingManifest() string {
+	if x != nil {
+		return x.TrainingManifest
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetEvaluationManifest() string {
+	if x != nil {
+		return x.EvaluationManifest
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *DatasetConfig) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *DatasetConfig) GetLbp() []*LBPParams {
+	if x != nil {
+		return x.Lbp
+	}
+	return nil
+}
+
+func (x *DatasetConfig) GetFeatures() string {
+	if x != nil {
+		return x.Features
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetNormalization() string {
+	if x != nil {
+		return x.Normalization
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetSegmentation() string {
+	if x != nil {
+		return x.Segmentation
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetResize() string {
+	if x != nil {
+		return x.Resize
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetDeidentification() *Deidentification {
+	if x != nil {
+		return x.Deidentification
+	}
+	return nil
+}
+
+func (x *DatasetConfig) GetAugmentation() *Augmentation {
+	if x != nil {
+		return x.Augmentation
+	}
+	return nil
+}
+
+func (x *DatasetConfig) GetCache() string {
+	if x != nil {
+		return x.Cache
+	}
+	return ""
+}
+
+func (x *DatasetConfig) GetFeatureNormalization() string {
+	if x != nil {
+		return x.FeatureNormalization
+	}
+	return ""
+}
+
+// Augmentation describes the random variations of the training images, see preprocessing.AugmentationConfig
+type Augmentation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Rotation    float64 `protobuf:"fixed64,1,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	Translation float64 `protobuf:"fixed64,2,opt,name=translation,proto3" json:"translation,omitempty"`
+	Scale       float64 `protobuf:"fixed64,3,opt,name=scale,proto3" json:"scale,omitempty"`
+	Flip        float64 `protobuf:"fixed64,4,opt,name=flip,proto3" json:"flip,omitempty"`
+	Brightness  float64 `protobuf:"fixed64,5,opt,name=brightness,proto3" json:"brightness,omitempty"`
+	Contrast    float64 `protobuf:"fixed64,6,opt,name=contrast,proto3" json:"contrast,omitempty"`
+	Noise       float64 `protobuf:"fixed64,7,opt,name=noise,proto3" json:"noise,omitempty"`
+	Copies      int32   `protobuf:"varint,8,opt,name=copies,proto3" json:"copies,omitempty"`
+	Epochs      int32   `protobuf:"varint,9,opt,name=epochs,proto3" json:"epochs,omitempty"`
+	Seed        int64   `protobuf:"varint,10,opt,name=seed,proto3" json:"seed,omitempty"`
 }
 
-func (x *GetTrainingActor) Reset() {
-	*x = GetTrainingActor{}
+func (x *Augmentation) Reset() {
+	*x = Augmentation{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -410,13 +886,13 @@ func (x *GetTrainingActor) Reset() {
 	}
 }
 
-func (x *GetTrainingActor) String() string {
+func (x *Augmentation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTrainingActor) ProtoMessage() {}
+func (*Augmentation) ProtoMessage() {}
 
-func (x *GetTrainingActor) ProtoReflect() protoreflect.Message {
+func (x *Augmentation) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -428,19 +904,97 @@ func (x *GetTrainingActor) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTrainingActor.ProtoReflect.Descriptor instead.
-func (*GetTrainingActor) Descriptor() ([]byte, []int) {
+// Deprecated: Use Augmentation.ProtoReflect.Descriptor instead.
+func (*Augmentation) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{8}
 }
 
-type GetGlobalWeights struct {
+func (x *Augmentation) GetRotation() float64 {
+	if x != nil {
+		return x.Rotation
+	}
+	return 0
+}
+
+func (x *Augmentation) GetTranslation() float64 {
+	if x != nil {
+		return x.Translation
+	}
+	return 0
+}
+
+func (x *Augmentation) GetScale() float64 {
+	if x != nil {
+		return x.Scale
+	}
+	return 0
+}
+
+func (x *Augmentation) GetFlip() float64 {
+	if x != nil {
+		return x.Flip
+	}
+	return 0
+}
+
+func (x *Augmentation) GetBrightness() float64 {
+	if x != nil {
+		return x.Brightness
+	}
+	return 0
+}
+
+func (x *Augmentation) GetContrast() float64 {
+	if x != nil {
+		return x.Contrast
+	}
+	return 0
+}
+
+func (x *Augmentation) GetNoise() float64 {
+	if x != nil {
+		return x.Noise
+	}
+	return 0
+}
+
+func (x *Augmentation) GetCopies() int32 {
+	if x != nil {
+		return x.Copies
+	}
+	return 0
+}
+
+func (x *Augmentation) GetEpochs() int32 {
+	if x != nil {
+		return x.Epochs
+	}
+	return 0
+}
+
+func (x *Augmentation) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// Deidentification is the profile applied to the image metadata, see preprocessing.DeidentificationConfig
+type Deidentification struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Enabled      bool   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Salt         string `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
+	MaxDateShift int32  `protobuf:"varint,3,opt,name=max_date_shift,json=maxDateShift,proto3" json:"max_date_shift,omitempty"`
+	// actions by metadata field or DICOM tag such as (0010,0010)
+	Actions                 map[string]string `protobuf:"bytes,4,rep,name=actions,proto3" json:"actions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	AllowBurnedInAnnotation bool              `protobuf:"varint,5,opt,name=allow_burned_in_annotation,json=allowBurnedInAnnotation,proto3" json:"allow_burned_in_annotation,omitempty"`
 }
 
-func (x *GetGlobalWeights) Reset() {
-	*x = GetGlobalWeights{}
+func (x *Deidentification) Reset() {
+	*x = Deidentification{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -448,13 +1002,13 @@ func (x *GetGlobalWeights) Reset() {
 	}
 }
 
-func (x *GetGlobalWeights) String() string {
+func (x *Deidentification) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetGlobalWeights) ProtoMessage() {}
+func (*Deidentification) ProtoMessage() {}
 
-func (x *GetGlobalWeights) ProtoReflect() protoreflect.Message {
+func (x *Deidentification) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -466,22 +1020,64 @@ func (x *GetGlobalWeights) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetGlobalWeights.ProtoReflect.Descriptor instead.
-func (*GetGlobalWeights) Descriptor() ([]byte, []int) {
+// Deprecated: Use Deidentification.ProtoReflect.Descriptor instead.
+func (*Deidentification) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{9}
 }
 
-type GlobalWeights struct {
+func (x *Deidentification) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Deidentification) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *Deidentification) GetMaxDateShift() int32 {
+	if x != nil {
+		return x.MaxDateShift
+	}
+	return 0
+}
+
+func (x *Deidentification) GetActions() map[string]string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *Deidentification) GetAllowBurnedInAnnotation() bool {
+	if x != nil {
+		return x.AllowBurnedInAnnotation
+	}
+	return false
+}
+
+type LBPParams struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Biases  []*Biases  `protobuf:"bytes,1,rep,name=biases,proto3" json:"biases,omitempty"`
-	Weights []*Weights `protobuf:"bytes,2,rep,name=weights,proto3" json:"weights,omitempty"`
+	Radius    uint32 `protobuf:"varint,1,opt,name=radius,proto3" json:"radius,omitempty"`
+	Neighbors uint32 `protobuf:"varint,2,opt,name=neighbors,proto3" json:"neighbors,omitempty"`
+	GridX     uint32 `protobuf:"varint,3,opt,name=grid_x,json=gridX,proto3" json:"grid_x,omitempty"`
+	GridY     uint32 `protobuf:"varint,4,opt,name=grid_y,json=gridY,proto3" json:"grid_y,omitempty"`
+	// full, uniform, ri or riu2
+	Histogram string `protobuf:"bytes,5,opt,name=histogram,proto3" json:"histogram,omitempty"`
+	// lbp, ltp or cslbp
+	Operator  string `protobuf:"bytes,6,opt,name=operator,proto3" json:"operator,omitempty"`
+	Tolerance uint32 `protobuf:"varint,7,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
 }
 
-func (x *GlobalWeights) Reset() {
-	*x = GlobalWeights{}
+func (x *LBPParams) Reset() {
+	*x = LBPParams{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -489,13 +1085,13 @@ func (x *GlobalWeights) Reset() {
 	}
 }
 
-func (x *GlobalWeights) String() string {
+func (x *LBPParams) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GlobalWeights) ProtoMessage() {}
+func (*LBPParams) ProtoMessage() {}
 
-func (x *GlobalWeights) ProtoReflect() protoreflect.Message {
+func (x *LBPParams) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -507,35 +1103,70 @@ func (x *GlobalWeights) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GlobalWeights.ProtoReflect.Descriptor instead.
-func (*GlobalWeights) Descriptor() ([]byte, []int) {
+// Deprecated: Use LBPParams.ProtoReflect.Descriptor instead.
+func (*LBPParams) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *GlobalWeights) GetBiases() []*Biases {
+func (x *LBPParams) GetRadius() uint32 {
 	if x != nil {
-		return x.Biases
+		return x.Radius
 	}
-	return nil
+	return 0
 }
 
-func (x *GlobalWeights) GetWeights() []*Weights {
+func (x *LBPParams) GetNeighbors() uint32 {
 	if x != nil {
-		return x.Weights
+		return x.Neighbors
 	}
-	return nil
+	return 0
+}
+
+func (x *LBPParams) GetGridX() uint32 {
+	if x != nil {
+		return x.GridX
+	}
+	return 0
+}
+
+func (x *LBPParams) GetGridY() uint32 {
+	if x != nil {
+		return x.GridY
+	}
+	return 0
+}
+
+func (x *LBPParams) GetHistogram() string {
+	if x != nil {
+		return x.Histogram
+	}
+	return ""
+}
+
+func (x *LBPParams) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *LBPParams) GetTolerance() uint32 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
 }
 
-type GlobalWeightsTest struct {
+type ActivatePreprocTraining struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	String_ string `protobuf:"bytes,1,opt,name=string,proto3" json:"string,omitempty"`
+	Dataset *DatasetConfig `protobuf:"bytes,1,opt,name=dataset,proto3" json:"dataset,omitempty"`
 }
 
-func (x *GlobalWeightsTest) Reset() {
-	*x = GlobalWeightsTest{}
+func (x *ActivatePreprocTraining) Reset() {
+	*x = ActivatePreprocTraining{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -543,13 +1174,13 @@ func (x *GlobalWeightsTest) Reset() {
 	}
 }
 
-func (x *GlobalWeightsTest) String() string {
+func (x *ActivatePreprocTraining) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GlobalWeightsTest) ProtoMessage() {}
+func (*ActivatePreprocTraining) ProtoMessage() {}
 
-func (x *GlobalWeightsTest) ProtoReflect() protoreflect.Message {
+func (x *ActivatePreprocTraining) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -561,28 +1192,28 @@ func (x *GlobalWeightsTest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GlobalWeightsTest.ProtoReflect.Descriptor instead.
-func (*GlobalWeightsTest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActivatePreprocTraining.ProtoReflect.Descriptor instead.
+func (*ActivatePreprocTraining) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GlobalWeightsTest) GetString_() string {
+func (x *ActivatePreprocTraining) GetDataset() *DatasetConfig {
 	if x != nil {
-		return x.String_
+		return x.Dataset
 	}
-	return ""
+	return nil
 }
 
-type Biases struct {
+type ActivatePreprocEvaluation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []float64 `protobuf:"fixed64,1,rep,packed,name=data,proto3" json:"data,omitempty"`
+	Dataset *DatasetConfig `protobuf:"bytes,1,opt,name=dataset,proto3" json:"dataset,omitempty"`
 }
 
-func (x *Biases) Reset() {
-	*x = Biases{}
+func (x *ActivatePreprocEvaluation) Reset() {
+	*x = ActivatePreprocEvaluation{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -590,13 +1221,13 @@ func (x *Biases) Reset() {
 	}
 }
 
-func (x *Biases) String() string {
+func (x *ActivatePreprocEvaluation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Biases) ProtoMessage() {}
+func (*ActivatePreprocEvaluation) ProtoMessage() {}
 
-func (x *Biases) ProtoReflect() protoreflect.Message {
+func (x *ActivatePreprocEvaluation) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -608,28 +1239,29 @@ func (x *Biases) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Biases.ProtoReflect.Descriptor instead.
-func (*Biases) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActivatePreprocEvaluation.ProtoReflect.Descriptor instead.
+func (*ActivatePreprocEvaluation) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *Biases) GetData() []float64 {
+func (x *ActivatePreprocEvaluation) GetDataset() *DatasetConfig {
 	if x != nil {
-		return x.Data
+		return x.Dataset
 	}
 	return nil
 }
 
-type Weights struct {
+type ActivateLocalTraining struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []float64 `protobuf:"fixed64,1,rep,packed,name=data,proto3" json:"data,omitempty"`
+	AggregationActor *actor.PID     `protobuf:"bytes,1,opt,name=AggregationActor,proto3" json:"AggregationActor,omitempty"`
+	Dataset          *DatasetConfig `protobuf:"bytes,2,opt,name=dataset,proto3" json:"dataset,omitempty"`
 }
 
-func (x *Weights) Reset() {
-	*x = Weights{}
+func (x *ActivateLocalTraining) Reset() {
+	*x = ActivateLocalTraining{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -637,13 +1269,13 @@ func (x *Weights) Reset() {
 	}
 }
 
-func (x *Weights) String() string {
+func (x *ActivateLocalTraining) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Weights) ProtoMessage() {}
+func (*ActivateLocalTraining) ProtoMessage() {}
 
-func (x *Weights) ProtoReflect() protoreflect.Message {
+func (x *ActivateLocalTraining) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -655,26 +1287,38 @@ func (x *Weights) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Weights.ProtoReflect.Descriptor instead.
-func (*Weights) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActivateLocalTraining.ProtoReflect.Descriptor instead.
+func (*ActivateLocalTraining) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *Weights) GetData() []float64 {
+func (x *ActivateLocalTraining) GetAggregationActor() *actor.PID {
 	if x != nil {
-		return x.Data
+		return x.AggregationActor
 	}
 	return nil
 }
 
-type GetAggregationActor struct {
+func (x *ActivateLocalTraining) GetDataset() *DatasetConfig {
+	if x != nil {
+		return x.Dataset
+	}
+	return nil
+}
+
+type ActivateEvaluation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	AggregationActor *actor.PID `protobuf:"bytes,1,opt,name=AggregationActor,proto3" json:"AggregationActor,omitempty"`
+	// exact registry version to evaluate, empty for the current global model
+	ModelVersion string         `protobuf:"bytes,2,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	Dataset      *DatasetConfig `protobuf:"bytes,3,opt,name=dataset,proto3" json:"dataset,omitempty"`
 }
 
-func (x *GetAggregationActor) Reset() {
-	*x = GetAggregationActor{}
+func (x *ActivateEvaluation) Reset() {
+	*x = ActivateEvaluation{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_protos_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -682,13 +1326,13 @@ func (x *GetAggregationActor) Reset() {
 	}
 }
 
-func (x *GetAggregationActor) String() string {
+func (x *ActivateEvaluation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAggregationActor) ProtoMessage() {}
+func (*ActivateEvaluation) ProtoMessage() {}
 
-func (x *GetAggregationActor) ProtoReflect() protoreflect.Message {
+func (x *ActivateEvaluation) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -700,34 +1344,1888 @@ func (x *GetAggregationActor) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAggregationActor.ProtoReflect.Descriptor instead.
-func (*GetAggregationActor) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActivateEvaluation.ProtoReflect.Descriptor instead.
+func (*ActivateEvaluation) Descriptor() ([]byte, []int) {
 	return file_protos_proto_rawDescGZIP(), []int{14}
 }
 
-type GetEvaluationActor struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *ActivateEvaluation) GetAggregationActor() *actor.PID {
+	if x != nil {
+		return x.AggregationActor
+	}
+	return nil
+}
+
+func (x *ActivateEvaluation) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+func (x *ActivateEvaluation) GetDataset() *DatasetConfig {
+	if x != nil {
+		return x.Dataset
+	}
+	return nil
+}
+
+type GetTrainingActor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetTrainingActor) Reset() {
+	*x = GetTrainingActor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTrainingActor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrainingActor) ProtoMessage() {}
+
+func (x *GetTrainingActor) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrainingActor.ProtoReflect.Descriptor instead.
+func (*GetTrainingActor) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{15}
+}
+
+type GetGlobalWeights struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AcceptedEncodings []Encoding `protobuf:"varint,1,rep,packed,name=accepted_encodings,json=acceptedEncodings,proto3,enum=messages.Encoding" json:"accepted_encodings,omitempty"`
+	// registry version number or tag (candidate, production), empty for the current global model
+	ModelVersion string `protobuf:"bytes,2,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+}
+
+func (x *GetGlobalWeights) Reset() {
+	*x = GetGlobalWeights{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetGlobalWeights) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGlobalWeights) ProtoMessage() {}
+
+func (x *GetGlobalWeights) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGlobalWeights.ProtoReflect.Descriptor instead.
+func (*GetGlobalWeights) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetGlobalWeights) GetAcceptedEncodings() []Encoding {
+	if x != nil {
+		return x.AcceptedEncodings
+	}
+	return nil
+}
+
+func (x *GetGlobalWeights) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+type GlobalWeights struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Parameters        *ModelParameters     `protobuf:"bytes,1,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	SecureAggregation bool                 `protobuf:"varint,3,opt,name=secure_aggregation,json=secureAggregation,proto3" json:"secure_aggregation,omitempty"`
+	UpdateCompression *CompressionSettings `protobuf:"bytes,4,opt,name=update_compression,json=updateCompression,proto3" json:"update_compression,omitempty"`
+	// How long the aggregator waits for the feature statistics of other hospitals
+	StatisticsTimeoutMs uint64 `protobuf:"varint,5,opt,name=statistics_timeout_ms,json=statisticsTimeoutMs,proto3" json:"statistics_timeout_ms,omitempty"`
+}
+
+func (x *GlobalWeights) Reset() {
+	*x = GlobalWeights{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GlobalWeights) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GlobalWeights) ProtoMessage() {}
+
+func (x *GlobalWeights) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GlobalWeights.ProtoReflect.Descriptor instead.
+func (*GlobalWeights) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GlobalWeights) GetParameters() *ModelParameters {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *GlobalWeights) GetSecureAggregation() bool {
+	if x != nil {
+		return x.SecureAggregation
+	}
+	return false
+}
+
+func (x *GlobalWeights) GetUpdateCompression() *CompressionSettings {
+	if x != nil {
+		return x.UpdateCompression
+	}
+	return nil
+}
+
+func (x *GlobalWeights) GetStatisticsTimeoutMs() uint64 {
+	if x != nil {
+		return x.StatisticsTimeoutMs
+	}
+	return 0
+}
+
+// Tensor is a named n-dimensional array in row-major order. The values are either
+// in data or, when compressed on the wire, in compressed.
+type Tensor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name       string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Shape      []int64           `protobuf:"varint,2,rep,packed,name=shape,proto3" json:"shape,omitempty"`
+	Dtype      DType             `protobuf:"varint,3,opt,name=dtype,proto3,enum=messages.DType" json:"dtype,omitempty"`
+	Data       []float64         `protobuf:"fixed64,4,rep,packed,name=data,proto3" json:"data,omitempty"`
+	Compressed *CompressedVector `protobuf:"bytes,5,opt,name=compressed,proto3" json:"compressed,omitempty"`
+}
+
+func (x *Tensor) Reset() {
+	*x = Tensor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Tensor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tensor) ProtoMessage() {}
+
+func (x *Tensor) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tensor.ProtoReflect.Descriptor instead.
+func (*Tensor) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Tensor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tensor) GetShape() []int64 {
+	if x != nil {
+		return x.Shape
+	}
+	return nil
+}
+
+func (x *Tensor) GetDtype() DType {
+	if x != nil {
+		return x.Dtype
+	}
+	return DType_DTYPE_FLOAT64
+}
+
+func (x *Tensor) GetData() []float64 {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Tensor) GetCompressed() *CompressedVector {
+	if x != nil {
+		return x.Compressed
+	}
+	return nil
+}
+
+type ModelParameters struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ModelId string    `protobuf:"bytes,1,opt,name=model_id,json=modelId,proto3" json:"model_id,omitempty"`
+	Version uint64    `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Tensors []*Tensor `protobuf:"bytes,3,rep,name=tensors,proto3" json:"tensors,omitempty"`
+	// e.g. "features" describes the input features the model was trained on
+	Metadata map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ModelParameters) Reset() {
+	*x = ModelParameters{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModelParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelParameters) ProtoMessage() {}
+
+func (x *ModelParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelParameters.ProtoReflect.Descriptor instead.
+func (*ModelParameters) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ModelParameters) GetModelId() string {
+	if x != nil {
+		return x.ModelId
+	}
+	return ""
+}
+
+func (x *ModelParameters) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ModelParameters) GetTensors() []*Tensor {
+	if x != nil {
+		return x.Tensors
+	}
+	return nil
+}
+
+func (x *ModelParameters) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type CompressionSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Encoding  Encoding `protobuf:"varint,1,opt,name=encoding,proto3,enum=messages.Encoding" json:"encoding,omitempty"`
+	TopKRatio float64  `protobuf:"fixed64,2,opt,name=top_k_ratio,json=topKRatio,proto3" json:"top_k_ratio,omitempty"`
+}
+
+func (x *CompressionSettings) Reset() {
+	*x = CompressionSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompressionSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressionSettings) ProtoMessage() {}
+
+func (x *CompressionSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressionSettings.ProtoReflect.Descriptor instead.
+func (*CompressionSettings) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CompressionSettings) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_NONE
+}
+
+func (x *CompressionSettings) GetTopKRatio() float64 {
+	if x != nil {
+		return x.TopKRatio
+	}
+	return 0
+}
+
+type CompressedVector struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Encoding     Encoding  `protobuf:"varint,1,opt,name=encoding,proto3,enum=messages.Encoding" json:"encoding,omitempty"`
+	Length       uint32    `protobuf:"varint,2,opt,name=length,proto3" json:"length,omitempty"`
+	Packed       []byte    `protobuf:"bytes,3,opt,name=packed,proto3" json:"packed,omitempty"`
+	Min          float64   `protobuf:"fixed64,4,opt,name=min,proto3" json:"min,omitempty"`
+	Max          float64   `protobuf:"fixed64,5,opt,name=max,proto3" json:"max,omitempty"`
+	Indices      []uint32  `protobuf:"varint,6,rep,packed,name=indices,proto3" json:"indices,omitempty"`
+	SparseValues []float32 `protobuf:"fixed32,7,rep,packed,name=sparse_values,json=sparseValues,proto3" json:"sparse_values,omitempty"`
+}
+
+func (x *CompressedVector) Reset() {
+	*x = CompressedVector{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompressedVector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressedVector) ProtoMessage() {}
+
+func (x *CompressedVector) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressedVector.ProtoReflect.Descriptor instead.
+func (*CompressedVector) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CompressedVector) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_NONE
+}
+
+func (x *CompressedVector) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *CompressedVector) GetPacked() []byte {
+	if x != nil {
+		return x.Packed
+	}
+	return nil
+}
+
+func (x *CompressedVector) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *CompressedVector) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *CompressedVector) GetIndices() []uint32 {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
+func (x *CompressedVector) GetSparseValues() []float32 {
+	if x != nil {
+		return x.SparseValues
+	}
+	return nil
+}
+
+type GetAggregationActor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetAggregationActor) Reset() {
+	*x = GetAggregationActor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAggregationActor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAggregationActor) ProtoMessage() {}
+
+func (x *GetAggregationActor) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAggregationActor.ProtoReflect.Descriptor instead.
+func (*GetAggregationActor) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{22}
+}
+
+type GetEvaluationActor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetEvaluationActor) Reset() {
+	*x = GetEvaluationActor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetEvaluationActor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEvaluationActor) ProtoMessage() {}
+
+func (x *GetEvaluationActor) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEvaluationActor.ProtoReflect.Descriptor instead.
+func (*GetEvaluationActor) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{23}
+}
+
+// GradientUpdate carries the gradients of every parameter tensor, computed
+// against the model id and version in the parameters.
+type GradientUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Gradients *ModelParameters `protobuf:"bytes,1,opt,name=gradients,proto3" json:"gradients,omitempty"`
+	BatchSize int32            `protobuf:"varint,2,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	Hospital  string           `protobuf:"bytes,3,opt,name=hospital,proto3" json:"hospital,omitempty"`
+}
+
+func (x *GradientUpdate) Reset() {
+	*x = GradientUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GradientUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GradientUpdate) ProtoMessage() {}
+
+func (x *GradientUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GradientUpdate.ProtoReflect.Descriptor instead.
+func (*GradientUpdate) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GradientUpdate) GetGradients() *ModelParameters {
+	if x != nil {
+		return x.Gradients
+	}
+	return nil
+}
+
+func (x *GradientUpdate) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *GradientUpdate) GetHospital() string {
+	if x != nil {
+		return x.Hospital
+	}
+	return ""
+}
+
+// FeatureStatistics are a hospital's number of training images and a sum over them for every
+// feature. The aggregator pools them in two passes into the mean and standard deviation of a model
+// which standardizes its features, so no hospital shares its feature distribution: first the sums
+// of the features, then the sums of their squared deviations from the pooled mean.
+type FeatureStatistics struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ModelId    string    `protobuf:"bytes,1,opt,name=model_id,json=modelId,proto3" json:"model_id,omitempty"`
+	Hospital   string    `protobuf:"bytes,2,opt,name=hospital,proto3" json:"hospital,omitempty"`
+	Count      uint64    `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Sum        []float64 `protobuf:"fixed64,4,rep,packed,name=sum,proto3" json:"sum,omitempty"`
+	SumSquares []float64 `protobuf:"fixed64,5,rep,packed,name=sum_squares,json=sumSquares,proto3" json:"sum_squares,omitempty"`
+	// sum_squares holds the squared deviations from the pooled mean, sum is unset
+	Deviations bool `protobuf:"varint,6,opt,name=deviations,proto3" json:"deviations,omitempty"`
+}
+
+func (x *FeatureStatistics) Reset() {
+	*x = FeatureStatistics{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeatureStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureStatistics) ProtoMessage() {}
+
+func (x *FeatureStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureStatistics.ProtoReflect.Descriptor instead.
+func (*FeatureStatistics) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *FeatureStatistics) GetModelId() string {
+	if x != nil {
+		return x.ModelId
+	}
+	return ""
+}
+
+func (x *FeatureStatistics) GetHospital() string {
+	if x != nil {
+		return x.Hospital
+	}
+	return ""
+}
+
+func (x *FeatureStatistics) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *FeatureStatistics) GetSum() []float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return nil
+}
+
+func (x *FeatureStatistics) GetSumSquares() []float64 {
+	if x != nil {
+		return x.SumSquares
+	}
+	return nil
+}
+
+func (x *FeatureStatistics) GetDeviations() bool {
+	if x != nil {
+		return x.Deviations
+	}
+	return false
+}
+
+// FeatureStatisticsReceived answers FeatureStatistics once the aggregator pooled them, with the
+// pooled mean after the first pass
+type FeatureStatisticsReceived struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Error string    `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Mean  []float64 `protobuf:"fixed64,2,rep,packed,name=mean,proto3" json:"mean,omitempty"`
+}
+
+func (x *FeatureStatisticsReceived) Reset() {
+	*x = FeatureStatisticsReceived{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeatureStatisticsReceived) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureStatisticsReceived) ProtoMessage() {}
+
+func (x *FeatureStatisticsReceived) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureStatisticsReceived.ProtoReflect.Descriptor instead.
+func (*FeatureStatisticsReceived) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *FeatureStatisticsReceived) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FeatureStatisticsReceived) GetMean() []float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return nil
+}
+
+type TrainingFinished struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TrainingFinished) Reset() {
+	*x = TrainingFinished{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrainingFinished) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrainingFinished) ProtoMessage() {}
+
+func (x *TrainingFinished) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrainingFinished.ProtoReflect.Descriptor instead.
+func (*TrainingFinished) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{27}
+}
+
+type PreprocessingFinished struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PreprocessingFinished) Reset() {
+	*x = PreprocessingFinished{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreprocessingFinished) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreprocessingFinished) ProtoMessage() {}
+
+func (x *PreprocessingFinished) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreprocessingFinished.ProtoReflect.Descriptor instead.
+func (*PreprocessingFinished) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{28}
+}
+
+type EvaluationFinished struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *EvaluationFinished) Reset() {
+	*x = EvaluationFinished{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvaluationFinished) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluationFinished) ProtoMessage() {}
+
+func (x *EvaluationFinished) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluationFinished.ProtoReflect.Descriptor instead.
+func (*EvaluationFinished) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{29}
+}
+
+type SecAggAdvertiseKeys struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClientId       uint32        `protobuf:"varint,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	MaskPublicKey  []byte        `protobuf:"bytes,2,opt,name=mask_public_key,json=maskPublicKey,proto3" json:"mask_public_key,omitempty"`
+	SharePublicKey []byte        `protobuf:"bytes,3,opt,name=share_public_key,json=sharePublicKey,proto3" json:"share_public_key,omitempty"`
+	Purpose        SecAggPurpose `protobuf:"varint,4,opt,name=purpose,proto3,enum=messages.SecAggPurpose" json:"purpose,omitempty"`
+}
+
+func (x *SecAggAdvertiseKeys) Reset() {
+	*x = SecAggAdvertiseKeys{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggAdvertiseKeys) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggAdvertiseKeys) ProtoMessage() {}
+
+func (x *SecAggAdvertiseKeys) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggAdvertiseKeys.ProtoReflect.Descriptor instead.
+func (*SecAggAdvertiseKeys) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SecAggAdvertiseKeys) GetClientId() uint32 {
+	if x != nil {
+		return x.ClientId
+	}
+	return 0
+}
+
+func (x *SecAggAdvertiseKeys) GetMaskPublicKey() []byte {
+	if x != nil {
+		return x.MaskPublicKey
+	}
+	return nil
+}
+
+func (x *SecAggAdvertiseKeys) GetSharePublicKey() []byte {
+	if x != nil {
+		return x.SharePublicKey
+	}
+	return nil
+}
+
+func (x *SecAggAdvertiseKeys) GetPurpose() SecAggPurpose {
+	if x != nil {
+		return x.Purpose
+	}
+	return SecAggPurpose_SECAGG_PURPOSE_GRADIENTS
+}
+
+type SecAggRoundKeys struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round     uint64                 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Threshold uint32                 `protobuf:"varint,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Clients   []*SecAggAdvertiseKeys `protobuf:"bytes,3,rep,name=clients,proto3" json:"clients,omitempty"`
+	Aborted   bool                   `protobuf:"varint,4,opt,name=aborted,proto3" json:"aborted,omitempty"`
+}
+
+func (x *SecAggRoundKeys) Reset() {
+	*x = SecAggRoundKeys{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggRoundKeys) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggRoundKeys) ProtoMessage() {}
+
+func (x *SecAggRoundKeys) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggRoundKeys.ProtoReflect.Descriptor instead.
+func (*SecAggRoundKeys) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SecAggRoundKeys) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SecAggRoundKeys) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *SecAggRoundKeys) GetClients() []*SecAggAdvertiseKeys {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *SecAggRoundKeys) GetAborted() bool {
+	if x != nil {
+		return x.Aborted
+	}
+	return false
+}
+
+type SecAggEncryptedShare struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From       uint32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To         uint32 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	Nonce      []byte `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Ciphertext []byte `protobuf:"bytes,4,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+}
+
+func (x *SecAggEncryptedShare) Reset() {
+	*x = SecAggEncryptedShare{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggEncryptedShare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggEncryptedShare) ProtoMessage() {}
+
+func (x *SecAggEncryptedShare) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggEncryptedShare.ProtoReflect.Descriptor instead.
+func (*SecAggEncryptedShare) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SecAggEncryptedShare) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *SecAggEncryptedShare) GetTo() uint32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *SecAggEncryptedShare) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *SecAggEncryptedShare) GetCiphertext() []byte {
+	if x != nil {
+		return x.Ciphertext
+	}
+	return nil
+}
+
+type SecAggSharePair struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From          uint32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            uint32 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	SelfSeedShare []byte `protobuf:"bytes,3,opt,name=self_seed_share,json=selfSeedShare,proto3" json:"self_seed_share,omitempty"`
+	MaskKeyShare  []byte `protobuf:"bytes,4,opt,name=mask_key_share,json=maskKeyShare,proto3" json:"mask_key_share,omitempty"`
+}
+
+func (x *SecAggSharePair) Reset() {
+	*x = SecAggSharePair{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggSharePair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggSharePair) ProtoMessage() {}
+
+func (x *SecAggSharePair) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggSharePair.ProtoReflect.Descriptor instead.
+func (*SecAggSharePair) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SecAggSharePair) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *SecAggSharePair) GetTo() uint32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *SecAggSharePair) GetSelfSeedShare() []byte {
+	if x != nil {
+		return x.SelfSeedShare
+	}
+	return nil
+}
+
+func (x *SecAggSharePair) GetMaskKeyShare() []byte {
+	if x != nil {
+		return x.MaskKeyShare
+	}
+	return nil
+}
+
+type SecAggShareKeys struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round    uint64                  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	ClientId uint32                  `protobuf:"varint,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Shares   []*SecAggEncryptedShare `protobuf:"bytes,3,rep,name=shares,proto3" json:"shares,omitempty"`
+}
+
+func (x *SecAggShareKeys) Reset() {
+	*x = SecAggShareKeys{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggShareKeys) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggShareKeys) ProtoMessage() {}
+
+func (x *SecAggShareKeys) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggShareKeys.ProtoReflect.Descriptor instead.
+func (*SecAggShareKeys) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SecAggShareKeys) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SecAggShareKeys) GetClientId() uint32 {
+	if x != nil {
+		return x.ClientId
+	}
+	return 0
+}
+
+func (x *SecAggShareKeys) GetShares() []*SecAggEncryptedShare {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+type SecAggRoundShares struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round   uint64                  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Shares  []*SecAggEncryptedShare `protobuf:"bytes,2,rep,name=shares,proto3" json:"shares,omitempty"`
+	Aborted bool                    `protobuf:"varint,3,opt,name=aborted,proto3" json:"aborted,omitempty"`
+}
+
+func (x *SecAggRoundShares) Reset() {
+	*x = SecAggRoundShares{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggRoundShares) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggRoundShares) ProtoMessage() {}
+
+func (x *SecAggRoundShares) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggRoundShares.ProtoReflect.Descriptor instead.
+func (*SecAggRoundShares) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SecAggRoundShares) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SecAggRoundShares) GetShares() []*SecAggEncryptedShare {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+func (x *SecAggRoundShares) GetAborted() bool {
+	if x != nil {
+		return x.Aborted
+	}
+	return false
+}
+
+type MaskedGradientUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round    uint64   `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	ClientId uint32   `protobuf:"varint,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Data     []uint64 `protobuf:"varint,3,rep,packed,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *MaskedGradientUpdate) Reset() {
+	*x = MaskedGradientUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MaskedGradientUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskedGradientUpdate) ProtoMessage() {}
+
+func (x *MaskedGradientUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskedGradientUpdate.ProtoReflect.Descriptor instead.
+func (*MaskedGradientUpdate) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *MaskedGradientUpdate) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *MaskedGradientUpdate) GetClientId() uint32 {
+	if x != nil {
+		return x.ClientId
+	}
+	return 0
+}
+
+func (x *MaskedGradientUpdate) GetData() []uint64 {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SecAggUnmaskRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round     uint64   `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Survivors []uint32 `protobuf:"varint,2,rep,packed,name=survivors,proto3" json:"survivors,omitempty"`
+	Dropped   []uint32 `protobuf:"varint,3,rep,packed,name=dropped,proto3" json:"dropped,omitempty"`
+	Aborted   bool     `protobuf:"varint,4,opt,name=aborted,proto3" json:"aborted,omitempty"`
+}
+
+func (x *SecAggUnmaskRequest) Reset() {
+	*x = SecAggUnmaskRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggUnmaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggUnmaskRequest) ProtoMessage() {}
+
+func (x *SecAggUnmaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggUnmaskRequest.ProtoReflect.Descriptor instead.
+func (*SecAggUnmaskRequest) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SecAggUnmaskRequest) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SecAggUnmaskRequest) GetSurvivors() []uint32 {
+	if x != nil {
+		return x.Survivors
+	}
+	return nil
+}
+
+func (x *SecAggUnmaskRequest) GetDropped() []uint32 {
+	if x != nil {
+		return x.Dropped
+	}
+	return nil
+}
+
+func (x *SecAggUnmaskRequest) GetAborted() bool {
+	if x != nil {
+		return x.Aborted
+	}
+	return false
+}
+
+type SecAggSecretShare struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner uint32 `protobuf:"varint,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *SecAggSecretShare) Reset() {
+	*x = SecAggSecretShare{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggSecretShare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggSecretShare) ProtoMessage() {}
+
+func (x *SecAggSecretShare) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggSecretShare.ProtoReflect.Descriptor instead.
+func (*SecAggSecretShare) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SecAggSecretShare) GetOwner() uint32 {
+	if x != nil {
+		return x.Owner
+	}
+	return 0
+}
+
+func (x *SecAggSecretShare) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// SecAggRoundResult answers the unmasking shares once the round's sum was applied or failed
+type SecAggRoundResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round uint64 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// The pooled feature mean of a SECAGG_PURPOSE_FEATURE_SUMS round
+	Mean []float64 `protobuf:"fixed64,3,rep,packed,name=mean,proto3" json:"mean,omitempty"`
+}
+
+func (x *SecAggRoundResult) Reset() {
+	*x = SecAggRoundResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggRoundResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggRoundResult) ProtoMessage() {}
+
+func (x *SecAggRoundResult) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggRoundResult.ProtoReflect.Descriptor instead.
+func (*SecAggRoundResult) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SecAggRoundResult) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SecAggRoundResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SecAggRoundResult) GetMean() []float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return nil
+}
+
+type SecAggUnmaskShares struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round          uint64               `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	ClientId       uint32               `protobuf:"varint,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	SelfSeedShares []*SecAggSecretShare `protobuf:"bytes,3,rep,name=self_seed_shares,json=selfSeedShares,proto3" json:"self_seed_shares,omitempty"`
+	MaskKeyShares  []*SecAggSecretShare `protobuf:"bytes,4,rep,name=mask_key_shares,json=maskKeyShares,proto3" json:"mask_key_shares,omitempty"`
+}
+
+func (x *SecAggUnmaskShares) Reset() {
+	*x = SecAggUnmaskShares{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecAggUnmaskShares) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecAggUnmaskShares) ProtoMessage() {}
+
+func (x *SecAggUnmaskShares) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecAggUnmaskShares.ProtoReflect.Descriptor instead.
+func (*SecAggUnmaskShares) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *SecAggUnmaskShares) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SecAggUnmaskShares) GetClientId() uint32 {
+	if x != nil {
+		return x.ClientId
+	}
+	return 0
+}
+
+func (x *SecAggUnmaskShares) GetSelfSeedShares() []*SecAggSecretShare {
+	if x != nil {
+		return x.SelfSeedShares
+	}
+	return nil
+}
+
+func (x *SecAggUnmaskShares) GetMaskKeyShares() []*SecAggSecretShare {
+	if x != nil {
+		return x.MaskKeyShares
+	}
+	return nil
+}
+
+type OptimizerState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Eta      float64   `protobuf:"fixed64,1,opt,name=eta,proto3" json:"eta,omitempty"`
+	Momentum float64   `protobuf:"fixed64,2,opt,name=momentum,proto3" json:"momentum,omitempty"`
+	Steps    uint64    `protobuf:"varint,3,opt,name=steps,proto3" json:"steps,omitempty"`
+	Velocity []*Tensor `protobuf:"bytes,4,rep,name=velocity,proto3" json:"velocity,omitempty"`
+}
+
+func (x *OptimizerState) Reset() {
+	*x = OptimizerState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptimizerState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizerState) ProtoMessage() {}
+
+func (x *OptimizerState) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizerState.ProtoReflect.Descriptor instead.
+func (*OptimizerState) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *OptimizerState) GetEta() float64 {
+	if x != nil {
+		return x.Eta
+	}
+	return 0
+}
+
+func (x *OptimizerState) GetMomentum() float64 {
+	if x != nil {
+		return x.Momentum
+	}
+	return 0
+}
+
+func (x *OptimizerState) GetSteps() uint64 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *OptimizerState) GetVelocity() []*Tensor {
+	if x != nil {
+		return x.Velocity
+	}
+	return nil
+}
+
+type Checkpoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Parameters             *ModelParameters `protobuf:"bytes,1,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	Optimizer              *OptimizerState  `protobuf:"bytes,2,opt,name=optimizer,proto3" json:"optimizer,omitempty"`
+	SecureAggregationRound uint64           `protobuf:"varint,3,opt,name=secure_aggregation_round,json=secureAggregationRound,proto3" json:"secure_aggregation_round,omitempty"`
+	CreatedUnix            int64            `protobuf:"varint,4,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+}
+
+func (x *Checkpoint) Reset() {
+	*x = Checkpoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Checkpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Checkpoint) ProtoMessage() {}
+
+func (x *Checkpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Checkpoint.ProtoReflect.Descriptor instead.
+func (*Checkpoint) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *Checkpoint) GetParameters() *ModelParameters {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetOptimizer() *OptimizerState {
+	if x != nil {
+		return x.Optimizer
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetSecureAggregationRound() uint64 {
+	if x != nil {
+		return x.SecureAggregationRound
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+type ValidationMetrics struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hospital     string  `protobuf:"bytes,1,opt,name=hospital,proto3" json:"hospital,omitempty"`
+	F1Score      float64 `protobuf:"fixed64,2,opt,name=f1_score,json=f1Score,proto3" json:"f1_score,omitempty"`
+	Recall       float64 `protobuf:"fixed64,3,opt,name=recall,proto3" json:"recall,omitempty"`
+	Samples      int32   `protobuf:"varint,4,opt,name=samples,proto3" json:"samples,omitempty"`
+	ReportedUnix int64   `protobuf:"varint,5,opt,name=reported_unix,json=reportedUnix,proto3" json:"reported_unix,omitempty"`
+}
+
+func (x *ValidationMetrics) Reset() {
+	*x = ValidationMetrics{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidationMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationMetrics) ProtoMessage() {}
+
+func (x *ValidationMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *GetEvaluationActor) Reset() {
-	*x = GetEvaluationActor{}
+// Deprecated: Use ValidationMetrics.ProtoReflect.Descriptor instead.
+func (*ValidationMetrics) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ValidationMetrics) GetHospital() string {
+	if x != nil {
+		return x.Hospital
+	}
+	return ""
+}
+
+func (x *ValidationMetrics) GetF1Score() float64 {
+	if x != nil {
+		return x.F1Score
+	}
+	return 0
+}
+
+func (x *ValidationMetrics) GetRecall() float64 {
+	if x != nil {
+		return x.Recall
+	}
+	return 0
+}
+
+func (x *ValidationMetrics) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *ValidationMetrics) GetReportedUnix() int64 {
+	if x != nil {
+		return x.ReportedUnix
+	}
+	return 0
+}
+
+// ValidationReport is sent by a hospital after validating a version of the global model.
+type ValidationReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ModelVersion uint64             `protobuf:"varint,1,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	Metrics      *ValidationMetrics `protobuf:"bytes,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+}
+
+func (x *ValidationReport) Reset() {
+	*x = ValidationReport{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_proto_msgTypes[15]
+		mi := &file_protos_proto_msgTypes[44]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetEvaluationActor) String() string {
+func (x *ValidationReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEvaluationActor) ProtoMessage() {}
+func (*ValidationReport) ProtoMessage() {}
 
-func (x *GetEvaluationActor) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_proto_msgTypes[15]
+func (x *ValidationReport) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[44]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -738,37 +3236,51 @@ func (x *GetEvaluationActor) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEvaluationActor.ProtoReflect.Descriptor instead.
-func (*GetEvaluationActor) Descriptor() ([]byte, []int) {
-	return file_protos_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use ValidationReport.ProtoReflect.Descriptor instead.
+func (*ValidationReport) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{44}
 }
 
-type GradientUpdate struct {
+func (x *ValidationReport) GetModelVersion() uint64 {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return 0
+}
+
+func (x *ValidationReport) GetMetrics() *ValidationMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+// ValidationReportReceived answers ValidationReport once the metrics are recorded
+type ValidationReportReceived struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Weights   []*WeightLayer `protobuf:"bytes,1,rep,name=weights,proto3" json:"weights,omitempty"`
-	BatchSize int32          `protobuf:"varint,2,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *GradientUpdate) Reset() {
-	*x = GradientUpdate{}
+func (x *ValidationReportReceived) Reset() {
+	*x = ValidationReportReceived{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_proto_msgTypes[16]
+		mi := &file_protos_proto_msgTypes[45]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GradientUpdate) String() string {
+func (x *ValidationReportReceived) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GradientUpdate) ProtoMessage() {}
+func (*ValidationReportReceived) ProtoMessage() {}
 
-func (x *GradientUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_proto_msgTypes[16]
+func (x *ValidationReportReceived) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[45]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -779,51 +3291,53 @@ func (x *GradientUpdate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GradientUpdate.ProtoReflect.Descriptor instead.
-func (*GradientUpdate) Descriptor() ([]byte, []int) {
-	return file_protos_proto_rawDescGZIP(), []int{16}
-}
-
-func (x *GradientUpdate) GetWeights() []*WeightLayer {
-	if x != nil {
-		return x.Weights
-	}
-	return nil
+// Deprecated: Use ValidationReportReceived.ProtoReflect.Descriptor instead.
+func (*ValidationReportReceived) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *GradientUpdate) GetBatchSize() int32 {
+func (x *ValidationReportReceived) GetError() string {
 	if x != nil {
-		return x.BatchSize
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-type WeightLayer struct {
+type ModelVersion struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Weights []float64 `protobuf:"fixed64,1,rep,packed,name=weights,proto3" json:"weights,omitempty"`
-	Biases  []float64 `protobuf:"fixed64,2,rep,packed,name=biases,proto3" json:"biases,omitempty"`
+	Version          uint64               `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Round            uint64               `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Participants     []string             `protobuf:"bytes,3,rep,name=participants,proto3" json:"participants,omitempty"`
+	ParticipantCount uint32               `protobuf:"varint,4,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
+	CreatedUnix      int64                `protobuf:"varint,5,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	Tags             []string             `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metrics          []*ValidationMetrics `protobuf:"bytes,7,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	ParentVersion    uint64               `protobuf:"varint,8,opt,name=parent_version,json=parentVersion,proto3" json:"parent_version,omitempty"`
+	Note             string               `protobuf:"bytes,9,opt,name=note,proto3" json:"note,omitempty"`
+	// false once the parameters were pruned from the registry
+	Stored bool `protobuf:"varint,10,opt,name=stored,proto3" json:"stored,omitempty"`
 }
 
-func (x *WeightLayer) Reset() {
-	*x = WeightLayer{}
+func (x *ModelVersion) Reset() {
+	*x = ModelVersion{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_proto_msgTypes[17]
+		mi := &file_protos_proto_msgTypes[46]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *WeightLayer) String() string {
+func (x *ModelVersion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WeightLayer) ProtoMessage() {}
+func (*ModelVersion) ProtoMessage() {}
 
-func (x *WeightLayer) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_proto_msgTypes[17]
+func (x *ModelVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[46]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -834,48 +3348,106 @@ func (x *WeightLayer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WeightLayer.ProtoReflect.Descriptor instead.
-func (*WeightLayer) Descriptor() ([]byte, []int) {
-	return file_protos_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use ModelVersion.ProtoReflect.Descriptor instead.
+func (*ModelVersion) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ModelVersion) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ModelVersion) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *ModelVersion) GetParticipants() []string {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+func (x *ModelVersion) GetParticipantCount() uint32 {
+	if x != nil {
+		return x.ParticipantCount
+	}
+	return 0
 }
 
-func (x *WeightLayer) GetWeights() []float64 {
+func (x *ModelVersion) GetCreatedUnix() int64 {
 	if x != nil {
-		return x.Weights
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *ModelVersion) GetTags() []string {
+	if x != nil {
+		return x.Tags
 	}
 	return nil
 }
 
-func (x *WeightLayer) GetBiases() []float64 {
+func (x *ModelVersion) GetMetrics() []*ValidationMetrics {
 	if x != nil {
-		return x.Biases
+		return x.Metrics
 	}
 	return nil
 }
 
-type TrainingFinished struct {
+func (x *ModelVersion) GetParentVersion() uint64 {
+	if x != nil {
+		return x.ParentVersion
+	}
+	return 0
+}
+
+func (x *ModelVersion) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *ModelVersion) GetStored() bool {
+	if x != nil {
+		return x.Stored
+	}
+	return false
+}
+
+type RegistryIndex struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Versions []*ModelVersion `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
 }
 
-func (x *TrainingFinished) Reset() {
-	*x = TrainingFinished{}
+func (x *RegistryIndex) Reset() {
+	*x = RegistryIndex{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_proto_msgTypes[18]
+		mi := &file_protos_proto_msgTypes[47]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *TrainingFinished) String() string {
+func (x *RegistryIndex) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TrainingFinished) ProtoMessage() {}
+func (*RegistryIndex) ProtoMessage() {}
 
-func (x *TrainingFinished) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_proto_msgTypes[18]
+func (x *RegistryIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[47]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -886,34 +3458,43 @@ func (x *TrainingFinished) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TrainingFinished.ProtoReflect.Descriptor instead.
-func (*TrainingFinished) Descriptor() ([]byte, []int) {
-	return file_protos_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use RegistryIndex.ProtoReflect.Descriptor instead.
+func (*RegistryIndex) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{47}
 }
 
-type PreprocessingFinished struct {
+func (x *RegistryIndex) GetVersions() []*ModelVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type ResolveModelVersion struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	ModelVersion string `protobuf:"bytes,1,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
 }
 
-func (x *PreprocessingFinished) Reset() {
-	*x = PreprocessingFinished{}
+func (x *ResolveModelVersion) Reset() {
+	*x = ResolveModelVersion{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_proto_msgTypes[19]
+		mi := &file_protos_proto_msgTypes[48]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PreprocessingFinished) String() string {
+func (x *ResolveModelVersion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PreprocessingFinished) ProtoMessage() {}
+func (*ResolveModelVersion) ProtoMessage() {}
 
-func (x *PreprocessingFinished) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_proto_msgTypes[19]
+func (x *ResolveModelVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[48]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -924,34 +3505,45 @@ func (x *PreprocessingFinished) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PreprocessingFinished.ProtoReflect.Descriptor instead.
-func (*PreprocessingFinished) Descriptor() ([]byte, []int) {
-	return file_protos_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ResolveModelVersion.ProtoReflect.Descriptor instead.
+func (*ResolveModelVersion) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{48}
 }
 
-type EvaluationFinished struct {
+func (x *ResolveModelVersion) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+type ResolvedModelVersion struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Version uint64   `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Tags    []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Error   string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *EvaluationFinished) Reset() {
-	*x = EvaluationFinished{}
+func (x *ResolvedModelVersion) Reset() {
+	*x = ResolvedModelVersion{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_proto_msgTypes[20]
+		mi := &file_protos_proto_msgTypes[49]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *EvaluationFinished) String() string {
+func (x *ResolvedModelVersion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EvaluationFinished) ProtoMessage() {}
+func (*ResolvedModelVersion) ProtoMessage() {}
 
-func (x *EvaluationFinished) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_proto_msgTypes[20]
+func (x *ResolvedModelVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_proto_msgTypes[49]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -962,9 +3554,30 @@ func (x *EvaluationFinished) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EvaluationFinished.ProtoReflect.Descriptor instead.
-func (*EvaluationFinished) Descriptor() ([]byte, []int) {
-	return file_protos_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use ResolvedModelVersion.ProtoReflect.Descriptor instead.
+func (*ResolvedModelVersion) Descriptor() ([]byte, []int) {
+	return file_protos_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ResolvedModelVersion) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ResolvedModelVersion) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ResolvedModelVersion) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
 var File_protos_proto protoreflect.FileDescriptor
@@ -972,74 +3585,472 @@ var File_protos_proto protoreflect.FileDescriptor
 var file_protos_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08,
 	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x1a, 0x0b, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x53, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a,
-	0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x06, 0x6c,
-	0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x33, 0x0a, 0x0a, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72,
-	0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x73, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x52, 0x0a,
-	0x68, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x23, 0x0a, 0x09, 0x48, 0x69,
-	0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22,
-	0x6e, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x44, 0x61, 0x74, 0x61, 0x53,
-	0x65, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x08, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73,
-	0x2e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x08, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12,
-	0x2e, 0x0a, 0x0a, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88, 0x01, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x06,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x33, 0x0a, 0x0a, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x67,
+	0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x52,
+	0x0a, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x33, 0x0a, 0x08, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0xae, 0x01, 0x0a, 0x0d, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x74, 0x69, 0x65, 0x6e,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x74, 0x69,
+	0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x63, 0x71, 0x75, 0x69, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0f, 0x61, 0x63, 0x71, 0x75, 0x69, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x76, 0x69, 0x65, 0x77, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x73, 0x65, 0x78, 0x12, 0x19, 0x0a, 0x08, 0x61, 0x67, 0x65, 0x5f, 0x62, 0x61,
+	0x6e, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x67, 0x65, 0x42, 0x61, 0x6e,
+	0x64, 0x22, 0x23, 0x0a, 0x09, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01, 0x52, 0x06,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x77, 0x0a, 0x09, 0x44, 0x61, 0x74, 0x61, 0x43, 0x68,
+	0x75, 0x6e, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x22, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x44,
-	0x61, 0x74, 0x61, 0x52, 0x0a, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
-	0x44, 0x0a, 0x12, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74,
-	0x61, 0x53, 0x65, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x0a, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x73, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x0a, 0x56, 0x61, 0x6c, 0x69, 0x64,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x19, 0x0a, 0x17, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74,
-	0x65, 0x50, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67,
-	0x22, 0x1b, 0x0a, 0x19, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x50, 0x72, 0x65, 0x70,
-	0x72, 0x6f, 0x63, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x4f, 0x0a,
-	0x15, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x72,
-	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x36, 0x0a, 0x10, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0a, 0x2e, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x50, 0x49, 0x44, 0x52, 0x10, 0x41, 0x67,
-	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x4c,
-	0x0a, 0x12, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x10, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74,
+	0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f,
+	0x63, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x22,
+	0x85, 0x01, 0x0a, 0x0d, 0x44, 0x61, 0x74, 0x61, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x6e,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x65, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x65, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x61, 0x72, 0x64, 0x69,
+	0x7a, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x6e, 0x64,
+	0x61, 0x72, 0x64, 0x69, 0x7a, 0x65, 0x64, 0x22, 0x8e, 0x01, 0x0a, 0x0c, 0x44, 0x61, 0x74, 0x61,
+	0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x66,
+	0x6f, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x69, 0x6e, 0x67, 0x46, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x65,
+	0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x66, 0x6f, 0x6c, 0x64, 0x65, 0x72,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x65, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x46, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x22, 0xb2, 0x01, 0x0a, 0x0c, 0x44, 0x61, 0x74,
+	0x61, 0x73, 0x65, 0x74, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x12,
+	0x1e, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73,
+	0x65, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x04, 0x74, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x72, 0x61, 0x74,
+	0x69, 0x66, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x74, 0x72, 0x61, 0x74,
+	0x69, 0x66, 0x79, 0x12, 0x28, 0x0a, 0x10, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x62, 0x79, 0x5f,
+	0x70, 0x61, 0x74, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x42, 0x79, 0x50, 0x61, 0x74, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x8e, 0x05,
+	0x0a, 0x0d, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72,
+	0x6f, 0x6f, 0x74, 0x12, 0x30, 0x0a, 0x07, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e,
+	0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x52, 0x07, 0x63, 0x6c,
+	0x61, 0x73, 0x73, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x05, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e,
+	0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x52, 0x05, 0x73, 0x70,
+	0x6c, 0x69, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f,
+	0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x74, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x12, 0x2f, 0x0a, 0x13, 0x65, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d,
+	0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x65,
+	0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x25, 0x0a, 0x03, 0x6c, 0x62,
+	0x70, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x2e, 0x4c, 0x42, 0x50, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x03, 0x6c, 0x62,
+	0x70, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x24, 0x0a,
+	0x0d, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x46, 0x0a, 0x10, 0x64, 0x65, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x2e, 0x44, 0x65, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x64, 0x65, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x0c, 0x61, 0x75, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x41, 0x75, 0x67, 0x6d, 0x65, 0x6e, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x61, 0x75, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x18, 0x0f, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x12, 0x33, 0x0a, 0x15, 0x66, 0x65, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x5f, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x4e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x8c,
+	0x02, 0x0a, 0x0c, 0x41, 0x75, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63,
+	0x61, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x6c, 0x69, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x04, 0x66, 0x6c, 0x69, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68,
+	0x74, 0x6e, 0x65, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x72, 0x69,
+	0x67, 0x68, 0x74, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x69, 0x73, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x6e, 0x6f, 0x69, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x70,
+	0x69, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x63, 0x6f, 0x70, 0x69, 0x65,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x06, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65,
+	0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x22, 0xa2, 0x02,
+	0x0a, 0x10, 0x44, 0x65, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x61, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x61, 0x6c, 0x74,
+	0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x73, 0x68, 0x69,
+	0x66, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x44, 0x61, 0x74,
+	0x65, 0x53, 0x68, 0x69, 0x66, 0x74, 0x12, 0x41, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x2e, 0x44, 0x65, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3b, 0x0a, 0x1a, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x5f, 0x62, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x5f, 0x61, 0x6e, 0x6e,
+	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x17, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x42, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x49, 0x6e, 0x41, 0x6e, 0x6e, 0x6f,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x3a, 0x0a, 0x0c, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xc7, 0x01, 0x0a, 0x09, 0x4c, 0x42, 0x50, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x65, 0x69, 0x67,
+	0x68, 0x62, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6e, 0x65, 0x69,
+	0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x67, 0x72, 0x69, 0x64, 0x5f, 0x78,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x67, 0x72, 0x69, 0x64, 0x58, 0x12, 0x15, 0x0a,
+	0x06, 0x67, 0x72, 0x69, 0x64, 0x5f, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x67,
+	0x72, 0x69, 0x64, 0x59, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61,
+	0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72,
+	0x61, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x4c, 0x0a, 0x17,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x50, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x54,
+	0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x31, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x73,
+	0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x22, 0x4e, 0x0a, 0x19, 0x41, 0x63,
+	0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x50, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x45, 0x76, 0x61,
+	0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x73,
+	0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x22, 0x82, 0x01, 0x0a, 0x15, 0x41,
+	0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x12, 0x36, 0x0a, 0x10, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74,
 	0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
 	0x2e, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x50, 0x49, 0x44, 0x52, 0x10, 0x41, 0x67, 0x67, 0x72,
-	0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x12, 0x0a, 0x10,
-	0x47, 0x65, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x6f, 0x72,
-	0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x57, 0x65, 0x69,
-	0x67, 0x68, 0x74, 0x73, 0x22, 0x66, 0x0a, 0x0d, 0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x57, 0x65,
-	0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x06, 0x62, 0x69, 0x61, 0x73, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73,
-	0x2e, 0x42, 0x69, 0x61, 0x73, 0x65, 0x73, 0x52, 0x06, 0x62, 0x69, 0x61, 0x73, 0x65, 0x73, 0x12,
-	0x2b, 0x0a, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x11, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x57, 0x65, 0x69, 0x67,
-	0x68, 0x74, 0x73, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x22, 0x2b, 0x0a, 0x11,
-	0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x54, 0x65, 0x73,
-	0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x22, 0x1c, 0x0a, 0x06, 0x42, 0x69, 0x61,
-	0x73, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x01, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x1d, 0x0a, 0x07, 0x57, 0x65, 0x69, 0x67, 0x68,
-	0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x15, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x41, 0x67, 0x67,
+	0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x31, 0x0a, 0x07,
+	0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x22,
+	0xa4, 0x01, 0x0a, 0x12, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x45, 0x76, 0x61, 0x6c,
+	0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x10, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x50, 0x49, 0x44, 0x52, 0x10, 0x41, 0x67,
+	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x23,
+	0x0a, 0x0d, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e,
+	0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x64,
+	0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x54, 0x72, 0x61,
+	0x69, 0x6e, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x7a, 0x0a, 0x10, 0x47, 0x65,
+	0x74, 0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x41,
+	0x0a, 0x12, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x5f, 0x65, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x11,
+	0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xfb, 0x01, 0x0a, 0x0d, 0x47, 0x6c, 0x6f, 0x62, 0x61,
+	0x6c, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61,
+	0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74,
+	0x65, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x5f, 0x61, 0x67,
+	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x11, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x4c, 0x0a, 0x12, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x6d,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d,
+	0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x11, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x32, 0x0a, 0x15, 0x73, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x13, 0x73, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x4d, 0x73, 0x22, 0xa9, 0x01, 0x0a, 0x06, 0x54, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x61, 0x70, 0x65, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x05, 0x73, 0x68, 0x61, 0x70, 0x65, 0x12, 0x25, 0x0a, 0x05, 0x64, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x2e, 0x44, 0x54, 0x79, 0x70, 0x65, 0x52, 0x05, 0x64, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x03, 0x28, 0x01, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x3a, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x56, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64,
+	0x22, 0xf4, 0x01, 0x0a, 0x0f, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x07, 0x74, 0x65, 0x6e,
+	0x73, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x54, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x52, 0x07, 0x74, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x73, 0x12, 0x43, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65,
+	0x72, 0x73, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x65, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x2e,
+	0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x12, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1e,
+	0x0a, 0x0b, 0x74, 0x6f, 0x70, 0x5f, 0x6b, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x09, 0x74, 0x6f, 0x70, 0x4b, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x22, 0xd5,
+	0x01, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x56, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x2e, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73,
+	0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x61, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x61, 0x63,
+	0x6b, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x03, 0x6d, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x78, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x03, 0x6d, 0x61, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63,
+	0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x70, 0x61, 0x72, 0x73, 0x65, 0x5f, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x02, 0x52, 0x0c, 0x73, 0x70, 0x61, 0x72, 0x73, 0x65,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x15, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x41, 0x67, 0x67,
 	0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x14, 0x0a,
 	0x12, 0x47, 0x65, 0x74, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63,
-	0x74, 0x6f, 0x72, 0x22, 0x60, 0x0a, 0x0e, 0x47, 0x72, 0x61, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x55,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x73, 0x2e, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x07, 0x77,
-	0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f,
-	0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62, 0x61, 0x74, 0x63,
-	0x68, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x3f, 0x0a, 0x0b, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x4c,
-	0x61, 0x79, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x01, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x16,
-	0x0a, 0x06, 0x62, 0x69, 0x61, 0x73, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x06,
-	0x62, 0x69, 0x61, 0x73, 0x65, 0x73, 0x22, 0x12, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69,
-	0x6e, 0x67, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x22, 0x17, 0x0a, 0x15, 0x50, 0x72,
-	0x65, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x46, 0x69, 0x6e, 0x69, 0x73,
-	0x68, 0x65, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x42, 0x10, 0x5a, 0x0e, 0x61, 0x67, 0x65,
-	0x6e, 0x74, 0x73, 0x6b, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x74, 0x6f, 0x72, 0x22, 0x84, 0x01, 0x0a, 0x0e, 0x47, 0x72, 0x61, 0x64, 0x69, 0x65, 0x6e, 0x74,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x67, 0x72, 0x61, 0x64, 0x69, 0x65,
+	0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x52, 0x09, 0x67, 0x72, 0x61, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x09, 0x62, 0x61, 0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x68, 0x6f, 0x73, 0x70, 0x69, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x68, 0x6f, 0x73, 0x70, 0x69, 0x74, 0x61, 0x6c, 0x22, 0xb3, 0x01, 0x0a, 0x11, 0x46,
+	0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73,
+	0x12, 0x19, 0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x68,
+	0x6f, 0x73, 0x70, 0x69, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68,
+	0x6f, 0x73, 0x70, 0x69, 0x74, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x73, 0x75, 0x6d, 0x18, 0x04, 0x20, 0x03, 0x28, 0x01, 0x52, 0x03, 0x73, 0x75, 0x6d, 0x12,
+	0x1f, 0x0a, 0x0b, 0x73, 0x75, 0x6d, 0x5f, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x01, 0x52, 0x0a, 0x73, 0x75, 0x6d, 0x53, 0x71, 0x75, 0x61, 0x72, 0x65, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x22, 0x45, 0x0a, 0x19, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x69,
+	0x73, 0x74, 0x69, 0x63, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x65, 0x61, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x01, 0x52, 0x04, 0x6d, 0x65, 0x61, 0x6e, 0x22, 0x12, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x22, 0x17, 0x0a, 0x15, 0x50,
+	0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x46, 0x69, 0x6e, 0x69,
+	0x73, 0x68, 0x65, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x22, 0xb7, 0x01, 0x0a, 0x13, 0x53,
+	0x65, 0x63, 0x41, 0x67, 0x67, 0x41, 0x64, 0x76, 0x65, 0x72, 0x74, 0x69, 0x73, 0x65, 0x4b, 0x65,
+	0x79, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12,
+	0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x73, 0x6b, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x6d, 0x61, 0x73, 0x6b, 0x50, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0e, 0x73, 0x68, 0x61, 0x72, 0x65, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x12, 0x31, 0x0a, 0x07, 0x70, 0x75, 0x72, 0x70, 0x6f, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x53, 0x65,
+	0x63, 0x41, 0x67, 0x67, 0x50, 0x75, 0x72, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x07, 0x70, 0x75, 0x72,
+	0x70, 0x6f, 0x73, 0x65, 0x22, 0x98, 0x01, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x37, 0x0a, 0x07,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x41,
+	0x64, 0x76, 0x65, 0x72, 0x74, 0x69, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x07, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x65, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x22,
+	0x70, 0x0a, 0x14, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74,
+	0x65, 0x64, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74,
+	0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x6e,
+	0x6f, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63,
+	0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78,
+	0x74, 0x22, 0x83, 0x01, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x53, 0x68, 0x61, 0x72,
+	0x65, 0x50, 0x61, 0x69, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x65, 0x6c,
+	0x66, 0x5f, 0x73, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0d, 0x73, 0x65, 0x6c, 0x66, 0x53, 0x65, 0x65, 0x64, 0x53, 0x68, 0x61, 0x72,
+	0x65, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x73, 0x6b, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x6d, 0x61, 0x73, 0x6b, 0x4b,
+	0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x22, 0x7c, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x41, 0x67,
+	0x67, 0x53, 0x68, 0x61, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x36, 0x0a,
+	0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x45,
+	0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x06, 0x73,
+	0x68, 0x61, 0x72, 0x65, 0x73, 0x22, 0x7b, 0x0a, 0x11, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x12, 0x36, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x53, 0x65, 0x63, 0x41,
+	0x67, 0x67, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x53, 0x68, 0x61, 0x72, 0x65,
+	0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x62, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x61, 0x62, 0x6f, 0x72, 0x74,
+	0x65, 0x64, 0x22, 0x5d, 0x0a, 0x14, 0x4d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x47, 0x72, 0x61, 0x64,
+	0x69, 0x65, 0x6e, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x04, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x7d, 0x0a, 0x13, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x55, 0x6e, 0x6d, 0x61, 0x73,
+	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x09, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x6f, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x07, 0x64,
+	0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x65, 0x64,
+	0x22, 0x3f, 0x0a, 0x11, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74,
+	0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x22, 0x53, 0x0a, 0x11, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x52, 0x6f, 0x75, 0x6e, 0x64,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x65, 0x61, 0x6e, 0x18, 0x03, 0x20, 0x03, 0x28, 0x01,
+	0x52, 0x04, 0x6d, 0x65, 0x61, 0x6e, 0x22, 0xd3, 0x01, 0x0a, 0x12, 0x53, 0x65, 0x63, 0x41, 0x67,
+	0x67, 0x55, 0x6e, 0x6d, 0x61, 0x73, 0x6b, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64,
+	0x12, 0x45, 0x0a, 0x10, 0x73, 0x65, 0x6c, 0x66, 0x5f, 0x73, 0x65, 0x65, 0x64, 0x5f, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x53, 0x65, 0x63, 0x41, 0x67, 0x67, 0x53, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x0e, 0x73, 0x65, 0x6c, 0x66, 0x53, 0x65, 0x65,
+	0x64, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x43, 0x0a, 0x0f, 0x6d, 0x61, 0x73, 0x6b, 0x5f,
+	0x6b, 0x65, 0x79, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x53, 0x65, 0x63, 0x41,
+	0x67, 0x67, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x0d, 0x6d,
+	0x61, 0x73, 0x6b, 0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x22, 0x82, 0x01, 0x0a,
+	0x0e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x65, 0x74,
+	0x61, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x6f, 0x6d, 0x65, 0x6e, 0x74, 0x75, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x6d, 0x6f, 0x6d, 0x65, 0x6e, 0x74, 0x75, 0x6d, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x73, 0x74,
+	0x65, 0x70, 0x73, 0x12, 0x2c, 0x0a, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73,
+	0x2e, 0x54, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x52, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74,
+	0x79, 0x22, 0xdc, 0x01, 0x0a, 0x0a, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x12, 0x39, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52,
+	0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x36, 0x0a, 0x09, 0x6f,
+	0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69,
+	0x7a, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09, 0x6f, 0x70, 0x74, 0x69, 0x6d, 0x69,
+	0x7a, 0x65, 0x72, 0x12, 0x38, 0x0a, 0x18, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x5f, 0x61, 0x67,
+	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x16, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x41, 0x67, 0x67,
+	0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x21, 0x0a,
+	0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x55, 0x6e, 0x69, 0x78,
+	0x22, 0xa1, 0x01, 0x0a, 0x11, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x70, 0x69, 0x74,
+	0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x70, 0x69, 0x74,
+	0x61, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x31, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x66, 0x31, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x72,
+	0x65, 0x63, 0x61, 0x6c, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x78,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64,
+	0x55, 0x6e, 0x69, 0x78, 0x22, 0x6e, 0x0a, 0x10, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x6f, 0x64, 0x65,
+	0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0c, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x35, 0x0a,
+	0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x07, 0x6d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x22, 0x30, 0x0a, 0x18, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xd0, 0x02, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x65, 0x6c,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70,
+	0x61, 0x6e, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x55, 0x6e, 0x69, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12,
+	0x35, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x56, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x07, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d,
+	0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x22, 0x43, 0x0a, 0x0d, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x32, 0x0a, 0x08, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x3a,
+	0x0a, 0x13, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x5a, 0x0a, 0x14, 0x52, 0x65,
+	0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x2a, 0x2d, 0x0a, 0x05, 0x44, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x11, 0x0a, 0x0d, 0x44, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x36, 0x34,
+	0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x41,
+	0x54, 0x33, 0x32, 0x10, 0x01, 0x2a, 0x81, 0x01, 0x0a, 0x08, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x4e,
+	0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e,
+	0x47, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x31, 0x36, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x45,
+	0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x51, 0x55, 0x41, 0x4e, 0x54, 0x49, 0x5a, 0x45,
+	0x44, 0x5f, 0x38, 0x42, 0x49, 0x54, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x45, 0x4e, 0x43, 0x4f,
+	0x44, 0x49, 0x4e, 0x47, 0x5f, 0x51, 0x55, 0x41, 0x4e, 0x54, 0x49, 0x5a, 0x45, 0x44, 0x5f, 0x34,
+	0x42, 0x49, 0x54, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e,
+	0x47, 0x5f, 0x54, 0x4f, 0x50, 0x5f, 0x4b, 0x10, 0x04, 0x2a, 0x75, 0x0a, 0x0d, 0x53, 0x65, 0x63,
+	0x41, 0x67, 0x67, 0x50, 0x75, 0x72, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x53, 0x45,
+	0x43, 0x41, 0x47, 0x47, 0x5f, 0x50, 0x55, 0x52, 0x50, 0x4f, 0x53, 0x45, 0x5f, 0x47, 0x52, 0x41,
+	0x44, 0x49, 0x45, 0x4e, 0x54, 0x53, 0x10, 0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x53, 0x45, 0x43, 0x41,
+	0x47, 0x47, 0x5f, 0x50, 0x55, 0x52, 0x50, 0x4f, 0x53, 0x45, 0x5f, 0x46, 0x45, 0x41, 0x54, 0x55,
+	0x52, 0x45, 0x5f, 0x53, 0x55, 0x4d, 0x53, 0x10, 0x01, 0x12, 0x25, 0x0a, 0x21, 0x53, 0x45, 0x43,
+	0x41, 0x47, 0x47, 0x5f, 0x50, 0x55, 0x52, 0x50, 0x4f, 0x53, 0x45, 0x5f, 0x46, 0x45, 0x41, 0x54,
+	0x55, 0x52, 0x45, 0x5f, 0x44, 0x45, 0x56, 0x49, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x53, 0x10, 0x02,
+	0x42, 0x10, 0x5a, 0x0e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x6b, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1054,46 +4065,109 @@ func file_protos_proto_rawDescGZIP() []byte {
 	return file_protos_proto_rawDescData
 }
 
-var file_protos_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_protos_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_protos_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
 var file_protos_proto_goTypes = []interface{}{
-	(*Data)(nil),                      // 0: messages.Data
-	(*Histogram)(nil),                 // 1: messages.Histogram
-	(*TrainingDataSets)(nil),          // 2: messages.TrainingDataSets
-	(*EvaluationDataSets)(nil),        // 3: messages.EvaluationDataSets
-	(*ActivatePreprocTraining)(nil),   // 4: messages.ActivatePreprocTraining
-	(*ActivatePreprocEvaluation)(nil), // 5: messages.ActivatePreprocEvaluation
-	(*ActivateLocalTraining)(nil),     // 6: messages.ActivateLocalTraining
-	(*ActivateEvaluation)(nil),        // 7: messages.ActivateEvaluation
-	(*GetTrainingActor)(nil),          // 8: messages.GetTrainingActor
-	(*GetGlobalWeights)(nil),          // 9: messages.GetGlobalWeights
-	(*GlobalWeights)(nil),             // 10: messages.GlobalWeights
-	(*GlobalWeightsTest)(nil),         // 11: messages.GlobalWeightsTest
-	(*Biases)(nil),                    // 12: messages.Biases
-	(*Weights)(nil),                   // 13: messages.Weights
-	(*GetAggregationActor)(nil),       // 14: messages.GetAggregationActor
-	(*GetEvaluationActor)(nil),        // 15: messages.GetEvaluationActor
-	(*GradientUpdate)(nil),            // 16: messages.GradientUpdate
-	(*WeightLayer)(nil),               // 17: messages.WeightLayer
-	(*TrainingFinished)(nil),          // 18: messages.TrainingFinished
-	(*PreprocessingFinished)(nil),     // 19: messages.PreprocessingFinished
-	(*EvaluationFinished)(nil),        // 20: messages.EvaluationFinished
-	(*actor.PID)(nil),                 // 21: actor.PID
+	(DType)(0),                        // 0: messages.DType
+	(Encoding)(0),                     // 1: messages.Encoding
+	(SecAggPurpose)(0),                // 2: messages.SecAggPurpose
+	(*Data)(nil),                      // 3: messages.Data
+	(*ImageMetadata)(nil),             // 4: messages.ImageMetadata
+	(*Histogram)(nil),                 // 5: messages.Histogram
+	(*DataChunk)(nil),                 // 6: messages.DataChunk
+	(*DataStreamEnd)(nil),             // 7: messages.DataStreamEnd
+	(*DatasetClass)(nil),              // 8: messages.DatasetClass
+	(*DatasetSplit)(nil),              // 9: messages.DatasetSplit
+	(*DatasetConfig)(nil),             // 10: messages.DatasetConfig
+	(*Augmentation)(nil),              // 11: messages.Augmentation
+	(*Deidentification)(nil),          // 12: messages.Deidentification
+	(*LBPParams)(nil),                 // 13: messages.LBPParams
+	(*ActivatePreprocTraining)(nil),   // 14: messages.ActivatePreprocTraining
+	(*ActivatePreprocEvaluation)(nil), // 15: messages.ActivatePreprocEvaluation
+	(*ActivateLocalTraining)(nil),     // 16: messages.ActivateLocalTraining
+	(*ActivateEvaluation)(nil),        // 17: messages.ActivateEvaluation
+	(*GetTrainingActor)(nil),          // 18: messages.GetTrainingActor
+	(*GetGlobalWeights)(nil),          // 19: messages.GetGlobalWeights
+	(*GlobalWeights)(nil),             // 20: messages.GlobalWeights
+	(*Tensor)(nil),                    // 21: messages.Tensor
+	(*ModelParameters)(nil),           // 22: messages.ModelParameters
+	(*CompressionSettings)(nil),       // 23: messages.CompressionSettings
+	(*CompressedVector)(nil),          // 24: messages.CompressedVector
+	(*GetAggregationActor)(nil),       // 25: messages.GetAggregationActor
+	(*GetEvaluationActor)(nil),        // 26: messages.GetEvaluationActor
+	(*GradientUpdate)(nil),            // 27: messages.GradientUpdate
+	(*FeatureStatistics)(nil),         // 28: messages.FeatureStatistics
+	(*FeatureStatisticsReceived)(nil), // 29: messages.FeatureStatisticsReceived
+	(*TrainingFinished)(nil),          // 30: messages.TrainingFinished
+	(*PreprocessingFinished)(nil),     // 31: messages.PreprocessingFinished
+	(*EvaluationFinished)(nil),        // 32: messages.EvaluationFinished
+	(*SecAggAdvertiseKeys)(nil),       // 33: messages.SecAggAdvertiseKeys
+	(*SecAggRoundKeys)(nil),           // 34: messages.SecAggRoundKeys
+	(*SecAggEncryptedShare)(nil),      // 35: messages.SecAggEncryptedShare
+	(*SecAggSharePair)(nil),           // 36: messages.SecAggSharePair
+	(*SecAggShareKeys)(nil),           // 37: messages.SecAggShareKeys
+	(*SecAggRoundShares)(nil),         // 38: messages.SecAggRoundShares
+	(*MaskedGradientUpdate)(nil),      // 39: messages.MaskedGradientUpdate
+	(*SecAggUnmaskRequest)(nil),       // 40: messages.SecAggUnmaskRequest
+	(*SecAggSecretShare)(nil),         // 41: messages.SecAggSecretShare
+	(*SecAggRoundResult)(nil),         // 42: messages.SecAggRoundResult
+	(*SecAggUnmaskShares)(nil),        // 43: messages.SecAggUnmaskShares
+	(*OptimizerState)(nil),            // 44: messages.OptimizerState
+	(*Checkpoint)(nil),                // 45: messages.Checkpoint
+	(*ValidationMetrics)(nil),         // 46: messages.ValidationMetrics
+	(*ValidationReport)(nil),          // 47: messages.ValidationReport
+	(*ValidationReportReceived)(nil),  // 48: messages.ValidationReportReceived
+	(*ModelVersion)(nil),              // 49: messages.ModelVersion
+	(*RegistryIndex)(nil),             // 50: messages.RegistryIndex
+	(*ResolveModelVersion)(nil),       // 51: messages.ResolveModelVersion
+	(*ResolvedModelVersion)(nil),      // 52: messages.ResolvedModelVersion
+	nil,                               // 53: messages.Deidentification.ActionsEntry
+	nil,                               // 54: messages.ModelParameters.MetadataEntry
+	(*actor.PID)(nil),                 // 55: actor.PID
 }
 var file_protos_proto_depIdxs = []int32{
-	1,  // 0: messages.Data.histograms:type_name -> messages.Histogram
-	0,  // 1: messages.TrainingDataSets.Training:type_name -> messages.Data
-	0,  // 2: messages.TrainingDataSets.Validation:type_name -> messages.Data
-	0,  // 3: messages.EvaluationDataSets.Validation:type_name -> messages.Data
-	21, // 4: messages.ActivateLocalTraining.AggregationActor:type_name -> actor.PID
-	21, // 5: messages.ActivateEvaluation.AggregationActor:type_name -> actor.PID
-	12, // 6: messages.GlobalWeights.biases:type_name -> messages.Biases
-	13, // 7: messages.GlobalWeights.weights:type_name -> messages.Weights
-	17, // 8: messages.GradientUpdate.weights:type_name -> messages.WeightLayer
-	9,  // [9:9] is the sub-list for method output_type
-	9,  // [9:9] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	5,  // 0: messages.Data.histograms:type_name -> messages.Histogram
+	4,  // 1: messages.Data.metadata:type_name -> messages.ImageMetadata
+	3,  // 2: messages.DataChunk.data:type_name -> messages.Data
+	8,  // 3: messages.DatasetConfig.classes:type_name -> messages.DatasetClass
+	9,  // 4: messages.DatasetConfig.split:type_name -> messages.DatasetSplit
+	13, // 5: messages.DatasetConfig.lbp:type_name -> messages.LBPParams
+	12, // 6: messages.DatasetConfig.deidentification:type_name -> messages.Deidentification
+	11, // 7: messages.DatasetConfig.augmentation:type_name -> messages.Augmentation
+	53, // 8: messages.Deidentification.actions:type_name -> messages.Deidentification.ActionsEntry
+	10, // 9: messages.ActivatePreprocTraining.dataset:type_name -> messages.DatasetConfig
+	10, // 10: messages.ActivatePreprocEvaluation.dataset:type_name -> messages.DatasetConfig
+	55, // 11: messages.ActivateLocalTraining.AggregationActor:type_name -> actor.PID
+	10, // 12: messages.ActivateLocalTraining.dataset:type_name -> messages.DatasetConfig
+	55, // 13: messages.ActivateEvaluation.AggregationActor:type_name -> actor.PID
+	10, // 14: messages.ActivateEvaluation.dataset:type_name -> messages.DatasetConfig
+	1,  // 15: messages.GetGlobalWeights.accepted_encodings:type_name -> messages.Encoding
+	22, // 16: messages.GlobalWeights.parameters:type_name -> messages.ModelParameters
+	23, // 17: messages.GlobalWeights.update_compression:type_name -> messages.CompressionSettings
+	0,  // 18: messages.Tensor.dtype:type_name -> messages.DType
+	24, // 19: messages.Tensor.compressed:type_name -> messages.CompressedVector
+	21, // 20: messages.ModelParameters.tensors:type_name -> messages.Tensor
+	54, // 21: messages.ModelParameters.metadata:type_name -> messages.ModelParameters.MetadataEntry
+	1,  // 22: messages.CompressionSettings.encoding:type_name -> messages.Encoding
+	1,  // 23: messages.CompressedVector.encoding:type_name -> messages.Encoding
+	22, // 24: messages.GradientUpdate.gradients:type_name -> messages.ModelParameters
+	2,  // 25: messages.SecAggAdvertiseKeys.purpose:type_name -> messages.SecAggPurpose
+	33, // 26: messages.SecAggRoundKeys.clients:type_name -> messages.SecAggAdvertiseKeys
+	35, // 27: messages.SecAggShareKeys.shares:type_name -> messages.SecAggEncryptedShare
+	35, // 28: messages.SecAggRoundShares.shares:type_name -> messages.SecAggEncryptedShare
+	41, // 29: messages.SecAggUnmaskShares.self_seed_shares:type_name -> messages.SecAggSecretShare
+	41, // 30: messages.SecAggUnmaskShares.mask_key_shares:type_name -> messages.SecAggSecretShare
+	21, // 31: messages.OptimizerState.velocity:type_name -> messages.Tensor
+	22, // 32: messages.Checkpoint.parameters:type_name -> messages.ModelParameters
+	44, // 33: messages.Checkpoint.optimizer:type_name -> messages.OptimizerState
+	46, // 34: messages.ValidationReport.metrics:type_name -> messages.ValidationMetrics
+	46, // 35: messages.ModelVersion.metrics:type_name -> messages.ValidationMetrics
+	49, // 36: messages.RegistryIndex.versions:type_name -> messages.ModelVersion
+	37, // [37:37] is the sub-list for method output_type
+	37, // [37:37] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_protos_proto_init() }
@@ -1114,8 +4188,92 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Histogram); i {
+		file_protos_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImageMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Histogram); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataStreamEnd); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatasetClass); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatasetSplit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatasetConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Augmentation); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1126,8 +4284,8 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TrainingDataSets); i {
+		file_protos_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Deidentification); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1138,8 +4296,8 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EvaluationDataSets); i {
+		file_protos_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LBPParams); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1150,7 +4308,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ActivatePreprocTraining); i {
 			case 0:
 				return &v.state
@@ -1162,7 +4320,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ActivatePreprocEvaluation); i {
 			case 0:
 				return &v.state
@@ -1174,7 +4332,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ActivateLocalTraining); i {
 			case 0:
 				return &v.state
@@ -1186,7 +4344,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ActivateEvaluation); i {
 			case 0:
 				return &v.state
@@ -1198,7 +4356,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetTrainingActor); i {
 			case 0:
 				return &v.state
@@ -1210,7 +4368,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetGlobalWeights); i {
 			case 0:
 				return &v.state
@@ -1222,7 +4380,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GlobalWeights); i {
 			case 0:
 				return &v.state
@@ -1234,8 +4392,8 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GlobalWeightsTest); i {
+		file_protos_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Tensor); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1246,8 +4404,8 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Biases); i {
+		file_protos_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModelParameters); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1258,8 +4416,8 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Weights); i {
+		file_protos_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompressionSettings); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1270,7 +4428,19 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompressedVector); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetAggregationActor); i {
 			case 0:
 				return &v.state
@@ -1282,7 +4452,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetEvaluationActor); i {
 			case 0:
 				return &v.state
@@ -1294,7 +4464,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GradientUpdate); i {
 			case 0:
 				return &v.state
@@ -1306,8 +4476,8 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WeightLayer); i {
+		file_protos_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeatureStatistics); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1318,7 +4488,19 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeatureStatisticsReceived); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TrainingFinished); i {
 			case 0:
 				return &v.state
@@ -1330,7 +4512,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PreprocessingFinished); i {
 			case 0:
 				return &v.state
@@ -1342,7 +4524,7 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
-		file_protos_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+		file_protos_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*EvaluationFinished); i {
 			case 0:
 				return &v.state
@@ -1354,19 +4536,260 @@ func file_protos_proto_init() {
 				return nil
 			}
 		}
+		file_protos_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggAdvertiseKeys); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggRoundKeys); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggEncryptedShare); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggSharePair); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggShareKeys); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggRoundShares); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MaskedGradientUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggUnmaskRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggSecretShare); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggRoundResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecAggUnmaskShares); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OptimizerState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Checkpoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidationMetrics); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidationReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidationReportReceived); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModelVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[47].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistryIndex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[48].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResolveModelVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_proto_msgTypes[49].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResolvedModelVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protos_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   21,
+			NumEnums:      3,
+			NumMessages:   52,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_protos_proto_goTypes,
 		DependencyIndexes: file_protos_proto_depIdxs,
+		EnumInfos:         file_protos_proto_enumTypes,
 		MessageInfos:      file_protos_proto_msgTypes,
 	}.Build()
 	File_protos_proto = out.File
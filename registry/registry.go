@@ -0,0 +1,279 @@
+package registry
+
+import (
+	"agentske/fileutil"
+	messages "agentske/proto"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	TagCandidate  = "candidate"
+	TagProduction = "production"
+)
+
+const indexFile = "index.pb"
+
+// Registry stores every version of the global model with its metadata in a directory.
+// The metadata of all versions is kept in an index, the parameters of each version in
+// its own file. Parameters of old untagged versions without metrics are pruned.
+type Registry struct {
+	dir          string
+	keepUntagged int
+	index        *messages.RegistryIndex
+	// Position of every version in the index
+	positions map[uint64]int
+}
+
+// Open loads the registry index from the directory, creating an empty registry if there is none.
+func Open(dir string, keepUntagged int) (*Registry, error) {
+	if keepUntagged < 1 {
+		return nil, errors.New("At least one untagged version has to be kept")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	r := &Registry{
+		dir:          dir,
+		keepUntagged: keepUntagged,
+		index:        &messages.RegistryIndex{},
+		positions:    make(map[uint64]int),
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(data, r.index); err != nil {
+		return nil, fmt.Errorf("Could not read the registry index: %w", err)
+	}
+	for i, v := range r.index.Versions {
+		r.positions[v.Version] = i
+	}
+	return r, nil
+}
+
+// Register stores the parameters of a new model version with its metadata.
+func (r *Registry) Register(params *messages.ModelParameters, meta *messages.ModelVersion) error {
+	if _, exists := r.positions[params.Version]; exists {
+		return fmt.Errorf("Model version %d is already registered", params.Version)
+	}
+	data, err := proto.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(r.versionPath(params.Version), data); err != nil {
+		return err
+	}
+
+	meta.Version = params.Version
+	meta.Stored = true
+	r.positions[meta.Version] = len(r.index.Versions)
+	r.index.Versions = append(r.index.Versions, meta)
+	r.prune()
+	return r.save()
+}
+
+// Get loads the parameters of a stored version.
+func (r *Registry) Get(version uint64) (*messages.ModelParameters, error) {
+	meta, ok := r.Info(version)
+	if !ok {
+		return nil, fmt.Errorf("Model version %d is not registered", version)
+	}
+	if !meta.Stored {
+		return nil, fmt.Errorf("Parameters of model version %d were pruned", version)
+	}
+	data, err := os.ReadFile(r.versionPath(version))
+	if err != nil {
+		return nil, err
+	}
+	params := &messages.ModelParameters{}
+	if err := proto.Unmarshal(data, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// Info returns the metadata of a version.
+func (r *Registry) Info(version uint64) (*messages.ModelVersion, bool) {
+	i, ok := r.positions[version]
+	if !ok {
+		return nil, false
+	}
+	return r.index.Versions[i], true
+}
+
+// Latest returns the highest registered version.
+func (r *Registry) Latest() (uint64, bool) {
+	if len(r.index.Versions) == 0 {
+		return 0, false
+	}
+	return r.index.Versions[len(r.index.Versions)-1].Version, true
+}
+
+// Versions returns the metadata of every registered version, oldest first.
+func (r *Registry) Versions() []*messages.ModelVersion {
+	return r.index.Versions
+}
+
+// Resolve turns a version number or a tag into a registered version.
+func (r *Registry) Resolve(selector string) (uint64, error) {
+	if version, err := strconv.ParseUint(selector, 10, 64); err == nil {
+		if _, ok := r.Info(version); !ok {
+			return 0, fmt.Errorf("Model version %d is not registered", version)
+		}
+		return version, nil
+	}
+	for _, v := range r.index.Versions {
+		if hasTag(v, selector) {
+			return v.Version, nil
+		}
+	}
+	return 0, fmt.Errorf("No model version is tagged %q", selector)
+}
+
+// Tag moves the tag to the version. A tag is on at most one version at a time.
+func (r *Registry) Tag(version uint64, tag string) error {
+	if tag != TagCandidate && tag != TagProduction {
+		return fmt.Errorf("Unknown tag %q, expected %s or %s", tag, TagCandidate, TagProduction)
+	}
+	meta, ok := r.Info(version)
+	if !ok {
+		return fmt.Errorf("Model version %d is not registered", version)
+	}
+	if !meta.Stored {
+		return fmt.Errorf("Parameters of model version %d were pruned", version)
+	}
+	for _, v := range r.index.Versions {
+		v.Tags = removeTag(v.Tags, tag)
+	}
+	meta.Tags = append(meta.Tags, tag)
+	return r.save()
+}
+
+// AddMetrics records validation metrics a hospital reported for a version.
+func (r *Registry) AddMetrics(version uint64, metrics *messages.ValidationMetrics) error {
+	meta, ok := r.Info(version)
+	if !ok {
+		return fmt.Errorf("Model version %d is not registered", version)
+	}
+	meta.Metrics = append(meta.Metrics, metrics)
+	return r.save()
+}
+
+// MeanF1 returns the sample weighted mean F1 score reported for the version.
+func MeanF1(meta *messages.ModelVersion) (float64, bool) {
+	var sum, samples float64
+	for _, m := range meta.Metrics {
+		sum += m.F1Score * float64(m.Samples)
+		samples += float64(m.Samples)
+	}
+	if samples == 0 {
+		return 0, false
+	}
+	return sum / samples, true
+}
+
+// Baseline returns the version new versions are compared against: the production
+// version if it has metrics, otherwise the stored version with the best mean F1 score.
+func (r *Registry) Baseline(exclude uint64) (*messages.ModelVersion, float64, bool) {
+	for _, v := range r.index.Versions {
+		if hasTag(v, TagProduction) && v.Version != exclude {
+			if f1, ok := MeanF1(v); ok {
+				return v, f1, true
+			}
+		}
+	}
+	var best *messages.ModelVersion
+	var bestF1 float64
+	for _, v := range r.index.Versions {
+		if v.Version == exclude || !v.Stored {
+			continue
+		}
+		if f1, ok := MeanF1(v); ok && (best == nil || f1 > bestF1) {
+			best, bestF1 = v, f1
+		}
+	}
+	return best, bestF1, best != nil
+}
+
+func (r *Registry) versionPath(version uint64) string {
+	return filepath.Join(r.dir, fmt.Sprintf("version-%020d.pb", version))
+}
+
+func (r *Registry) save() error {
+	data, err := proto.Marshal(r.index)
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(filepath.Join(r.dir, indexFile), data)
+}
+
+// prune removes the parameters of the oldest untagged versions without metrics beyond the retention limit.
+func (r *Registry) prune() {
+	var prunable []*messages.ModelVersion
+	for _, v := range r.index.Versions {
+		if v.Stored && len(v.Tags) == 0 && len(v.Metrics) == 0 {
+			prunable = append(prunable, v)
+		}
+	}
+	for i := 0; i < len(prunable)-r.keepUntagged; i++ {
+		if err := os.Remove(r.versionPath(prunable[i].Version)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Could not prune model version %d: %v\n", prunable[i].Version, err)
+			continue
+		}
+		prunable[i].Stored = false
+	}
+}
+
+// Describe formats the metadata of a version on one line.
+func Describe(v *messages.ModelVersion) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "version %d round %d", v.Version, v.Round)
+	if len(v.Tags) > 0 {
+		fmt.Fprintf(&b, " [%s]", strings.Join(v.Tags, ", "))
+	}
+	fmt.Fprintf(&b, " participants %d", v.ParticipantCount)
+	if len(v.Participants) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(v.Participants, ", "))
+	}
+	if f1, ok := MeanF1(v); ok {
+		fmt.Fprintf(&b, " f1 %0.01f%% from %d reports", f1, len(v.Metrics))
+	}
+	if !v.Stored {
+		b.WriteString(" pruned")
+	}
+	if v.Note != "" {
+		fmt.Fprintf(&b, " - %s", v.Note)
+	}
+	return b.String()
+}
+
+func hasTag(v *messages.ModelVersion, tag string) bool {
+	for _, t := range v.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func removeTag(tags []string, tag string) []string {
+	var kept []string
+	for _, t := range tags {
+		if t != tag {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
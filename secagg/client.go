@@ -0,0 +1,295 @@
+package secagg
+
+import (
+	messages "agentske/proto"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Client holds the secrets of one participant for a single secure aggregation round.
+// The keys are never reused, so a new Client has to be created for every round.
+type Client struct {
+	id        uint32
+	round     uint64
+	threshold int
+	maskKey   *ecdh.PrivateKey
+	shareKey  *ecdh.PrivateKey
+	selfSeed  []byte
+
+	// Public keys of everyone who advertised keys in this round, by client id
+	peers map[uint32]*messages.SecAggAdvertiseKeys
+	// Shares this client holds for the other participants (and itself), by owner id
+	held map[uint32]*messages.SecAggSharePair
+	// Whether the unmasking shares were already revealed
+	revealed bool
+}
+
+// NewClient generates fresh key pairs and a self-mask seed.
+func NewClient() (*Client, error) {
+	maskKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shareKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	selfSeed := make([]byte, SeedSize)
+	if _, err := rand.Read(selfSeed); err != nil {
+		return nil, err
+	}
+	return &Client{
+		maskKey:  maskKey,
+		shareKey: shareKey,
+		selfSeed: selfSeed,
+	}, nil
+}
+
+// AdvertiseKeys returns the public keys the client sends to the aggregator to join a round.
+func (c *Client) AdvertiseKeys() *messages.SecAggAdvertiseKeys {
+	return &messages.SecAggAdvertiseKeys{
+		MaskPublicKey:  c.maskKey.PublicKey().Bytes(),
+		SharePublicKey: c.shareKey.PublicKey().Bytes(),
+	}
+}
+
+// ShareKeys validates the participant list, secret shares the self-mask seed and the mask key
+// among all participants and encrypts every share for its recipient.
+func (c *Client) ShareKeys(keys *messages.SecAggRoundKeys) (*messages.SecAggShareKeys, error) {
+	if keys.Aborted {
+		return nil, errors.New("Secure aggregation round was aborted by the aggregator")
+	}
+	if keys.Threshold < 2 {
+		return nil, errors.New("Secure aggregation threshold must be at least 2")
+	}
+	if len(keys.Clients) < int(keys.Threshold) {
+		return nil, errors.New("Not enough secure aggregation participants for the threshold")
+	}
+
+	c.round = keys.Round
+	c.threshold = int(keys.Threshold)
+	c.peers = make(map[uint32]*messages.SecAggAdvertiseKeys)
+	var ids []uint32
+	ownKey := c.maskKey.PublicKey().Bytes()
+	for _, peer := range keys.Clients {
+		if peer.ClientId == 0 {
+			return nil, errors.New("Secure aggregation client id can not be zero")
+		}
+		if _, exists := c.peers[peer.ClientId]; exists {
+			return nil, fmt.Errorf("Duplicate secure aggregation client id %d", peer.ClientId)
+		}
+		for _, other := range c.peers {
+			if bytes.Equal(other.MaskPublicKey, peer.MaskPublicKey) || bytes.Equal(other.SharePublicKey, peer.SharePublicKey) {
+				return nil, errors.New("Duplicate secure aggregation public keys")
+			}
+		}
+		if bytes.Equal(peer.MaskPublicKey, ownKey) {
+			c.id = peer.ClientId
+		}
+		c.peers[peer.ClientId] = peer
+		ids = append(ids, peer.ClientId)
+	}
+	if c.id == 0 {
+		return nil, errors.New("Own keys are missing from the secure aggregation round")
+	}
+
+	seedShares, err := SplitSecret(c.selfSeed, ids, c.threshold)
+	if err != nil {
+		return nil, err
+	}
+	keyShares, err := SplitSecret(c.maskKey.Bytes(), ids, c.threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &messages.SecAggShareKeys{Round: c.round, ClientId: c.id}
+	c.held = make(map[uint32]*messages.SecAggSharePair)
+	for _, to := range ids {
+		pair := &messages.SecAggSharePair{
+			From:          c.id,
+			To:            to,
+			SelfSeedShare: seedShares[to],
+			MaskKeyShare:  keyShares[to],
+		}
+		if to == c.id {
+			c.held[c.id] = pair
+			continue
+		}
+		share, err := c.encryptShare(pair)
+		if err != nil {
+			return nil, err
+		}
+		msg.Shares = append(msg.Shares, share)
+	}
+
+	return msg, nil
+}
+
+// ReceiveShares decrypts the shares other participants addressed to this client.
+// The senders form the set of clients whose masks are applied in MaskInput.
+func (c *Client) ReceiveShares(msg *messages.SecAggRoundShares) error {
+	if msg.Aborted {
+		return errors.New("Secure aggregation round was aborted by the aggregator")
+	}
+	if msg.Round != c.round {
+		return errors.New("Secure aggregation shares belong to another round")
+	}
+	for _, share := range msg.Shares {
+		if share.To != c.id {
+			return errors.New("Received a secure aggregation share addressed to another client")
+		}
+		if _, exists := c.held[share.From]; exists {
+			return fmt.Errorf("Duplicate secure aggregation share from client %d", share.From)
+		}
+		pair, err := c.decryptShare(share)
+		if err != nil {
+			return err
+		}
+		c.held[share.From] = pair
+	}
+	if len(c.held) < c.threshold {
+		return errors.New("Not enough secure aggregation participants shared their keys")
+	}
+	return nil
+}
+
+// MaskInput encodes the values in fixed point and hides them behind the self mask and
+// the pairwise masks, which cancel out when all inputs of the round are summed.
+func (c *Client) MaskInput(values []float64) (*messages.MaskedGradientUpdate, error) {
+	if c.held == nil {
+		return nil, errors.New("Keys have to be shared before masking the input")
+	}
+	masked := EncodeFixedPoint(values)
+	addMask(masked, expandMask(c.selfSeed, len(masked)), false)
+
+	for peerID := range c.held {
+		if peerID == c.id {
+			continue
+		}
+		seed, err := deriveKey(c.maskKey, c.peers[peerID].MaskPublicKey, "mask", c.round)
+		if err != nil {
+			return nil, err
+		}
+		addMask(masked, expandMask(seed, len(masked)), peerID < c.id)
+	}
+
+	return &messages.MaskedGradientUpdate{Round: c.round, ClientId: c.id, Data: masked}, nil
+}
+
+// Unmask reveals the self-seed shares of the surviving clients and the mask key shares of the
+// dropped ones. Both shares of the same client are never revealed, otherwise its input would leak.
+func (c *Client) Unmask(req *messages.SecAggUnmaskRequest) (*messages.SecAggUnmaskShares, error) {
+	if req.Aborted {
+		return nil, errors.New("Secure aggregation round was aborted by the aggregator")
+	}
+	if req.Round != c.round {
+		return nil, errors.New("Unmask request belongs to another round")
+	}
+	if c.revealed {
+		return nil, errors.New("Unmasking shares were already revealed in this round")
+	}
+	if len(req.Survivors) < c.threshold {
+		return nil, errors.New("Not enough surviving clients to safely unmask")
+	}
+
+	seen := make(map[uint32]bool)
+	msg := &messages.SecAggUnmaskShares{Round: c.round, ClientId: c.id}
+	for _, id := range req.Survivors {
+		pair, ok := c.held[id]
+		if !ok || seen[id] {
+			return nil, fmt.Errorf("Unexpected surviving client %d in the unmask request", id)
+		}
+		seen[id] = true
+		msg.SelfSeedShares = append(msg.SelfSeedShares, &messages.SecAggSecretShare{Owner: id, Value: pair.SelfSeedShare})
+	}
+	if !seen[c.id] {
+		return nil, errors.New("Client is missing from the survivors of the unmask request")
+	}
+	for _, id := range req.Dropped {
+		pair, ok := c.held[id]
+		if !ok || seen[id] {
+			return nil, fmt.Errorf("Unexpected dropped client %d in the unmask request", id)
+		}
+		seen[id] = true
+		msg.MaskKeyShares = append(msg.MaskKeyShares, &messages.SecAggSecretShare{Owner: id, Value: pair.MaskKeyShare})
+	}
+
+	c.revealed = true
+	return msg, nil
+}
+
+// shareAAD binds an encrypted share to its round, sender and recipient.
+func (c *Client) shareAAD(from, to uint32) []byte {
+	aad := make([]byte, 16)
+	binary.BigEndian.PutUint64(aad[0:], c.round)
+	binary.BigEndian.PutUint32(aad[8:], from)
+	binary.BigEndian.PutUint32(aad[12:], to)
+	return aad
+}
+
+func (c *Client) shareCipher(peerID uint32) (cipher.AEAD, error) {
+	peer, ok := c.peers[peerID]
+	if !ok {
+		return nil, fmt.Errorf("Unknown secure aggregation client %d", peerID)
+	}
+	key, err := deriveKey(c.shareKey, peer.SharePublicKey, "share", c.round)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (c *Client) encryptShare(pair *messages.SecAggSharePair) (*messages.SecAggEncryptedShare, error) {
+	aead, err := c.shareCipher(pair.To)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := proto.Marshal(pair)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &messages.SecAggEncryptedShare{
+		From:       pair.From,
+		To:         pair.To,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, c.shareAAD(pair.From, pair.To)),
+	}, nil
+}
+
+func (c *Client) decryptShare(share *messages.SecAggEncryptedShare) (*messages.SecAggSharePair, error) {
+	aead, err := c.shareCipher(share.From)
+	if err != nil {
+		return nil, err
+	}
+	if len(share.Nonce) != aead.NonceSize() {
+		return nil, errors.New("Invalid secure aggregation share nonce")
+	}
+	plaintext, err := aead.Open(nil, share.Nonce, share.Ciphertext, c.shareAAD(share.From, share.To))
+	if err != nil {
+		return nil, fmt.Errorf("Could not decrypt the share from client %d: %w", share.From, err)
+	}
+	pair := &messages.SecAggSharePair{}
+	if err := proto.Unmarshal(plaintext, pair); err != nil {
+		return nil, err
+	}
+	if pair.From != share.From || pair.To != c.id {
+		return nil, errors.New("Secure aggregation share does not match its envelope")
+	}
+	return pair, nil
+}
@@ -0,0 +1,83 @@
+package secagg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+// SeedSize is the size of the self-mask seeds and of the derived pairwise keys.
+const SeedSize = 32
+
+// fixedPointScale is the number of fractional bits used when encoding gradients.
+// Masked values are summed modulo 2^64, so the sum over all clients must fit in an int64.
+const fixedPointScale = 1 << 24
+
+// deriveKey hashes an ECDH shared secret together with a purpose label and the round.
+func deriveKey(private *ecdh.PrivateKey, peerPublic []byte, purpose string, round uint64) ([]byte, error) {
+	public, err := ecdh.X25519().NewPublicKey(peerPublic)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := private.ECDH(public)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(shared)
+	h.Write([]byte(purpose))
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round)
+	h.Write(r[:])
+	return h.Sum(nil), nil
+}
+
+// expandMask stretches the seed into a mask vector of the given length using AES-CTR.
+func expandMask(seed []byte, length int) []uint64 {
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		// The seed is always SeedSize bytes, which is a valid AES-256 key
+		panic(err)
+	}
+	stream := cipher.NewCTR(block, make([]byte, aes.BlockSize))
+	buf := make([]byte, 8*length)
+	stream.XORKeyStream(buf, buf)
+
+	mask := make([]uint64, length)
+	for i := range mask {
+		mask[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	return mask
+}
+
+// addMask adds (or subtracts) the mask to the vector in place, modulo 2^64.
+func addMask(vector, mask []uint64, subtract bool) {
+	for i := range vector {
+		if subtract {
+			vector[i] -= mask[i]
+		} else {
+			vector[i] += mask[i]
+		}
+	}
+}
+
+// EncodeFixedPoint converts the values into fixed point integers in the ring of 2^64.
+func EncodeFixedPoint(values []float64) []uint64 {
+	encoded := make([]uint64, len(values))
+	for i, v := range values {
+		encoded[i] = uint64(int64(math.Round(v * fixedPointScale)))
+	}
+	return encoded
+}
+
+// DecodeFixedPoint converts a (summed) fixed point vector back into floats.
+func DecodeFixedPoint(values []uint64) []float64 {
+	decoded := make([]float64, len(values))
+	for i, v := range values {
+		decoded[i] = float64(int64(v)) / fixedPointScale
+	}
+	return decoded
+}
@@ -0,0 +1,143 @@
+package secagg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFixedPointRoundTrip(t *testing.T) {
+	values := []float64{0, 1.5, -2.25, 1e-6, -12345.678, 3e5}
+	decoded := DecodeFixedPoint(EncodeFixedPoint(values))
+	for i, v := range values {
+		if math.Abs(decoded[i]-v) > 1.0/fixedPointScale {
+			t.Errorf("%g decoded as %g", v, decoded[i])
+		}
+	}
+}
+
+func TestMasksCancel(t *testing.T) {
+	seed := make([]byte, SeedSize)
+	seed[0] = 1
+	vector := EncodeFixedPoint([]float64{1, -2, 3.5})
+	masked := append([]uint64(nil), vector...)
+	addMask(masked, expandMask(seed, len(masked)), false)
+	addMask(masked, expandMask(seed, len(masked)), true)
+	for i := range vector {
+		if masked[i] != vector[i] {
+			t.Fatalf("Adding and subtracting the same mask changed entry %d", i)
+		}
+	}
+}
+
+// runRound takes the clients through a round. Clients from dropBeforeShare on never send their
+// shares, clients from dropBeforeMask on never send their masked input, and the round's sum is
+// returned.
+func runRound(t *testing.T, inputs [][]float64, threshold, dropBeforeShare, dropBeforeMask int) []float64 {
+	t.Helper()
+	round := NewRound(1, threshold)
+	var clients []*Client
+	for range inputs {
+		client, err := NewClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := round.Join(client.AdvertiseKeys()); err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, client)
+	}
+	keys, err := round.CloseAdvertise()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]uint32, len(clients))
+	for i, client := range clients[:dropBeforeShare] {
+		shares, err := client.ShareKeys(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := round.AddShares(shares); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = shares.ClientId
+	}
+	if err := round.CloseShare(); err != nil {
+		t.Fatal(err)
+	}
+	for i, client := range clients[:dropBeforeShare] {
+		if err := client.ReceiveShares(round.SharesFor(ids[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, client := range clients[:dropBeforeMask] {
+		masked, err := client.MaskInput(inputs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := round.AddMaskedInput(masked); err != nil {
+			t.Fatal(err)
+		}
+	}
+	request, err := round.CloseMasked()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range clients[:dropBeforeMask] {
+		shares, err := client.Unmask(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := round.AddUnmaskShares(shares); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum, err := round.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return DecodeFixedPoint(sum)
+}
+
+func checkSum(t *testing.T, sum []float64, inputs [][]float64) {
+	t.Helper()
+	for j := range sum {
+		var expected float64
+		for _, input := range inputs {
+			expected += input[j]
+		}
+		if math.Abs(sum[j]-expected) > float64(len(inputs))/fixedPointScale {
+			t.Errorf("Entry %d of the sum is %g, expected %g", j, sum[j], expected)
+		}
+	}
+}
+
+func TestRoundSumWithoutDropouts(t *testing.T) {
+	inputs := [][]float64{{1.5, -2, 3}, {0.25, 4, -1}, {100, -100, 0.5}}
+	checkSum(t, runRound(t, inputs, 2, 3, 3), inputs)
+}
+
+func TestRoundMasksCancelWithDropouts(t *testing.T) {
+	inputs := [][]float64{{1.5, -2, 3}, {0.25, 4, -1}, {100, -100, 0.5}, {7, 7, 7}, {-3, 2, 1}}
+	// The fifth client drops before sharing its keys and the fourth after, before masking its
+	// input: the pairwise masks shared with the fourth are reconstructed and removed
+	sum := runRound(t, inputs, 3, 4, 3)
+	checkSum(t, sum, inputs[:3])
+}
+
+func TestRoundFailsBelowThreshold(t *testing.T) {
+	round := NewRound(1, 3)
+	for i := 0; i < 2; i++ {
+		client, err := NewClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := round.Join(client.AdvertiseKeys()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := round.CloseAdvertise(); err == nil {
+		t.Fatal("A round with fewer clients than the threshold was not aborted")
+	}
+}
@@ -0,0 +1,308 @@
+package secagg
+
+import (
+	messages "agentske/proto"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Phase is the step of the secure aggregation protocol a round is currently in.
+type Phase int
+
+const (
+	PhaseAdvertise Phase = iota
+	PhaseShare
+	PhaseMasked
+	PhaseUnmask
+	PhaseDone
+)
+
+// Round is the aggregator side of one secure aggregation round. It only ever sees
+// public keys, encrypted shares and masked inputs, and can recover nothing but their sum.
+type Round struct {
+	ID        uint64
+	Threshold int
+	phase     Phase
+
+	// Every client that advertised keys, by id
+	clients map[uint32]*messages.SecAggAdvertiseKeys
+	// Encrypted shares sent by each client, by sender id
+	shares map[uint32][]*messages.SecAggEncryptedShare
+	// Masked inputs, by client id
+	masked map[uint32][]uint64
+	// Clients whose masked input arrived and clients that dropped out after sharing keys
+	survivors []uint32
+	dropped   []uint32
+	// Unmasking shares, by responding client id
+	unmask map[uint32]*messages.SecAggUnmaskShares
+}
+
+func NewRound(id uint64, threshold int) *Round {
+	return &Round{
+		ID:        id,
+		Threshold: threshold,
+		phase:     PhaseAdvertise,
+		clients:   make(map[uint32]*messages.SecAggAdvertiseKeys),
+		shares:    make(map[uint32][]*messages.SecAggEncryptedShare),
+		masked:    make(map[uint32][]uint64),
+		unmask:    make(map[uint32]*messages.SecAggUnmaskShares),
+	}
+}
+
+func (r *Round) Phase() Phase {
+	return r.phase
+}
+
+// Advertised returns the number of clients that joined the round.
+func (r *Round) Advertised() int {
+	return len(r.clients)
+}
+
+// Shared returns the number of clients that sent their encrypted shares.
+func (r *Round) Shared() int {
+	return len(r.shares)
+}
+
+// Masked returns the number of masked inputs received.
+func (r *Round) Masked() int {
+	return len(r.masked)
+}
+
+// Unmasked returns the number of unmasking responses received.
+func (r *Round) Unmasked() int {
+	return len(r.unmask)
+}
+
+// Join registers the client keys and assigns the client its id in the round.
+func (r *Round) Join(keys *messages.SecAggAdvertiseKeys) (uint32, error) {
+	if r.phase != PhaseAdvertise {
+		return 0, errors.New("Secure aggregation round is no longer accepting clients")
+	}
+	id := uint32(len(r.clients) + 1)
+	r.clients[id] = &messages.SecAggAdvertiseKeys{
+		ClientId:       id,
+		MaskPublicKey:  keys.MaskPublicKey,
+		SharePublicKey: keys.SharePublicKey,
+	}
+	return id, nil
+}
+
+// CloseAdvertise ends the advertise phase and returns the public keys of all participants.
+func (r *Round) CloseAdvertise() (*messages.SecAggRoundKeys, error) {
+	if r.phase != PhaseAdvertise {
+		return nil, errors.New("Secure aggregation round is not in the advertise phase")
+	}
+	if len(r.clients) < r.Threshold {
+		r.phase = PhaseDone
+		return nil, fmt.Errorf("Only %d clients joined secure aggregation round %d, at least %d are required", len(r.clients), r.ID, r.Threshold)
+	}
+	r.phase = PhaseShare
+
+	msg := &messages.SecAggRoundKeys{Round: r.ID, Threshold: uint32(r.Threshold)}
+	for _, id := range sortedIDs(r.clients) {
+		msg.Clients = append(msg.Clients, r.clients[id])
+	}
+	return msg, nil
+}
+
+// AddShares stores the encrypted shares a client created for the other participants.
+func (r *Round) AddShares(msg *messages.SecAggShareKeys) error {
+	if r.phase != PhaseShare {
+		return errors.New("Secure aggregation round is not in the share phase")
+	}
+	if _, ok := r.clients[msg.ClientId]; !ok {
+		return fmt.Errorf("Unknown secure aggregation client %d", msg.ClientId)
+	}
+	if _, exists := r.shares[msg.ClientId]; exists {
+		return fmt.Errorf("Client %d already sent its shares", msg.ClientId)
+	}
+	for _, share := range msg.Shares {
+		if share.From != msg.ClientId {
+			return errors.New("Secure aggregation share sender does not match the client")
+		}
+		if _, ok := r.clients[share.To]; !ok {
+			return fmt.Errorf("Secure aggregation share addressed to unknown client %d", share.To)
+		}
+	}
+	r.shares[msg.ClientId] = msg.Shares
+	return nil
+}
+
+// CloseShare ends the share phase. Only clients that sent their shares take part from now on.
+func (r *Round) CloseShare() error {
+	if r.phase != PhaseShare {
+		return errors.New("Secure aggregation round is not in the share phase")
+	}
+	if len(r.shares) < r.Threshold {
+		r.phase = PhaseDone
+		return fmt.Errorf("Only %d clients shared keys in secure aggregation round %d, at least %d are required", len(r.shares), r.ID, r.Threshold)
+	}
+	r.phase = PhaseMasked
+	return nil
+}
+
+// SharesFor returns the encrypted shares addressed to the client by the other participants.
+func (r *Round) SharesFor(id uint32) *messages.SecAggRoundShares {
+	msg := &messages.SecAggRoundShares{Round: r.ID}
+	for _, from := range sortedIDs(r.shares) {
+		for _, share := range r.shares[from] {
+			if share.To == id {
+				msg.Shares = append(msg.Shares, share)
+			}
+		}
+	}
+	return msg
+}
+
+// Participants returns the ids of the clients that sent their shares.
+func (r *Round) Participants() []uint32 {
+	return sortedIDs(r.shares)
+}
+
+// AddMaskedInput stores the masked input of a client.
+func (r *Round) AddMaskedInput(msg *messages.MaskedGradientUpdate) error {
+	if r.phase != PhaseMasked {
+		return errors.New("Secure aggregation round is not accepting masked inputs")
+	}
+	if _, ok := r.shares[msg.ClientId]; !ok {
+		return fmt.Errorf("Client %d did not share its keys in this round", msg.ClientId)
+	}
+	if _, exists := r.masked[msg.ClientId]; exists {
+		return fmt.Errorf("Client %d already sent its masked input", msg.ClientId)
+	}
+	for _, other := range r.masked {
+		if len(other) != len(msg.Data) {
+			return errors.New("Masked inputs have different sizes")
+		}
+	}
+	r.masked[msg.ClientId] = msg.Data
+	return nil
+}
+
+// CloseMasked ends the masking phase and returns the unmask request for the survivors.
+func (r *Round) CloseMasked() (*messages.SecAggUnmaskRequest, error) {
+	if r.phase != PhaseMasked {
+		return nil, errors.New("Secure aggregation round is not in the masking phase")
+	}
+	if len(r.masked) < r.Threshold {
+		r.phase = PhaseDone
+		return nil, fmt.Errorf("Only %d masked inputs arrived in secure aggregation round %d, at least %d are required", len(r.masked), r.ID, r.Threshold)
+	}
+	r.phase = PhaseUnmask
+
+	r.survivors = sortedIDs(r.masked)
+	for _, id := range sortedIDs(r.shares) {
+		if _, ok := r.masked[id]; !ok {
+			r.dropped = append(r.dropped, id)
+		}
+	}
+	return &messages.SecAggUnmaskRequest{Round: r.ID, Survivors: r.survivors, Dropped: r.dropped}, nil
+}
+
+// Survivors returns the ids of the clients whose masked input is part of the sum.
+func (r *Round) Survivors() []uint32 {
+	return r.survivors
+}
+
+// AddUnmaskShares stores the shares a surviving client revealed.
+func (r *Round) AddUnmaskShares(msg *messages.SecAggUnmaskShares) error {
+	if r.phase != PhaseUnmask {
+		return errors.New("Secure aggregation round is not in the unmask phase")
+	}
+	if _, ok := r.masked[msg.ClientId]; !ok {
+		return fmt.Errorf("Client %d is not a survivor of this round", msg.ClientId)
+	}
+	if _, exists := r.unmask[msg.ClientId]; exists {
+		return fmt.Errorf("Client %d already sent its unmasking shares", msg.ClientId)
+	}
+	r.unmask[msg.ClientId] = msg
+	return nil
+}
+
+// Finish removes the self masks of the survivors and the pairwise masks of the dropped
+// clients, and returns the sum of the survivors' inputs in fixed point.
+func (r *Round) Finish() ([]uint64, error) {
+	if r.phase != PhaseUnmask {
+		return nil, errors.New("Secure aggregation round is not in the unmask phase")
+	}
+	r.phase = PhaseDone
+	if len(r.unmask) < r.Threshold {
+		return nil, fmt.Errorf("Only %d clients sent unmasking shares in secure aggregation round %d, at least %d are required", len(r.unmask), r.ID, r.Threshold)
+	}
+
+	// Collect the revealed shares per owner
+	seedShares := make(map[uint32]map[uint32][]byte)
+	keyShares := make(map[uint32]map[uint32][]byte)
+	for holder, msg := range r.unmask {
+		for _, share := range msg.SelfSeedShares {
+			addShare(seedShares, share, holder)
+		}
+		for _, share := range msg.MaskKeyShares {
+			addShare(keyShares, share, holder)
+		}
+	}
+
+	var length int
+	for _, data := range r.masked {
+		length = len(data)
+	}
+	sum := make([]uint64, length)
+	for _, id := range r.survivors {
+		addMask(sum, r.masked[id], false)
+	}
+
+	// Remove the self masks of the survivors
+	for _, id := range r.survivors {
+		if len(seedShares[id]) < r.Threshold {
+			return nil, fmt.Errorf("Not enough self seed shares to unmask client %d", id)
+		}
+		seed, err := CombineShares(seedShares[id], SeedSize)
+		if err != nil {
+			return nil, err
+		}
+		addMask(sum, expandMask(seed, length), true)
+	}
+
+	// Remove the pairwise masks the survivors share with the dropped clients
+	for _, id := range r.dropped {
+		if len(keyShares[id]) < r.Threshold {
+			return nil, fmt.Errorf("Not enough mask key shares to recover dropped client %d", id)
+		}
+		secret, err := CombineShares(keyShares[id], SeedSize)
+		if err != nil {
+			return nil, err
+		}
+		maskKey, err := ecdh.X25519().NewPrivateKey(secret)
+		if err != nil {
+			return nil, err
+		}
+		for _, survivor := range r.survivors {
+			seed, err := deriveKey(maskKey, r.clients[survivor].MaskPublicKey, "mask", r.ID)
+			if err != nil {
+				return nil, err
+			}
+			// The survivor added the mask if its id is lower and subtracted it otherwise
+			addMask(sum, expandMask(seed, length), survivor < id)
+		}
+	}
+
+	return sum, nil
+}
+
+func addShare(shares map[uint32]map[uint32][]byte, share *messages.SecAggSecretShare, holder uint32) {
+	if shares[share.Owner] == nil {
+		shares[share.Owner] = make(map[uint32][]byte)
+	}
+	shares[share.Owner][holder] = share.Value
+}
+
+func sortedIDs[T any](m map[uint32]T) []uint32 {
+	ids := make([]uint32, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
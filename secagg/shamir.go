@@ -0,0 +1,91 @@
+package secagg
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// prime is the Mersenne prime 2^521 - 1. Every 32-byte secret fits in the field.
+var prime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 521), big.NewInt(1))
+
+// shareSize is the fixed length of an encoded share value.
+const shareSize = 66
+
+// SplitSecret splits the secret into one share per x-coordinate in xs, any threshold
+// of which are enough to reconstruct it. The x-coordinates must be distinct and non-zero.
+func SplitSecret(secret []byte, xs []uint32, threshold int) (map[uint32][]byte, error) {
+	if threshold < 1 || threshold > len(xs) {
+		return nil, errors.New("Invalid threshold passed to the SplitSecret function")
+	}
+
+	// Random polynomial of degree threshold-1 whose constant term is the secret
+	coefficients := make([]*big.Int, threshold)
+	coefficients[0] = new(big.Int).SetBytes(secret)
+	for i := 1; i < threshold; i++ {
+		c, err := rand.Int(rand.Reader, prime)
+		if err != nil {
+			return nil, err
+		}
+		coefficients[i] = c
+	}
+
+	shares := make(map[uint32][]byte, len(xs))
+	for _, x := range xs {
+		if x == 0 {
+			return nil, errors.New("Share x-coordinate can not be zero")
+		}
+		if _, exists := shares[x]; exists {
+			return nil, errors.New("Duplicate share x-coordinate")
+		}
+		// Horner evaluation of the polynomial in x
+		bx := new(big.Int).SetUint64(uint64(x))
+		y := new(big.Int)
+		for i := threshold - 1; i >= 0; i-- {
+			y.Mul(y, bx)
+			y.Add(y, coefficients[i])
+			y.Mod(y, prime)
+		}
+		shares[x] = y.FillBytes(make([]byte, shareSize))
+	}
+
+	return shares, nil
+}
+
+// CombineShares reconstructs a secret of secretSize bytes from the shares using
+// Lagrange interpolation at zero.
+func CombineShares(shares map[uint32][]byte, secretSize int) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("No shares passed to the CombineShares function")
+	}
+
+	secret := new(big.Int)
+	for xi, yi := range shares {
+		if len(yi) != shareSize {
+			return nil, errors.New("Invalid share size")
+		}
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+		for xj := range shares {
+			if xj == xi {
+				continue
+			}
+			// Basis polynomial evaluated at zero: prod xj / (xj - xi)
+			num.Mul(num, new(big.Int).SetUint64(uint64(xj)))
+			num.Mod(num, prime)
+			diff := new(big.Int).Sub(new(big.Int).SetUint64(uint64(xj)), new(big.Int).SetUint64(uint64(xi)))
+			den.Mul(den, diff)
+			den.Mod(den, prime)
+		}
+		term := new(big.Int).SetBytes(yi)
+		term.Mul(term, num)
+		term.Mul(term, new(big.Int).ModInverse(den, prime))
+		secret.Add(secret, term)
+		secret.Mod(secret, prime)
+	}
+
+	if secret.BitLen() > secretSize*8 {
+		return nil, errors.New("Reconstructed secret does not fit, the shares are inconsistent")
+	}
+	return secret.FillBytes(make([]byte, secretSize)), nil
+}
@@ -0,0 +1,79 @@
+package secagg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSplitCombineRoundTrip(t *testing.T) {
+	secret := make([]byte, SeedSize)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+	xs := []uint32{1, 2, 3, 4, 5}
+	shares, err := SplitSecret(secret, xs, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every subset of at least threshold shares reconstructs the secret
+	for subset := 0; subset < 1<<len(xs); subset++ {
+		selected := make(map[uint32][]byte)
+		for i, x := range xs {
+			if subset&(1<<i) != 0 {
+				selected[x] = shares[x]
+			}
+		}
+		if len(selected) < 3 {
+			continue
+		}
+		combined, err := CombineShares(selected, SeedSize)
+		if err != nil {
+			t.Fatalf("shares %v: %v", selected, err)
+		}
+		if !bytes.Equal(combined, secret) {
+			t.Fatalf("shares of subset %b reconstructed %x, expected %x", subset, combined, secret)
+		}
+	}
+}
+
+func TestCombineBelowThreshold(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, SeedSize)
+	shares, err := SplitSecret(secret, []uint32{1, 2, 3}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined, err := CombineShares(map[uint32][]byte{1: shares[1], 2: shares[2]}, SeedSize)
+	if err == nil && bytes.Equal(combined, secret) {
+		t.Fatal("Two shares of a threshold 3 secret reconstructed it")
+	}
+}
+
+func TestSplitSecretRejectsInvalidArguments(t *testing.T) {
+	secret := make([]byte, SeedSize)
+	cases := []struct {
+		name      string
+		xs        []uint32
+		threshold int
+	}{
+		{"zero threshold", []uint32{1, 2}, 0},
+		{"threshold above the shares", []uint32{1, 2}, 3},
+		{"zero x-coordinate", []uint32{0, 1}, 2},
+		{"duplicate x-coordinate", []uint32{1, 1}, 2},
+	}
+	for _, c := range cases {
+		if _, err := SplitSecret(secret, c.xs, c.threshold); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestCombineSharesRejectsInvalidShares(t *testing.T) {
+	if _, err := CombineShares(nil, SeedSize); err == nil {
+		t.Error("No shares: expected an error")
+	}
+	if _, err := CombineShares(map[uint32][]byte{1: {1, 2, 3}}, SeedSize); err == nil {
+		t.Error("Short share: expected an error")
+	}
+}
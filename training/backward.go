@@ -1,20 +1,107 @@
 package training
 
 import (
+	"agentske/compression"
 	messages "agentske/proto"
+	"errors"
+	"fmt"
 	"github.com/asynkron/protoactor-go/actor"
 	"gonum.org/v1/gonum/mat"
+	"log"
 	"time"
 )
 
-func (n *MLP) Backward(x, y mat.Matrix, context actor.Context) {
+// Backward computes the gradients of a mini-batch against the current global weights and sends
+// them to the aggregator. Without secure aggregation the update is not acknowledged.
+func (n *MLP) Backward(x, y mat.Matrix, context actor.Context) error {
+	globalWeights, aggregationActor, err := n.loadGlobalWeights(context)
+	if err != nil {
+		return err
+	}
+	nws, nbs := n.gradients(x, y)
+	N, _ := x.Dims()
+	gradientsMsg := n.gradientUpdate(globalWeights, nws, nbs, N, context)
+
+	if globalWeights.SecureAggregation {
+		return SendSecureUpdate(gradientsMsg, aggregationActor, context)
+	}
+	if err := compression.EncodeGradientUpdate(gradientsMsg, globalWeights.UpdateCompression, n.feedback); err != nil {
+		log.Println("Could not compress the gradient update, sending it uncompressed:", err)
+	}
+	context.Send(aggregationActor, gradientsMsg)
+	return nil
+}
 
+// loadGlobalWeights replaces the network's weights with the current global ones.
+func (n *MLP) loadGlobalWeights(context actor.Context) (*messages.GlobalWeights, *actor.PID, error) {
 	//mozda treba da se poveca vreme odziva
-	aggregationActor, _ := context.RequestFuture(context.Parent(), &messages.GetAggregationActor{}, 5*time.Second).Result()
-	globalWeightsResult, _ := context.RequestFuture(aggregationActor.(*actor.PID), &messages.GetGlobalWeights{}, 20*time.Second).Result()
-	globalWeights := globalWeightsResult.(*messages.GlobalWeights)
-	n.ConvertFromGlobalWeights(globalWeights)
+	aggregationActor, err := context.RequestFuture(context.Parent(), &messages.GetAggregationActor{}, 5*time.Second).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	globalWeights, err := requestGlobalWeights(aggregationActor.(*actor.PID), "", context)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not get the global weights: %w", err)
+	}
+	if err := n.ConvertFromGlobalWeights(globalWeights); err != nil {
+		return nil, nil, fmt.Errorf("Global weights do not fit the network: %w", err)
+	}
+	if n.standardized && n.scaler == nil {
+		return nil, nil, errors.New("The global model has no feature scaler")
+	}
+	return globalWeights, aggregationActor.(*actor.PID), nil
+}
+
+// gradientUpdate wraps the gradients of N images, computed from the global weights, in an update.
+func (n *MLP) gradientUpdate(globalWeights *messages.GlobalWeights, nws, nbs []*mat.Dense, N int, context actor.Context) *messages.GradientUpdate {
+	// gradients are sent against the model version they were computed from
+	gradientsMsg := &messages.GradientUpdate{
+		Gradients: &messages.ModelParameters{
+			ModelId: globalWeights.Parameters.ModelId,
+			Version: globalWeights.Parameters.Version,
+		},
+		BatchSize: int32(N),
+		Hospital:  context.Self().Address,
+	}
+	if n.features != "" {
+		gradientsMsg.Gradients.Metadata = map[string]string{MetadataFeatures: n.features}
+	}
+	for i := range nws {
+		gradientsMsg.Gradients.Tensors = append(gradientsMsg.Gradients.Tensors,
+			TensorFromMatrix(WeightsTensorName(i), nws[i]),
+			TensorFromMatrix(BiasesTensorName(i), nbs[i]))
+	}
+	return gradientsMsg
+}
+
+// secureEpoch sums the gradients of all mini-batches of the epoch against the global weights and
+// sends them in a single secure aggregation round. Every hospital takes part in one round per epoch,
+// however many batches it has, so the rounds reach the threshold.
+func (n *MLP) secureEpoch(samples Samples, globalWeights *messages.GlobalWeights, aggregationActor *actor.PID, context actor.Context) error {
+	var nws, nbs []*mat.Dense
+	r := 0
+	err := batches(samples, n.config.BatchSize, func(x, y mat.Matrix) error {
+		rows, _ := x.Dims()
+		r += rows
+		bws, bbs := n.gradients(x, y)
+		if nws == nil {
+			nws, nbs = bws, bbs
+			return nil
+		}
+		for l := range nws {
+			nws[l].Add(nws[l], bws[l])
+			nbs[l].Add(nbs[l], bbs[l])
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return SendSecureUpdate(n.gradientUpdate(globalWeights, nws, nbs, r, context), aggregationActor, context)
+}
 
+// gradients returns the gradients of the weights and biases of every layer, summed over the batch.
+func (n *MLP) gradients(x, y mat.Matrix) (nws, nbs []*mat.Dense) {
 	// get activations
 	as, zs := n.Forward(x)
 
@@ -23,9 +110,9 @@ func (n *MLP) Backward(x, y mat.Matrix, context actor.Context) {
 	out := as[len(as)-1]
 
 	// error
-	err := new(mat.Dense)
+	diff := new(mat.Dense)
 
-	err.Sub(out, y)
+	diff.Sub(out, y)
 
 	// delta of last layer
 	// delta = (out - y).sigmoidprime(last_z)
@@ -33,12 +120,12 @@ func (n *MLP) Backward(x, y mat.Matrix, context actor.Context) {
 	sp.Apply(ApplySigmoidPrime, z)
 
 	delta := new(mat.Dense)
-	delta.MulElem(err, sp)
+	delta.MulElem(diff, sp)
 
 	// prop delta through layers
 
-	nbs := make([]*mat.Dense, len(n.Weights))
-	nws := make([]*mat.Dense, len(n.Weights))
+	nbs = make([]*mat.Dense, len(n.Weights))
+	nws = make([]*mat.Dense, len(n.Weights))
 
 	nbs[len(nbs)-1] = delta
 
@@ -48,15 +135,6 @@ func (n *MLP) Backward(x, y mat.Matrix, context actor.Context) {
 	nw.Mul(a.T(), delta)
 	nws[len(nws)-1] = nw
 
-	gradientsMsg := &messages.GradientUpdate{
-		Weights: make([]*messages.WeightLayer, len(n.Weights)),
-	}
-	weightLayerLast := &messages.WeightLayer{
-		Weights: nw.RawMatrix().Data,
-		Biases:  SumCols(delta).RawMatrix().Data,
-	}
-	gradientsMsg.Weights[len(n.Weights)-1] = weightLayerLast
-
 	// go back through layers
 	for i := n.numLayers - 2; i > 0; i-- {
 		z := zs[i-1] // -1?
@@ -82,17 +160,11 @@ func (n *MLP) Backward(x, y mat.Matrix, context actor.Context) {
 
 		//r, c := nbs[i-1].Dims()
 		//fmt.Println("Nws: ", r, c)
-
-		weightLayer := &messages.WeightLayer{
-			Weights: nws[i-1].RawMatrix().Data,
-			Biases:  SumCols(nbs[i-1]).RawMatrix().Data,
-		}
-
-		gradientsMsg.Weights[i-1] = weightLayer
 	}
 
-	N, _ := x.Dims()
-	gradientsMsg.BatchSize = int32(N)
-
-	context.Send(aggregationActor.(*actor.PID), gradientsMsg)
+	// bias gradients are summed over the batch and shaped like the biases (y*1)
+	for i := range nbs {
+		nbs[i] = mat.DenseCopyOf(SumCols(nbs[i]).T())
+	}
+	return nws, nbs
 }
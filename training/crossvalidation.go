@@ -0,0 +1,230 @@
+package training
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// CrossValidationConfig describes a local k-fold cross-validation. It trains on the hospital's data
+// only and never contacts the aggregator, so a hospital can assess its local baseline before it joins.
+type CrossValidationConfig struct {
+	Folds int
+	// Seed of the assignment of the images to the folds and of the order they are trained in
+	Seed int64
+	// Standardize the features with the statistics of every fold's training images
+	Standardize bool
+	Training    Config
+}
+
+// DefaultCrossValidationConfig trains every fold like a federated training round.
+func DefaultCrossValidationConfig() CrossValidationConfig {
+	return CrossValidationConfig{
+		Folds: 5,
+		Seed:  42,
+		Training: Config{
+			Epochs:    DefaultEpochs,
+			Eta:       0.3,
+			BatchSize: 32,
+		},
+	}
+}
+
+// FoldMetrics are the metrics of the model trained without a fold, measured on that fold.
+type FoldMetrics struct {
+	Fold              int
+	TrainingSamples   int
+	ValidationSamples int
+	F1Score           float64
+	Recall            float64
+}
+
+// CrossValidationReport holds the metrics of every fold and their mean and standard deviation. Folds
+// whose metrics are undefined, e.g. because no image was predicted positive, are left out of both.
+type CrossValidationReport struct {
+	Folds       []FoldMetrics
+	MeanF1Score float64
+	StdF1Score  float64
+	MeanRecall  float64
+	StdRecall   float64
+}
+
+func (r CrossValidationReport) String() string {
+	var b strings.Builder
+	for _, fold := range r.Folds {
+		fmt.Fprintf(&b, "fold %d: f1_score = %0.01f%%, recall = %0.01f%% (%d training, %d validation images)\n",
+			fold.Fold+1, fold.F1Score, fold.Recall, fold.TrainingSamples, fold.ValidationSamples)
+	}
+	fmt.Fprintf(&b, "f1_score = %0.01f%% ± %0.01f%%\n", r.MeanF1Score, r.StdF1Score)
+	fmt.Fprintf(&b, "recall = %0.01f%% ± %0.01f%%\n", r.MeanRecall, r.StdRecall)
+	return b.String()
+}
+
+// CrossValidate trains a network on all folds but one and evaluates it on the remaining fold, for every
+// fold. The folds are stratified by label, and the images of a group, e.g. a patient, stay in one fold.
+// groups may be nil, and images with an empty group are assigned on their own.
+func CrossValidate(X, Y *mat.Dense, groups []string, config CrossValidationConfig) (CrossValidationReport, error) {
+	var report CrossValidationReport
+	rows, cols := X.Dims()
+	if config.Folds < 2 {
+		return report, errors.New("Cross-validation needs at least 2 folds")
+	}
+	if groups != nil && len(groups) != rows {
+		return report, fmt.Errorf("Cross-validation got %d groups for %d images", len(groups), rows)
+	}
+	folds := assignFolds(Y, groups, config.Folds, config.Seed)
+	rng := rand.New(rand.NewSource(config.Seed))
+	for fold := 0; fold < config.Folds; fold++ {
+		var train, validation []int
+		for i, f := range folds {
+			if f == fold {
+				validation = append(validation, i)
+			} else {
+				train = append(train, i)
+			}
+		}
+		if len(train) == 0 || len(validation) == 0 {
+			return report, fmt.Errorf("Cross-validation of %d images left fold %d empty, use fewer folds", rows, fold+1)
+		}
+		// The images may come sorted by class, which mini-batches must not see
+		rng.Shuffle(len(train), func(i, j int) { train[i], train[j] = train[j], train[i] })
+
+		n := New(config.Training, cols, 15, 8, 1)
+		if config.Standardize {
+			n.SetScaler(FitFeatureScaler(selectRows(X, train)))
+		}
+		n.TrainLocal(selectRows(X, train), selectRows(Y, train), NewOptimizer(config.Training.Eta, 0))
+		f1Score, recall := n.Evaluate(selectRows(X, validation), selectRows(Y, validation))
+		report.Folds = append(report.Folds, FoldMetrics{
+			Fold:              fold,
+			TrainingSamples:   len(train),
+			ValidationSamples: len(validation),
+			F1Score:           f1Score,
+			Recall:            recall,
+		})
+	}
+
+	var f1Scores, recalls []float64
+	for _, fold := range report.Folds {
+		f1Scores = append(f1Scores, fold.F1Score)
+		recalls = append(recalls, fold.Recall)
+	}
+	report.MeanF1Score, report.StdF1Score = meanStd(f1Scores)
+	report.MeanRecall, report.StdRecall = meanStd(recalls)
+	return report, nil
+}
+
+// TrainLocal trains the network in mini-batches with the optimizer, without the aggregator.
+func (n *MLP) TrainLocal(x, y *mat.Dense, optimizer *Optimizer) {
+	r, cx := x.Dims()
+	_, cy := y.Dims()
+	b := n.config.BatchSize
+
+	for e := 1; e < n.config.Epochs+1; e++ {
+		for i := 0; i < r; i += b {
+			k := i + b
+			if k > r {
+				k = r
+			}
+			nws, nbs := n.gradients(x.Slice(i, k, 0, cx), y.Slice(i, k, 0, cy))
+			optimizer.Step(n, nws, nbs, k-i)
+		}
+	}
+}
+
+// assignFolds returns the fold of every image. The groups are shuffled, labeled by their most common
+// label, and every group goes to the fold holding the fewest images of its label so far.
+func assignFolds(Y *mat.Dense, groups []string, folds int, seed int64) []int {
+	rows, _ := Y.Dims()
+	var members [][]int
+	byName := make(map[string]int)
+	for i := 0; i < rows; i++ {
+		name := ""
+		if groups != nil {
+			name = groups[i]
+		}
+		group, ok := byName[name]
+		if !ok || name == "" {
+			group = len(members)
+			members = append(members, nil)
+			byName[name] = group
+		}
+		members[group] = append(members[group], i)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	// Larger groups first, so the small ones even out the folds
+	sort.SliceStable(members, func(i, j int) bool { return len(members[i]) > len(members[j]) })
+
+	counts := make(map[float64][]int)
+	assignment := make([]int, rows)
+	for _, group := range members {
+		labels := make(map[float64]int)
+		for _, i := range group {
+			labels[Y.At(i, 0)]++
+		}
+		var label float64
+		best := 0
+		for l, count := range labels {
+			if count > best || count == best && l < label {
+				label, best = l, count
+			}
+		}
+		if counts[label] == nil {
+			counts[label] = make([]int, folds)
+		}
+		fold := 0
+		for f := range counts[label] {
+			if counts[label][f] < counts[label][fold] {
+				fold = f
+			}
+		}
+		counts[label][fold] += len(group)
+		for _, i := range group {
+			assignment[i] = fold
+		}
+	}
+	return assignment
+}
+
+// selectRows copies the rows into a new matrix.
+func selectRows(m *mat.Dense, rows []int) *mat.Dense {
+	_, cols := m.Dims()
+	selected := mat.NewDense(len(rows), cols, nil)
+	for i, row := range rows {
+		selected.SetRow(i, m.RawRowView(row))
+	}
+	return selected
+}
+
+// meanStd returns the mean and the sample standard deviation of the defined values.
+func meanStd(values []float64) (float64, float64) {
+	var defined []float64
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			defined = append(defined, v)
+		}
+	}
+	if len(defined) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	var sum float64
+	for _, v := range defined {
+		sum += v
+	}
+	mean := sum / float64(len(defined))
+	if len(defined) == 1 {
+		return mean, 0
+	}
+	var squares float64
+	for _, v := range defined {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(defined)-1))
+}
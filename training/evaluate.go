@@ -5,19 +5,41 @@ import (
 	"fmt"
 	"github.com/asynkron/protoactor-go/actor"
 	"gonum.org/v1/gonum/mat"
+	"math"
 	"time"
 )
 
 func (n *MLP) Evaluate(x, y mat.Matrix) (float64, float64) {
+	var c confusion
+	c.add(n.Predict(x), y)
+	return c.scores()
+}
 
-	p := n.Predict(x)
-	N, _ := p.Dims()
+// EvaluateSamples evaluates the network chunk by chunk, and also returns the number of images.
+func (n *MLP) EvaluateSamples(samples Samples) (float64, float64, int, error) {
+	var c confusion
+	err := samples.Chunks(func(x, y *mat.Dense) error {
+		c.add(n.Predict(x), y)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	f1Score, recall := c.scores()
+	return f1Score, recall, c.samples, nil
+}
+
+// confusion counts the predictions by their outcome.
+type confusion struct {
+	truePositive  int
+	falsePositive int
+	falseNegative int
+	samples       int
+}
 
-	var (
-		truePositive  int
-		falsePositive int
-		falseNegative int
-	)
+func (c *confusion) add(p, y mat.Matrix) {
+	N, _ := p.Dims()
+	c.samples += N
 
 	for n := 0; n < N; n++ {
 		ry := mat.Row(nil, n, y)
@@ -27,15 +49,19 @@ func (n *MLP) Evaluate(x, y mat.Matrix) (float64, float64) {
 		predicted := Prediction(rp)
 
 		if predicted == 1.0 && truth == 1.0 {
-			truePositive++
+			c.truePositive++
 		} else if predicted == 1.0 && truth == 0.0 {
-			falsePositive++
+			c.falsePositive++
 		} else if predicted == 0.0 && truth == 1.0 {
-			falseNegative++
+			c.falseNegative++
 		}
 	}
-	precision := float64(truePositive) / float64(truePositive+falsePositive)
-	recall := float64(truePositive) / float64(truePositive+falseNegative)
+}
+
+// scores returns the F1 score and the recall in percent.
+func (c *confusion) scores() (float64, float64) {
+	precision := float64(c.truePositive) / float64(c.truePositive+c.falsePositive)
+	recall := float64(c.truePositive) / float64(c.truePositive+c.falseNegative)
 	if (precision + recall) == 0 {
 		return 0, 0
 	}
@@ -52,22 +78,73 @@ func Prediction(vs []float64) float64 {
 	}
 }
 
-func StartEvaluation(Xv, Yv *mat.Dense, context actor.Context) {
+// reportValidation sends the metrics of the global model version the network was loaded from
+// to the aggregator, which uses them to compare versions and to roll back regressions.
+func reportValidation(n *MLP, f1Score, recall float64, samples int, context actor.Context) {
+	version, ok := n.GlobalVersion()
+	if !ok || math.IsNaN(f1Score) || math.IsNaN(recall) {
+		return
+	}
+	aggregationActor, err := context.RequestFuture(context.Parent(), &messages.GetAggregationActor{}, 5*time.Second).Result()
+	if err != nil {
+		fmt.Println("Could not report the validation metrics:", err)
+		return
+	}
+	report := &messages.ValidationReport{
+		ModelVersion: version,
+		Metrics: &messages.ValidationMetrics{
+			Hospital:     context.Self().Address,
+			F1Score:      f1Score,
+			Recall:       recall,
+			Samples:      int32(samples),
+			ReportedUnix: time.Now().Unix(),
+		},
+	}
+	// Waiting for the answer keeps the hospital from shutting down before the report is delivered
+	result, err := context.RequestFuture(aggregationActor.(*actor.PID), report, 5*time.Second).Result()
+	if err != nil {
+		fmt.Println("Could not report the validation metrics:", err)
+		return
+	}
+	if response, ok := result.(*messages.ValidationReportReceived); ok && response.Error != "" {
+		fmt.Println("The aggregator did not record the validation metrics:", response.Error)
+	}
+}
+
+// StartEvaluation evaluates the given model version, or the current global model when it is empty,
+// on samples with dimension features per image.
+func StartEvaluation(samples Samples, dimension int, features string, standardized bool, modelVersion string, context actor.Context) {
 	con := Config{
-		Epochs:    25,
+		Epochs:    DefaultEpochs,
 		Eta:       0.3,
 		BatchSize: 32,
 	}
-	_, cols := Xv.Dims()
-	arch := []int{cols, 15, 8, 1}
+	arch := []int{dimension, 15, 8, 1}
 	n := New(con, arch...)
+	n.SetFeatures(features, standardized)
 
 	aggregationActor, _ := context.RequestFuture(context.Parent(), &messages.GetAggregationActor{}, 5*time.Second).Result()
-	globalWeightsResult, _ := context.RequestFuture(aggregationActor.(*actor.PID), &messages.GetGlobalWeights{}, 20*time.Second).Result()
-	globalWeights := globalWeightsResult.(*messages.GlobalWeights)
-	n.ConvertFromGlobalWeights(globalWeights)
+	globalWeights, err := requestGlobalWeights(aggregationActor.(*actor.PID), modelVersion, context)
+	if err != nil {
+		fmt.Println("Could not get the global weights:", err)
+		return
+	}
+	if err := n.ConvertFromGlobalWeights(globalWeights); err != nil {
+		fmt.Println("Global weights do not fit the network:", err)
+		return
+	}
+	if n.Standardized() && !n.HasScaler() {
+		fmt.Println("The global model has no feature scaler, train it before evaluating")
+		return
+	}
 
-	f1Score, recall := n.Evaluate(Xv, Yv)
+	f1Score, recall, samplesCount, err := n.EvaluateSamples(samples)
+	if err != nil {
+		fmt.Println("Could not read the evaluation features:", err)
+		return
+	}
+	fmt.Printf("model_version = %d\n", globalWeights.Parameters.Version)
 	fmt.Printf("f1_score = %0.01f%%\n", f1Score)
 	fmt.Printf("recall = %0.01f%%\n", recall)
+	reportValidation(n, f1Score, recall, samplesCount, context)
 }
@@ -4,6 +4,10 @@ import "gonum.org/v1/gonum/mat"
 
 func (n *MLP) Forward(x mat.Matrix) (as, zs []mat.Matrix) {
 
+	// Inputs are standardized with the statistics stored in the model
+	if n.scaler != nil {
+		x = n.scaler.Transform(x)
+	}
 	as = append(as, x)
 	_x := x
 
@@ -1,6 +1,9 @@
 package training
 
 import (
+	"agentske/compression"
+	messages "agentske/proto"
+	"fmt"
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
 )
@@ -11,12 +14,25 @@ type Config struct {
 	Eta       float64
 }
 
+// DefaultEpochs is the number of epochs every hospital trains the global model for.
+const DefaultEpochs = 25
+
 type MLP struct {
 	numLayers int
 	sizes     []int
 	Biases    []*mat.Dense
 	Weights   []*mat.Dense
 	config    Config
+	// Residuals of the compressed gradient updates, fed back into the next update
+	feedback *compression.ErrorFeedback
+	// Version of the global model the weights were last loaded from
+	globalVersion uint64
+	fromGlobal    bool
+	// Description of the input features, recorded in the model metadata
+	features string
+	// Whether the features are standardized, and the statistics they are standardized with
+	standardized bool
+	scaler       *FeatureScaler
 }
 
 func (n *MLP) GetWeights() []*mat.Dense {
@@ -27,6 +43,48 @@ func (n *MLP) GetBiases() []*mat.Dense {
 	return n.Biases
 }
 
+// GlobalVersion returns the version of the global model the weights come from,
+// and false while they are still the random initial weights.
+func (n *MLP) GlobalVersion() (uint64, bool) {
+	return n.globalVersion, n.fromGlobal
+}
+
+// MetadataFeatures is the model metadata key of the input feature description.
+const MetadataFeatures = "features"
+
+// SetFeatures records which input features the network is trained on, and whether they end with
+// the zscore normalization. Global weights and gradients for different features are rejected.
+func (n *MLP) SetFeatures(features string, standardized bool) {
+	n.features = features
+	n.standardized = standardized
+}
+
+// Standardized reports whether the features end with the zscore normalization, which the network
+// applies with the scaler stored in the model.
+func (n *MLP) Standardized() bool {
+	return n.standardized
+}
+
+// SetScaler sets the statistics the network standardizes its inputs with, nil for none.
+func (n *MLP) SetScaler(scaler *FeatureScaler) {
+	n.scaler = scaler
+}
+
+// HasScaler reports whether the network standardizes its inputs.
+func (n *MLP) HasScaler() bool {
+	return n.scaler != nil
+}
+
+// checkFeatures compares the feature description in the metadata with the network's.
+// Parameters without one, or a network without one, are accepted.
+func (n *MLP) checkFeatures(params *messages.ModelParameters) error {
+	features := params.Metadata[MetadataFeatures]
+	if n.features == "" || features == "" || features == n.features {
+		return nil
+	}
+	return fmt.Errorf("The model expects features %s, the network uses %s", features, n.features)
+}
+
 func New(c Config, sizes ...int) *MLP {
 
 	// generate some random weights and biases
@@ -64,5 +122,6 @@ func New(c Config, sizes ...int) *MLP {
 		Biases:    bs,
 		Weights:   ws,
 		config:    c,
+		feedback:  compression.NewErrorFeedback(),
 	}
 }
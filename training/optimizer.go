@@ -0,0 +1,107 @@
+package training
+
+import (
+	messages "agentske/proto"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Optimizer is SGD with (optional) momentum, applied by the aggregator to the global model.
+// With zero momentum a step is w = w - eta/batch * gradient.
+type Optimizer struct {
+	Eta      float64
+	Momentum float64
+	// Number of steps taken so far
+	Steps uint64
+	// Velocity of the weights and biases of every layer, created on the first step
+	wvelocity []*mat.Dense
+	bvelocity []*mat.Dense
+}
+
+func NewOptimizer(eta, momentum float64) *Optimizer {
+	return &Optimizer{Eta: eta, Momentum: momentum}
+}
+
+// Step updates the weights and biases of the network with the summed gradients of a batch.
+func (o *Optimizer) Step(n *MLP, nws, nbs []*mat.Dense, batchSize int) {
+	if o.wvelocity == nil {
+		o.wvelocity = make([]*mat.Dense, len(n.Weights))
+		o.bvelocity = make([]*mat.Dense, len(n.Biases))
+		for i := range n.Weights {
+			wrows, wcols := n.Weights[i].Dims()
+			brows, bcols := n.Biases[i].Dims()
+			o.wvelocity[i] = mat.NewDense(wrows, wcols, nil)
+			o.bvelocity[i] = mat.NewDense(brows, bcols, nil)
+		}
+	}
+	alpha := o.Eta / float64(batchSize)
+
+	for i := range n.Weights {
+		// v = momentum*v + alpha*gradient
+		o.wvelocity[i].Scale(o.Momentum, o.wvelocity[i])
+		o.wvelocity[i].Apply(func(r, c int, v float64) float64 { return v + alpha*nws[i].At(r, c) }, o.wvelocity[i])
+		o.bvelocity[i].Scale(o.Momentum, o.bvelocity[i])
+		o.bvelocity[i].Apply(func(r, c int, v float64) float64 { return v + alpha*nbs[i].At(r, c) }, o.bvelocity[i])
+
+		wprime := new(mat.Dense)
+		wprime.Sub(n.Weights[i], o.wvelocity[i])
+
+		bprime := new(mat.Dense)
+		bprime.Sub(n.Biases[i], o.bvelocity[i])
+
+		n.Weights[i] = wprime
+		n.Biases[i] = bprime
+	}
+	o.Steps++
+}
+
+// ResetVelocity forgets the accumulated momentum, e.g. after the model was rolled back.
+func (o *Optimizer) ResetVelocity() {
+	o.wvelocity, o.bvelocity = nil, nil
+}
+
+// State returns the hyperparameters, step counter and velocities for checkpointing.
+func (o *Optimizer) State() *messages.OptimizerState {
+	state := &messages.OptimizerState{
+		Eta:      o.Eta,
+		Momentum: o.Momentum,
+		Steps:    o.Steps,
+	}
+	for i := range o.wvelocity {
+		state.Velocity = append(state.Velocity,
+			TensorFromMatrix(WeightsTensorName(i), o.wvelocity[i]),
+			TensorFromMatrix(BiasesTensorName(i), o.bvelocity[i]))
+	}
+	return state
+}
+
+// Restore resumes from a checkpointed state, whose velocities must fit the network.
+func (o *Optimizer) Restore(state *messages.OptimizerState, n *MLP) error {
+	o.Eta = state.Eta
+	o.Momentum = state.Momentum
+	o.Steps = state.Steps
+	o.wvelocity, o.bvelocity = nil, nil
+	if len(state.Velocity) == 0 {
+		return nil
+	}
+	if len(state.Velocity) != 2*len(n.Weights) {
+		return fmt.Errorf("Optimizer state has %d velocity tensors, the network has %d layers", len(state.Velocity), len(n.Weights))
+	}
+
+	params := &messages.ModelParameters{Tensors: state.Velocity}
+	wvelocity := make([]*mat.Dense, len(n.Weights))
+	bvelocity := make([]*mat.Dense, len(n.Biases))
+	for i := range n.Weights {
+		wrows, wcols := n.Weights[i].Dims()
+		brows, bcols := n.Biases[i].Dims()
+		var err error
+		if wvelocity[i], err = matrixWithShape(params, WeightsTensorName(i), wrows, wcols); err != nil {
+			return err
+		}
+		if bvelocity[i], err = matrixWithShape(params, BiasesTensorName(i), brows, bcols); err != nil {
+			return err
+		}
+	}
+	o.wvelocity, o.bvelocity = wvelocity, bvelocity
+	return nil
+}
@@ -0,0 +1,67 @@
+package training
+
+import "gonum.org/v1/gonum/mat"
+
+// Samples are the features and the labels of a set of images, read chunk by chunk, so the set does
+// not have to fit in memory. Every call of Chunks passes over the whole set again, in the same order.
+type Samples interface {
+	// Chunks calls visit with the features and the labels of every chunk, and stops at its first error.
+	Chunks(visit func(x, y *mat.Dense) error) error
+}
+
+// TrainingSet holds the features and the labels of a set of images in memory, as a single chunk.
+type TrainingSet struct {
+	X, Y *mat.Dense
+}
+
+func (s TrainingSet) Chunks(visit func(x, y *mat.Dense) error) error {
+	return visit(s.X, s.Y)
+}
+
+// batches calls visit with the consecutive mini-batches of the samples. All but the last have size
+// rows, batches which span two chunks are copied together.
+func batches(samples Samples, size int, visit func(x, y mat.Matrix) error) error {
+	var pendingX, pendingY []float64
+	var pending, cx, cy int
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		x, y := mat.NewDense(pending, cx, pendingX), mat.NewDense(pending, cy, pendingY)
+		pendingX, pendingY, pending = nil, nil, 0
+		return visit(x, y)
+	}
+
+	err := samples.Chunks(func(x, y *mat.Dense) error {
+		var r int
+		r, cx = x.Dims()
+		_, cy = y.Dims()
+		i := 0
+		// Complete the batch left over from the previous chunk
+		for ; pending > 0 && pending < size && i < r; i++ {
+			pendingX = append(pendingX, x.RawRowView(i)...)
+			pendingY = append(pendingY, y.RawRowView(i)...)
+			pending++
+		}
+		if pending == size {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		for ; i+size <= r; i += size {
+			if err := visit(x.Slice(i, i+size, 0, cx), y.Slice(i, i+size, 0, cy)); err != nil {
+				return err
+			}
+		}
+		for ; i < r; i++ {
+			pendingX = append(pendingX, x.RawRowView(i)...)
+			pendingY = append(pendingY, y.RawRowView(i)...)
+			pending++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
@@ -0,0 +1,272 @@
+package training
+
+import (
+	"agentske/compression"
+	messages "agentske/proto"
+	"errors"
+	"fmt"
+	"github.com/asynkron/protoactor-go/actor"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"time"
+)
+
+// Names of the scaler tensors in the model parameters. They are exact, compression would make
+// hospitals standardize with slightly different statistics.
+var (
+	scalerMeanTensor = compression.ExactPrefix + "inputs.mean"
+	scalerStdTensor  = compression.ExactPrefix + "inputs.std"
+)
+
+// minFeatureStd keeps features which hardly vary, e.g. an always empty bin, from being blown up.
+const minFeatureStd = 1e-8
+
+// FeatureScaler standardizes every input feature with the mean and standard deviation of the
+// training images. It is stored with the model, and the network applies it to every input.
+type FeatureScaler struct {
+	Mean []float64
+	Std  []float64
+}
+
+// FitFeatureScaler computes the statistics of every column of x.
+func FitFeatureScaler(x mat.Matrix) *FeatureScaler {
+	mean, _ := FeatureMean(ComputeFeatureSums(x))
+	scaler, _ := FeatureScalerFromDeviations(mean, ComputeFeatureDeviations(x, mean))
+	return scaler
+}
+
+// ComputeFeatureSums returns the number of rows of x and the sum of every column, the first pass
+// of the statistics. They add up over hospitals.
+func ComputeFeatureSums(x mat.Matrix) *messages.FeatureStatistics {
+	rows, cols := x.Dims()
+	statistics := &messages.FeatureStatistics{
+		Count:      uint64(rows),
+		Sum:        make([]float64, cols),
+		SumSquares: make([]float64, cols),
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			statistics.Sum[j] += x.At(i, j)
+		}
+	}
+	return statistics
+}
+
+// ComputeFeatureDeviations returns the number of rows of x and the sum of the squared deviations
+// of every column from the pooled mean, the second pass of the statistics. Unlike the sum of
+// squares, it does not cancel out against the mean, so features with a large mean and a small
+// variance such as raw histogram counts keep their precision.
+func ComputeFeatureDeviations(x mat.Matrix, mean []float64) *messages.FeatureStatistics {
+	rows, cols := x.Dims()
+	statistics := &messages.FeatureStatistics{
+		Count:      uint64(rows),
+		Sum:        make([]float64, cols),
+		SumSquares: make([]float64, cols),
+		Deviations: true,
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			d := x.At(i, j) - mean[j]
+			statistics.SumSquares[j] += d * d
+		}
+	}
+	return statistics
+}
+
+// FeatureMean computes the mean of every feature from the pooled sums.
+func FeatureMean(statistics *messages.FeatureStatistics) ([]float64, error) {
+	if statistics.Count == 0 {
+		return nil, errors.New("The feature statistics cover no images")
+	}
+	mean := make([]float64, len(statistics.Sum))
+	for j, sum := range statistics.Sum {
+		mean[j] = sum / float64(statistics.Count)
+	}
+	return mean, nil
+}
+
+// FeatureScalerFromDeviations computes the population standard deviation of every feature from the
+// pooled squared deviations from the mean.
+func FeatureScalerFromDeviations(mean []float64, statistics *messages.FeatureStatistics) (*FeatureScaler, error) {
+	if statistics.Count == 0 {
+		return nil, errors.New("The feature statistics cover no images")
+	}
+	if len(statistics.SumSquares) != len(mean) {
+		return nil, fmt.Errorf("The feature statistics have %d squared deviations for %d features", len(statistics.SumSquares), len(mean))
+	}
+	s := &FeatureScaler{Mean: append([]float64{}, mean...), Std: make([]float64, len(mean))}
+	for j, squares := range statistics.SumSquares {
+		s.Std[j] = math.Sqrt(squares / float64(statistics.Count))
+		if !(s.Std[j] >= minFeatureStd) {
+			s.Std[j] = 1
+		}
+	}
+	return s, nil
+}
+
+// FlattenFeatureStatistics lays out the sums and the sums of squared deviations, followed by the
+// count, so secure aggregation masks all of them.
+func FlattenFeatureStatistics(statistics *messages.FeatureStatistics) []float64 {
+	vector := append(append([]float64{}, statistics.Sum...), statistics.SumSquares...)
+	return append(vector, float64(statistics.Count))
+}
+
+// UnflattenFeatureStatistics is the inverse of FlattenFeatureStatistics for the given number of features.
+func UnflattenFeatureStatistics(vector []float64, inputs int) (*messages.FeatureStatistics, error) {
+	if len(vector) != 2*inputs+1 {
+		return nil, fmt.Errorf("Feature statistics vector has %d values, expected %d", len(vector), 2*inputs+1)
+	}
+	return &messages.FeatureStatistics{
+		Sum:        vector[:inputs],
+		SumSquares: vector[inputs : 2*inputs],
+		Count:      uint64(math.Round(vector[2*inputs])),
+	}, nil
+}
+
+// Transform returns the standardized copy of x.
+func (s *FeatureScaler) Transform(x mat.Matrix) *mat.Dense {
+	scaled := mat.DenseCopyOf(x)
+	scaled.Apply(func(_, j int, v float64) float64 { return (v - s.Mean[j]) / s.Std[j] }, scaled)
+	return scaled
+}
+
+func (s *FeatureScaler) tensors() []*messages.Tensor {
+	return []*messages.Tensor{
+		TensorFromMatrix(scalerMeanTensor, mat.NewDense(1, len(s.Mean), s.Mean)),
+		TensorFromMatrix(scalerStdTensor, mat.NewDense(1, len(s.Std), s.Std)),
+	}
+}
+
+// featureScalerFromParameters reads the scaler of a network with the given number of inputs. It
+// returns nil when the parameters have none.
+func featureScalerFromParameters(params *messages.ModelParameters, inputs int) (*FeatureScaler, error) {
+	if _, err := FindTensor(params, scalerMeanTensor); err != nil {
+		return nil, nil
+	}
+	mean, err := matrixWithShape(params, scalerMeanTensor, 1, inputs)
+	if err != nil {
+		return nil, err
+	}
+	std, err := matrixWithShape(params, scalerStdTensor, 1, inputs)
+	if err != nil {
+		return nil, err
+	}
+	s := &FeatureScaler{Mean: mat.Row(nil, 0, mean), Std: mat.Row(nil, 0, std)}
+	for _, v := range s.Std {
+		if !(v > 0) {
+			return nil, errors.New("The feature scaler has a standard deviation which is not positive")
+		}
+	}
+	return s, nil
+}
+
+// ensureFeatureScaler makes sure the global model has a feature scaler before training starts. When
+// it has none, the hospital reports the sums of its training features and then their squared
+// deviations from the pooled mean, masked when the aggregator runs secure aggregation. The aggregator
+// answers each pass once it pooled the statistics of the hospitals.
+func ensureFeatureScaler(samples Samples, context actor.Context) error {
+	aggregationActor, err := context.RequestFuture(context.Parent(), &messages.GetAggregationActor{}, 5*time.Second).Result()
+	if err != nil {
+		return err
+	}
+	aggregator := aggregationActor.(*actor.PID)
+	globalWeights, err := requestGlobalWeights(aggregator, "", context)
+	if err != nil {
+		return err
+	}
+	if globalWeights.Parameters == nil {
+		return errors.New("The aggregator sent no model")
+	}
+	if hasFeatureScaler(globalWeights) {
+		return nil
+	}
+
+	sums, err := featureStatistics(samples, ComputeFeatureSums)
+	if err != nil {
+		return err
+	}
+	mean, err := reportFeatureStatistics(sums, globalWeights, aggregator, context)
+	if err != nil {
+		return err
+	}
+	if len(mean) == 0 {
+		// Other hospitals already set the scaler
+		return nil
+	}
+	if len(mean) != len(sums.Sum) {
+		return fmt.Errorf("The pooled feature mean has %d features, expected %d", len(mean), len(sums.Sum))
+	}
+	deviations, err := featureStatistics(samples, func(x mat.Matrix) *messages.FeatureStatistics {
+		return ComputeFeatureDeviations(x, mean)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = reportFeatureStatistics(deviations, globalWeights, aggregator, context)
+	return err
+}
+
+// featureStatistics adds up the statistics of every chunk of the samples.
+func featureStatistics(samples Samples, compute func(x mat.Matrix) *messages.FeatureStatistics) (*messages.FeatureStatistics, error) {
+	var total *messages.FeatureStatistics
+	err := samples.Chunks(func(x, _ *mat.Dense) error {
+		statistics := compute(x)
+		if total == nil {
+			total = statistics
+			return nil
+		}
+		total.Count += statistics.Count
+		floats.Add(total.Sum, statistics.Sum)
+		floats.Add(total.SumSquares, statistics.SumSquares)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total == nil {
+		return nil, errors.New("There are no images to compute the feature statistics of")
+	}
+	return total, nil
+}
+
+func hasFeatureScaler(globalWeights *messages.GlobalWeights) bool {
+	if globalWeights.Parameters == nil {
+		return false
+	}
+	_, err := FindTensor(globalWeights.Parameters, scalerMeanTensor)
+	return err == nil
+}
+
+// reportFeatureStatistics sends one pass of the statistics and returns the pooled mean the aggregator
+// answers the sums with.
+func reportFeatureStatistics(statistics *messages.FeatureStatistics, globalWeights *messages.GlobalWeights, aggregator *actor.PID, context actor.Context) ([]float64, error) {
+	statistics.ModelId = globalWeights.Parameters.ModelId
+	statistics.Hospital = context.Self().Address
+	if globalWeights.SecureAggregation {
+		purpose := messages.SecAggPurpose_SECAGG_PURPOSE_FEATURE_SUMS
+		if statistics.Deviations {
+			purpose = messages.SecAggPurpose_SECAGG_PURPOSE_FEATURE_DEVIATIONS
+		}
+		result, err := secureSum(FlattenFeatureStatistics(statistics), purpose, aggregator, context)
+		if err != nil {
+			return nil, err
+		}
+		return result.Mean, nil
+	}
+
+	// The aggregator answers once the other hospitals reported as well, or its timeout passed
+	timeout := time.Duration(globalWeights.StatisticsTimeoutMs)*time.Millisecond + 20*time.Second
+	result, err := context.RequestFuture(aggregator, statistics, timeout).Result()
+	if err != nil {
+		return nil, err
+	}
+	response, ok := result.(*messages.FeatureStatisticsReceived)
+	if !ok {
+		return nil, errors.New("Unexpected response to the feature statistics")
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("The aggregator could not pool the feature statistics: %s", response.Error)
+	}
+	return response.Mean, nil
+}
@@ -0,0 +1,132 @@
+package training
+
+import (
+	messages "agentske/proto"
+	"agentske/secagg"
+	"errors"
+	"fmt"
+	"github.com/asynkron/protoactor-go/actor"
+	"math"
+	"time"
+)
+
+// Every step of the protocol waits for the other hospitals, so this is longer than the aggregator's phase timeout
+const secureAggregationTimeout = 60 * time.Second
+
+// FlattenGradientUpdate lays out the data of all gradient tensors in order, followed by
+// the batch size in a single vector, so the batch size is masked as well.
+func FlattenGradientUpdate(msg *messages.GradientUpdate) []float64 {
+	var vector []float64
+	for _, tensor := range msg.Gradients.Tensors {
+		vector = append(vector, tensor.Data...)
+	}
+	return append(vector, float64(msg.BatchSize))
+}
+
+// UnflattenGradientUpdate is the inverse of FlattenGradientUpdate, using the layer shapes of the network.
+func UnflattenGradientUpdate(n *MLP, vector []float64) (*messages.GradientUpdate, error) {
+	msg := &messages.GradientUpdate{Gradients: &messages.ModelParameters{}}
+	offset := 0
+	next := func(name string, rows, cols int) error {
+		if offset+rows*cols > len(vector)-1 {
+			return errors.New("Gradient vector is too short for the network")
+		}
+		msg.Gradients.Tensors = append(msg.Gradients.Tensors, &messages.Tensor{
+			Name:  name,
+			Shape: []int64{int64(rows), int64(cols)},
+			Dtype: messages.DType_DTYPE_FLOAT64,
+			Data:  vector[offset : offset+rows*cols],
+		})
+		offset += rows * cols
+		return nil
+	}
+	for i := range n.Weights {
+		wrows, wcols := n.Weights[i].Dims()
+		brows, bcols := n.Biases[i].Dims()
+		if err := next(WeightsTensorName(i), wrows, wcols); err != nil {
+			return nil, err
+		}
+		if err := next(BiasesTensorName(i), brows, bcols); err != nil {
+			return nil, err
+		}
+	}
+	if offset != len(vector)-1 {
+		return nil, errors.New("Gradient vector is too long for the network")
+	}
+	msg.BatchSize = int32(math.Round(vector[offset]))
+	return msg, nil
+}
+
+// SendSecureUpdate takes part in one secure aggregation round, so the aggregator only learns
+// the sum of this update with the updates of the other hospitals in the round.
+func SendSecureUpdate(update *messages.GradientUpdate, aggregationActor *actor.PID, context actor.Context) error {
+	_, err := secureSum(FlattenGradientUpdate(update), messages.SecAggPurpose_SECAGG_PURPOSE_GRADIENTS, aggregationActor, context)
+	return err
+}
+
+// secureSum takes part in one secure aggregation round of the purpose with the vector. Only
+// hospitals summing vectors of the same purpose share a round.
+func secureSum(vector []float64, purpose messages.SecAggPurpose, aggregationActor *actor.PID, context actor.Context) (*messages.SecAggRoundResult, error) {
+	client, err := secagg.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	advertise := client.AdvertiseKeys()
+	advertise.Purpose = purpose
+	keysResult, err := context.RequestFuture(aggregationActor, advertise, secureAggregationTimeout).Result()
+	if err != nil {
+		return nil, err
+	}
+	keys, ok := keysResult.(*messages.SecAggRoundKeys)
+	if !ok {
+		return nil, errors.New("Unexpected response to the advertised keys")
+	}
+	shareKeys, err := client.ShareKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	sharesResult, err := context.RequestFuture(aggregationActor, shareKeys, secureAggregationTimeout).Result()
+	if err != nil {
+		return nil, err
+	}
+	shares, ok := sharesResult.(*messages.SecAggRoundShares)
+	if !ok {
+		return nil, errors.New("Unexpected response to the shared keys")
+	}
+	if err := client.ReceiveShares(shares); err != nil {
+		return nil, err
+	}
+
+	masked, err := client.MaskInput(vector)
+	if err != nil {
+		return nil, err
+	}
+	unmaskResult, err := context.RequestFuture(aggregationActor, masked, secureAggregationTimeout).Result()
+	if err != nil {
+		return nil, err
+	}
+	unmaskRequest, ok := unmaskResult.(*messages.SecAggUnmaskRequest)
+	if !ok {
+		return nil, errors.New("Unexpected response to the masked update")
+	}
+	unmaskShares, err := client.Unmask(unmaskRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// The round is only over once the aggregator applied the sum
+	finished, err := context.RequestFuture(aggregationActor, unmaskShares, secureAggregationTimeout).Result()
+	if err != nil {
+		return nil, err
+	}
+	result, ok := finished.(*messages.SecAggRoundResult)
+	if !ok {
+		return nil, errors.New("Unexpected response to the unmasking shares")
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("Secure aggregation round %d failed: %s", result.Round, result.Error)
+	}
+	return result, nil
+}
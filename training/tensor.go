@@ -0,0 +1,67 @@
+package training
+
+import (
+	messages "agentske/proto"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+func WeightsTensorName(layer int) string {
+	return fmt.Sprintf("layers.%d.weights", layer)
+}
+
+func BiasesTensorName(layer int) string {
+	return fmt.Sprintf("layers.%d.biases", layer)
+}
+
+// TensorFromMatrix copies the matrix into a 2-D float64 tensor.
+func TensorFromMatrix(name string, m mat.Matrix) *messages.Tensor {
+	rows, cols := m.Dims()
+	return &messages.Tensor{
+		Name:  name,
+		Shape: []int64{int64(rows), int64(cols)},
+		Dtype: messages.DType_DTYPE_FLOAT64,
+		Data:  mat.DenseCopyOf(m).RawMatrix().Data,
+	}
+}
+
+// MatrixFromTensor builds a matrix from a 2-D tensor, checking that the data matches the shape.
+func MatrixFromTensor(tensor *messages.Tensor) (*mat.Dense, error) {
+	if tensor.Compressed != nil {
+		return nil, fmt.Errorf("Tensor %s is still compressed", tensor.Name)
+	}
+	if len(tensor.Shape) != 2 || tensor.Shape[0] <= 0 || tensor.Shape[1] <= 0 {
+		return nil, fmt.Errorf("Tensor %s has shape %v, expected a matrix", tensor.Name, tensor.Shape)
+	}
+	rows, cols := int(tensor.Shape[0]), int(tensor.Shape[1])
+	if len(tensor.Data) != rows*cols {
+		return nil, fmt.Errorf("Tensor %s has %d values, its shape %v needs %d", tensor.Name, len(tensor.Data), tensor.Shape, rows*cols)
+	}
+	return mat.NewDense(rows, cols, tensor.Data), nil
+}
+
+// FindTensor returns the tensor with the given name.
+func FindTensor(params *messages.ModelParameters, name string) (*messages.Tensor, error) {
+	for _, tensor := range params.Tensors {
+		if tensor.Name == name {
+			return tensor, nil
+		}
+	}
+	return nil, fmt.Errorf("Tensor %s is missing", name)
+}
+
+// matrixWithShape reads the named tensor as a matrix and checks it has the expected shape.
+func matrixWithShape(params *messages.ModelParameters, name string, rows, cols int) (*mat.Dense, error) {
+	tensor, err := FindTensor(params, name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := MatrixFromTensor(tensor)
+	if err != nil {
+		return nil, err
+	}
+	if r, c := m.Dims(); r != rows || c != cols {
+		return nil, fmt.Errorf("Tensor %s has shape %dx%d, expected %dx%d", name, r, c, rows, cols)
+	}
+	return m, nil
+}
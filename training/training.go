@@ -1,45 +1,122 @@
 package training
 
 import (
+	messages "agentske/proto"
+	"errors"
 	"fmt"
 	"github.com/asynkron/protoactor-go/actor"
 	"gonum.org/v1/gonum/mat"
+	"strconv"
+	"time"
 )
 
-func (n *MLP) Train(x, y *mat.Dense, context actor.Context) {
-
-	r, cx := x.Dims()
-	_, cy := y.Dims()
+func (n *MLP) Train(x, y *mat.Dense, context actor.Context) error {
+	return n.TrainSets([]Samples{TrainingSet{X: x, Y: y}}, context)
+}
 
-	b := n.config.BatchSize
+// TrainSets trains epoch e on the set e modulo the number of sets, so every epoch sees other augmentations.
+// Several sets hold differently augmented versions of the training images, and are read again every
+// epoch. With secure aggregation every epoch is one round. Training stops at the first update the
+// aggregator could not take, and its error is returned.
+func (n *MLP) TrainSets(sets []Samples, context actor.Context) error {
 
 	for e := 1; e < n.config.Epochs+1; e++ {
+		set := sets[(e-1)%len(sets)]
 
-		for i := 0; i < r; i += b {
-			k := i + b
-			if k > r {
-				k = r
+		globalWeights, aggregationActor, err := n.loadGlobalWeights(context)
+		if err != nil {
+			return fmt.Errorf("Epoch %d: %w", e, err)
+		}
+		if globalWeights.SecureAggregation {
+			if err := n.secureEpoch(set, globalWeights, aggregationActor, context); err != nil {
+				return fmt.Errorf("The secure aggregation round of epoch %d failed: %w", e, err)
 			}
-			_x := x.Slice(i, k, 0, cx)
-			_y := y.Slice(i, k, 0, cy)
+			continue
+		}
 
-			n.Backward(_x, _y, context)
+		err = batches(set, n.config.BatchSize, func(_x, _y mat.Matrix) error {
+			return n.Backward(_x, _y, context)
+		})
+		if err != nil {
+			return fmt.Errorf("Epoch %d: %w", e, err)
 		}
 	}
+	return nil
 }
 
-func StartTraining(X, Y, Xv, Yv *mat.Dense, context actor.Context) {
+// StartTraining trains on the features and reports the validation metrics. features is the
+// description of their pipeline, which global weights and updates are checked against, dimension
+// their number per image, and standardized whether the network applies a fitted feature scaler to
+// them. Epoch e trains on the set e modulo the number of sets. The test set, nil without a test
+// split, is only evaluated locally once training is done.
+func StartTraining(sets []Samples, validation, test Samples, features string, dimension int, standardized bool, context actor.Context) {
 	con := Config{
-		Epochs:    25,
+		Epochs:    DefaultEpochs,
 		Eta:       0.3,
 		BatchSize: 32,
 	}
-	_, cols := X.Dims()
-	arch := []int{cols, 15, 8, 1}
+	arch := []int{dimension, 15, 8, 1}
 	n := New(con, arch...)
+	n.SetFeatures(features, standardized)
+	// Standardized features need the pooled statistics in the global model before the first batch
+	if n.Standardized() {
+		if err := ensureFeatureScaler(sets[0], context); err != nil {
+			fmt.Println("Could not set up the feature scaler:", err)
+			return
+		}
+	}
 	//n.WriteWeightsToFile("./../weights.json")
-	n.Train(X, Y, context)
-	f1Score, recall := n.Evaluate(Xv, Yv)
+	if err := n.TrainSets(sets, context); err != nil {
+		fmt.Println("Training stopped:", err)
+		return
+	}
+	// The aggregator only registers some versions, and metrics are recorded for registered ones
+	if err := n.loadRegisteredModel(context); err != nil {
+		fmt.Println("Validating the last local weights, the registered global model could not be loaded:", err)
+	}
+	f1Score, recall, samples, err := n.EvaluateSamples(validation)
+	if err != nil {
+		fmt.Println("Could not read the validation features:", err)
+		return
+	}
+	if version, ok := n.GlobalVersion(); ok {
+		fmt.Printf("model_version = %d\n", version)
+	}
 	fmt.Printf("f1_score = %0.01f%%\n", f1Score)
 	fmt.Printf("recall = %0.01f%%\n", recall)
+	reportValidation(n, f1Score, recall, samples, context)
+	if test != nil {
+		testF1Score, testRecall, _, err := n.EvaluateSamples(test)
+		if err != nil {
+			fmt.Println("Could not read the test features:", err)
+			return
+		}
+		fmt.Printf("test_f1_score = %0.01f%%\n", testF1Score)
+		fmt.Printf("test_recall = %0.01f%%\n", testRecall)
+	}
+}
+
+// loadRegisteredModel loads the current global model, which the aggregator registers when asked for
+// its version number.
+func (n *MLP) loadRegisteredModel(context actor.Context) error {
+	aggregationActor, err := context.RequestFuture(context.Parent(), &messages.GetAggregationActor{}, 5*time.Second).Result()
+	if err != nil {
+		return err
+	}
+	resolved, err := context.RequestFuture(aggregationActor.(*actor.PID), &messages.ResolveModelVersion{}, 5*time.Second).Result()
+	if err != nil {
+		return err
+	}
+	version, ok := resolved.(*messages.ResolvedModelVersion)
+	if !ok {
+		return errors.New("Unexpected response to the model version")
+	}
+	if version.Error != "" {
+		return errors.New(version.Error)
+	}
+	globalWeights, err := requestGlobalWeights(aggregationActor.(*actor.PID), strconv.FormatUint(version.Version, 10), context)
+	if err != nil {
+		return err
+	}
+	return n.ConvertFromGlobalWeights(globalWeights)
 }
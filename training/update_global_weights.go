@@ -2,30 +2,40 @@ package training
 
 import (
 	messages "agentske/proto"
+	"errors"
 	"gonum.org/v1/gonum/mat"
 )
 
-func UpdateGlobalWeights(n *MLP, msg *messages.GradientUpdate) {
-	for i, weightLayer := range msg.Weights {
+// UpdateGlobalWeights checks the gradient update against the network and lets the optimizer apply it.
+func UpdateGlobalWeights(n *MLP, optimizer *Optimizer, msg *messages.GradientUpdate) error {
+	if msg.Gradients == nil {
+		return errors.New("Gradient update has no gradients")
+	}
+	if len(msg.Gradients.Tensors) != 2*len(n.Weights) {
+		return errors.New("Gradient update does not have a weights and biases tensor for every layer")
+	}
+	if msg.BatchSize <= 0 {
+		return errors.New("Gradient update has an invalid batch size")
+	}
+	if err := n.checkFeatures(msg.Gradients); err != nil {
+		return err
+	}
+
+	// Check every tensor before touching the weights, so a bad update is never half applied
+	nws := make([]*mat.Dense, len(n.Weights))
+	nbs := make([]*mat.Dense, len(n.Biases))
+	for i := range n.Weights {
 		wrows, wcols := n.Weights[i].Dims()
 		brows, bcols := n.Biases[i].Dims()
-		nw := mat.NewDense(wrows, wcols, weightLayer.Weights)
-		nb := mat.NewDense(bcols, brows, weightLayer.Biases).T()
-		alpha := 0.3 / float64(msg.BatchSize)
-
-		scalednw := new(mat.Dense)
-		scalednw.Scale(alpha, nw)
-
-		scalednb := new(mat.Dense)
-		scalednb.Scale(alpha, nb)
-
-		wprime := new(mat.Dense)
-		wprime.Sub(n.Weights[i], scalednw)
-
-		bprime := new(mat.Dense)
-		bprime.Sub(n.Biases[i], scalednb)
-
-		n.Weights[i] = wprime
-		n.Biases[i] = bprime
+		var err error
+		if nws[i], err = matrixWithShape(msg.Gradients, WeightsTensorName(i), wrows, wcols); err != nil {
+			return err
+		}
+		if nbs[i], err = matrixWithShape(msg.Gradients, BiasesTensorName(i), brows, bcols); err != nil {
+			return err
+		}
 	}
+
+	optimizer.Step(n, nws, nbs, int(msg.BatchSize))
+	return nil
 }
@@ -1,34 +1,55 @@
 package training
 
 import (
+	"agentske/compression"
 	messages "agentske/proto"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/asynkron/protoactor-go/actor"
 	"gonum.org/v1/gonum/mat"
 	"io/ioutil"
+	"time"
 )
 
 func ConvertToGlobalWeights(n *MLP) *messages.GlobalWeights {
-	globalWeights := &messages.GlobalWeights{}
-
-	// Convert biases
-	for _, bias := range n.GetBiases() {
-		biasData := &messages.Biases{
-			Data: bias.RawMatrix().Data,
-		}
-		globalWeights.Biases = append(globalWeights.Biases, biasData)
+	globalWeights := &messages.GlobalWeights{Parameters: &messages.ModelParameters{}}
+	if n.features != "" {
+		globalWeights.Parameters.Metadata = map[string]string{MetadataFeatures: n.features}
 	}
 
-	// Convert weights
-	for _, weight := range n.GetWeights() {
-		weightData := &messages.Weights{
-			Data: weight.RawMatrix().Data,
-		}
-		globalWeights.Weights = append(globalWeights.Weights, weightData)
+	// Weights (x*y) and biases (y*1) of every layer
+	for i := range n.GetWeights() {
+		globalWeights.Parameters.Tensors = append(globalWeights.Parameters.Tensors,
+			TensorFromMatrix(WeightsTensorName(i), n.Weights[i]),
+			TensorFromMatrix(BiasesTensorName(i), n.Biases[i]))
+	}
+	if n.scaler != nil {
+		globalWeights.Parameters.Tensors = append(globalWeights.Parameters.Tensors, n.scaler.tensors()...)
 	}
 
 	return globalWeights
 }
 
+// requestGlobalWeights fetches the global weights from the aggregator, letting it compress them
+// with any encoding we can decode, and restores the dense values. An empty modelVersion asks
+// for the current global model, otherwise for that registry version or tag.
+func requestGlobalWeights(aggregationActor *actor.PID, modelVersion string, context actor.Context) (*messages.GlobalWeights, error) {
+	request := &messages.GetGlobalWeights{AcceptedEncodings: compression.Supported(), ModelVersion: modelVersion}
+	result, err := context.RequestFuture(aggregationActor, request, 20*time.Second).Result()
+	if err != nil {
+		return nil, err
+	}
+	globalWeights, ok := result.(*messages.GlobalWeights)
+	if !ok {
+		return nil, errors.New("Unexpected response to the global weights request")
+	}
+	if err := compression.DecodeGlobalWeights(globalWeights); err != nil {
+		return nil, err
+	}
+	return globalWeights, nil
+}
+
 func (n *MLP) WriteWeightsToFile(filename string) error {
 	// Create a struct to hold the weights
 	weightsData := struct {
@@ -79,6 +100,17 @@ func (n *MLP) ReadWeightsFromFile(filename string) error {
 		return err
 	}
 
+	// The file must have the network's layer sizes
+	layers := len(n.sizes) - 1
+	if len(weightsData.Biases) != layers || len(weightsData.Weights) != layers {
+		return fmt.Errorf("%s has %d layers, the network has %d", filename, len(weightsData.Biases), layers)
+	}
+	for i := 0; i < layers; i++ {
+		if len(weightsData.Biases[i]) != n.sizes[i+1] || len(weightsData.Weights[i]) != n.sizes[i]*n.sizes[i+1] {
+			return fmt.Errorf("Layer %d in %s does not have %d inputs and %d outputs", i, filename, n.sizes[i], n.sizes[i+1])
+		}
+	}
+
 	// Convert the weights data into biases and weights matrices
 	n.Biases = make([]*mat.Dense, len(weightsData.Biases))
 	n.Weights = make([]*mat.Dense, len(weightsData.Weights))
@@ -97,25 +129,40 @@ func (n *MLP) ReadWeightsFromFile(filename string) error {
 	return nil
 }
 
-func (n *MLP) ConvertFromGlobalWeights(globalWeights *messages.GlobalWeights) {
-	// Clear existing biases and weights
-	n.Biases = []*mat.Dense{}
-	n.Weights = []*mat.Dense{}
-
-	// Convert biases
-	for _, biasesData := range globalWeights.Biases {
-		rows := len(biasesData.Data)
-		cols := 1
-		biases := mat.NewDense(rows, cols, biasesData.Data)
-		n.Biases = append(n.Biases, biases)
+// ConvertFromGlobalWeights replaces the weights and biases with the global ones,
+// which must have the shapes of the network's layers.
+func (n *MLP) ConvertFromGlobalWeights(globalWeights *messages.GlobalWeights) error {
+	if globalWeights.Parameters == nil {
+		return errors.New("Global weights have no parameters")
+	}
+	if err := n.checkFeatures(globalWeights.Parameters); err != nil {
+		return err
+	}
+	layers := len(n.sizes) - 1
+	// The feature scaler adds its mean and standard deviation
+	if tensors := len(globalWeights.Parameters.Tensors); tensors != 2*layers && tensors != 2*layers+2 {
+		return fmt.Errorf("Global weights have %d tensors, the network has %d layers", tensors, layers)
+	}
+	scaler, err := featureScalerFromParameters(globalWeights.Parameters, n.sizes[0])
+	if err != nil {
+		return err
 	}
 
-	// Convert weights
-	for i, weightsData := range globalWeights.Weights {
-		rows := n.sizes[i+1]
-		cols := n.sizes[i]
-		weights := mat.NewDense(cols, rows, weightsData.Data)
-		n.Weights = append(n.Weights, weights)
+	biases := make([]*mat.Dense, layers)
+	weights := make([]*mat.Dense, layers)
+	for i := 0; i < layers; i++ {
+		if weights[i], err = matrixWithShape(globalWeights.Parameters, WeightsTensorName(i), n.sizes[i], n.sizes[i+1]); err != nil {
+			return err
+		}
+		if biases[i], err = matrixWithShape(globalWeights.Parameters, BiasesTensorName(i), n.sizes[i+1], 1); err != nil {
+			return err
+		}
 	}
 
+	n.Biases = biases
+	n.Weights = weights
+	n.scaler = scaler
+	n.globalVersion = globalWeights.Parameters.Version
+	n.fromGlobal = true
+	return nil
 }